@@ -21,6 +21,7 @@ package main
 
 import (
     "context"
+    "flag"
     "os/user"
     "time"
 
@@ -28,6 +29,7 @@ import (
     _ "github.com/lib/pq"
 
     "github.com/wso2-open-operations/common-tools/bigquery-flash-data-sync/internal/config"
+    "github.com/wso2-open-operations/common-tools/bigquery-flash-data-sync/internal/health"
     "github.com/wso2-open-operations/common-tools/bigquery-flash-data-sync/internal/logger"
     "github.com/wso2-open-operations/common-tools/bigquery-flash-data-sync/internal/model"
     "github.com/wso2-open-operations/common-tools/bigquery-flash-data-sync/internal/pipeline"
@@ -44,7 +46,15 @@ var (
 
 // main initializes logging, configuration, and starts the sync pipeline.
 func main() {
-    // Initialize logger first
+    dryRun := flag.Bool("dry-run", false, "run every read and check and log the planned writes without changing BigQuery (same as DRY_RUN=true)")
+    healthAddr := flag.String("health-addr", "", "serve /health and /metrics on this address while the sync runs (same as HEALTH_ADDR)")
+    flag.Parse()
+
+    // Load .env file (optional in production) before the logger, which reads
+    // its LOG_* settings from the environment
+    envErr := godotenv.Load()
+
+    // Initialize logger before anything else logs
     logger.InitLogger()
     defer logger.Sync()
 
@@ -64,19 +74,31 @@ func main() {
         zap.String("timestamp", time.Now().Format(time.RFC3339)),
     )
 
-    // Load .env file (optional in production)
-    if err := godotenv.Load(); err != nil {
+    if envErr != nil {
         logger.Logger.Info("No .env file found, using environment variables")
     } else {
         logger.Logger.Info(".env file loaded successfully")
     }
 
+    // Expose the runtime log level endpoint if LOG_ADMIN_ADDR is set
+    logger.StartLevelServer()
+
     // Load application configuration
     logger.Logger.Info("Loading application configuration")
     cfg, err := config.LoadConfig(logger.Logger)
     if err != nil {
         logger.Logger.Fatal("Failed to load configuration", zap.Error(err))
     }
+    if *dryRun {
+        cfg.DryRun = true
+    }
+    if *healthAddr != "" {
+        cfg.HealthAddr = *healthAddr
+    }
+
+    // Expose sync status for monitoring if HEALTH_ADDR or --health-addr is set
+    tracker := health.NewTracker()
+    health.StartServer(cfg.HealthAddr, tracker, logger.Logger)
 
     // Log configuration summary
     logConfigSummary(cfg)
@@ -91,10 +113,17 @@ func main() {
         zap.Bool("dry_run", cfg.DryRun),
     )
 
-    if err := pipeline.Start(ctx, cfg, logger.Logger); err != nil {
+    tracker.RunStarted()
+    summary, err := pipeline.Start(ctx, cfg, logger.Logger)
+    tracker.RunFinished(summary, err)
+    if err != nil {
         logger.Logger.Fatal("Data sync pipeline failed", zap.Error(err))
     }
 
+    if cfg.DryRun {
+        logger.Logger.Info("Dry run completed, no changes were made")
+        return
+    }
     logger.Logger.Info("Data sync completed successfully")
 }
 
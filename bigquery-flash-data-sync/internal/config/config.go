@@ -22,6 +22,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -46,11 +47,27 @@ const (
 	SyncTimeout     = "SYNC_TIMEOUT"
 	DateFormat      = "DATE_FORMAT"
 	DefaultBatchSize = "DEFAULT_BATCH_SIZE"
+	SyncConcurrency  = "SYNC_CONCURRENCY"
+	PagedReads       = "PAGED_READS"
+	HealthAddr       = "HEALTH_ADDR"
 
 	DryRun              = "DRY_RUN"
 	CreateTables        = "AUTO_CREATE_TABLES"
 	TruncateOnSync    = "TRUNCATE_ON_SYNC"
 	MaxRowParseFailures = "MAX_ROW_PARSE_FAILURES"
+	SchemaDrift         = "SCHEMA_DRIFT"
+
+	WatermarkStore      = "WATERMARK_STORE"
+	WatermarkStateFile  = "WATERMARK_STATE_FILE"
+	WatermarkStateTable = "WATERMARK_STATE_TABLE"
+
+	RetryMaxAttempts = "RETRY_MAX_ATTEMPTS"
+	RetryBaseDelay   = "RETRY_BASE_DELAY"
+	RetryMaxDelay    = "RETRY_MAX_DELAY"
+
+	CheckpointStore = "CHECKPOINT_STORE"
+	CheckpointFile  = "CHECKPOINT_FILE"
+	CheckpointTable = "CHECKPOINT_TABLE"
 )
 
 // LoadConfig reads all required environment variables and builds database connection strings.
@@ -90,17 +107,49 @@ func LoadConfig(logger *zap.Logger) (*model.Config, error) {
 	maxOpen := parseInt(logger, DBMaxOpenConns, "10", 10)
 	maxIdle := parseInt(logger, DBMaxIdleConns, "10", 10)
 	defaultBatchSize := parseInt(logger, DefaultBatchSize, "1000", 1000)
+	if defaultBatchSize < 1 {
+		return nil, fmt.Errorf("%s must be at least 1, got %d", DefaultBatchSize, defaultBatchSize)
+	}
 	maxRowParseFailures := parseInt(logger, MaxRowParseFailures, "100", 100)
 
 	syncTimeout := parseDuration(logger, SyncTimeout, "10m", 10*time.Minute)
+	syncConcurrency := parseInt(logger, SyncConcurrency, "4", 4)
+	if syncConcurrency < 1 {
+		return nil, fmt.Errorf("%s must be at least 1, got %d", SyncConcurrency, syncConcurrency)
+	}
 	connMaxLifetime := parseDuration(logger, DBConnMaxLifetime, "1m", 1*time.Minute)
 
+	retryMaxAttempts := parseInt(logger, RetryMaxAttempts, "3", 3)
+	if retryMaxAttempts < 1 {
+		return nil, fmt.Errorf("%s must be at least 1, got %d", RetryMaxAttempts, retryMaxAttempts)
+	}
+	retryBaseDelay := parseDuration(logger, RetryBaseDelay, "1s", 1*time.Second)
+	retryMaxDelay := parseDuration(logger, RetryMaxDelay, "30s", 30*time.Second)
+	if retryMaxDelay < retryBaseDelay {
+		return nil, fmt.Errorf("%s (%s) must not be shorter than %s (%s)", RetryMaxDelay, retryMaxDelay, RetryBaseDelay, retryBaseDelay)
+	}
+
+	checkpointStore := strings.ToLower(strings.TrimSpace(getEnv(CheckpointStore, "")))
+	if checkpointStore != "" && checkpointStore != model.CheckpointStoreFile && checkpointStore != model.CheckpointStoreBigQuery {
+		return nil, fmt.Errorf("%s must be empty, %q or %q, got %q", CheckpointStore, model.CheckpointStoreFile, model.CheckpointStoreBigQuery, checkpointStore)
+	}
+
 	dateFormat := getEnv(DateFormat, "2006-01-02T15:04:05Z07:00")
 
 	dryRun := parseBool(getEnv(DryRun, "false"))
 	createTables := parseBool(getEnv(CreateTables, "true"))
 	truncateOnSync := parseBool(getEnv(TruncateOnSync, "false"))
 
+	schemaDrift := strings.ToLower(strings.TrimSpace(getEnv(SchemaDrift, model.SchemaDriftFail)))
+	if schemaDrift != model.SchemaDriftFail && schemaDrift != model.SchemaDriftAddColumns {
+		return nil, fmt.Errorf("%s must be %q or %q, got %q", SchemaDrift, model.SchemaDriftFail, model.SchemaDriftAddColumns, schemaDrift)
+	}
+
+	watermarkStore := strings.ToLower(strings.TrimSpace(getEnv(WatermarkStore, model.WatermarkStoreFile)))
+	if watermarkStore != model.WatermarkStoreFile && watermarkStore != model.WatermarkStoreBigQuery {
+		return nil, fmt.Errorf("%s must be %q or %q, got %q", WatermarkStore, model.WatermarkStoreFile, model.WatermarkStoreBigQuery, watermarkStore)
+	}
+
 	cfg := &model.Config{
 		GCPProjectID:        gcpProjectID,
 		BigQueryDatasetID:   bqDatasetID,
@@ -108,6 +157,8 @@ func LoadConfig(logger *zap.Logger) (*model.Config, error) {
 		SyncTimeout:         syncTimeout,
 		DateFormat:          dateFormat,
 		DefaultBatchSize:    defaultBatchSize,
+		SyncConcurrency:     syncConcurrency,
+		HealthAddr:          getEnv(HealthAddr, ""),
 		MaxOpenConns:        maxOpen,
 		MaxIdleConns:        maxIdle,
 		ConnMaxLifetime:     connMaxLifetime,
@@ -115,6 +166,16 @@ func LoadConfig(logger *zap.Logger) (*model.Config, error) {
 		CreateTables:        createTables,
 		TruncateOnSync:      truncateOnSync,
 		MaxRowParseFailures: maxRowParseFailures,
+		SchemaDrift:         schemaDrift,
+		WatermarkStore:      watermarkStore,
+		WatermarkStateFile:  getEnv(WatermarkStateFile, "sync_state.json"),
+		WatermarkStateTable: getEnv(WatermarkStateTable, "_sync_watermarks"),
+		RetryMaxAttempts:    retryMaxAttempts,
+		RetryBaseDelay:      retryBaseDelay,
+		RetryMaxDelay:       retryMaxDelay,
+		CheckpointStore:     checkpointStore,
+		CheckpointFile:      getEnv(CheckpointFile, "sync_checkpoint.json"),
+		CheckpointTable:     getEnv(CheckpointTable, "_sync_checkpoints"),
 	}
 
 	logger.Info("Configuration loaded successfully",
@@ -123,6 +184,11 @@ func LoadConfig(logger *zap.Logger) (*model.Config, error) {
 		zap.Int("database_count", len(databases)),
 		zap.Bool("dry_run", cfg.DryRun),
 		zap.Int("max_row_parse_failures", cfg.MaxRowParseFailures),
+		zap.String("schema_drift", cfg.SchemaDrift),
+		zap.Int("sync_concurrency", cfg.SyncConcurrency),
+		zap.String("watermark_store", cfg.WatermarkStore),
+		zap.Int("retry_max_attempts", cfg.RetryMaxAttempts),
+		zap.String("checkpoint_store", cfg.CheckpointStore),
 	)
 
 	return cfg, nil
@@ -183,7 +249,11 @@ func loadTableConfigs(logger *zap.Logger, dbID string) (map[string]*model.TableC
 
 	tables := make(map[string]*model.TableConfig, len(tableList))
 	for _, tableName := range tableList {
-		tables[tableName] = loadTableConfig(logger, dbID, tableName)
+		tableConfig, err := loadTableConfig(logger, dbID, tableName)
+		if err != nil {
+			return nil, fmt.Errorf("table '%s': %w", tableName, err)
+		}
+		tables[tableName] = tableConfig
 	}
 
 	return tables, nil
@@ -191,25 +261,58 @@ func loadTableConfigs(logger *zap.Logger, dbID string) (map[string]*model.TableC
 
 // loadTableConfig loads configuration for a specific table.
 // Environment variables are prefixed with {DB_ID}_{TABLE_NAME}_ in uppercase.
-func loadTableConfig(logger *zap.Logger, dbID, tableName string) *model.TableConfig {
+// Incremental tables need a watermark column, which defaults to the timestamp column.
+func loadTableConfig(logger *zap.Logger, dbID, tableName string) (*model.TableConfig, error) {
 	prefix := strings.ToUpper(strings.TrimSpace(dbID)) + "_" + strings.ToUpper(strings.TrimSpace(tableName)) + "_"
 
 	targetTable := getEnv(prefix+"TARGET_TABLE", tableName)
 	primaryKey := getEnv(prefix+"PRIMARY_KEY", "id")
 	timestampCol := getEnv(prefix+"TIMESTAMP_COLUMN", "")
-	columnsStr := getEnv(prefix+"COLUMNS", "")
+	columns := parseCommaList(getEnv(prefix+"COLUMNS", ""))
 	batchSize := parseInt(logger, prefix+"BATCH_SIZE", "0", 0)
+	if batchSize < 0 {
+		return nil, fmt.Errorf("%sBATCH_SIZE must not be negative, got %d", prefix, batchSize)
+	}
+	dedupeKeys := parseCommaList(getEnv(prefix+"DEDUPE_KEYS", ""))
+	for _, key := range dedupeKeys {
+		if len(columns) > 0 && !slices.Contains(columns, key) {
+			return nil, fmt.Errorf("%sCOLUMNS must include the dedupe key column '%s'", prefix, key)
+		}
+	}
+	pagedReads := parseBool(getEnv(prefix+"PAGED_READS", getEnv(PagedReads, "false")))
 	enabled := parseBool(getEnv(prefix+"ENABLED", "true"))
 
-	return &model.TableConfig{
-		Name:            tableName,
-		TargetTable:     targetTable,
-		PrimaryKey:      primaryKey,
-		TimestampColumn: timestampCol,
-		Columns:         parseCommaList(columnsStr),
-		BatchSize:       batchSize,
-		Enabled:         enabled,
+	syncMode := strings.ToLower(strings.TrimSpace(getEnv(prefix+"SYNC_MODE", model.SyncModeFull)))
+	watermarkCol := strings.TrimSpace(getEnv(prefix+"WATERMARK_COLUMN", timestampCol))
+	watermarkInitial := getEnv(prefix+"WATERMARK_INITIAL", "")
+
+	switch syncMode {
+	case model.SyncModeFull:
+	case model.SyncModeIncremental:
+		if watermarkCol == "" {
+			return nil, fmt.Errorf("%sWATERMARK_COLUMN or %sTIMESTAMP_COLUMN is required when %sSYNC_MODE is incremental", prefix, prefix, prefix)
+		}
+		if len(columns) > 0 && !slices.Contains(columns, watermarkCol) {
+			return nil, fmt.Errorf("%sCOLUMNS must include the watermark column '%s'", prefix, watermarkCol)
+		}
+	default:
+		return nil, fmt.Errorf("%sSYNC_MODE must be %q or %q, got %q", prefix, model.SyncModeFull, model.SyncModeIncremental, syncMode)
 	}
+
+	return &model.TableConfig{
+		Name:             tableName,
+		TargetTable:      targetTable,
+		PrimaryKey:       primaryKey,
+		TimestampColumn:  timestampCol,
+		Columns:          columns,
+		BatchSize:        batchSize,
+		PagedReads:       pagedReads,
+		DedupeKeys:       dedupeKeys,
+		Enabled:          enabled,
+		SyncMode:         syncMode,
+		WatermarkColumn:  watermarkCol,
+		WatermarkInitial: watermarkInitial,
+	}, nil
 }
 
 // buildConnectionString creates a database connection string based on type.
@@ -0,0 +1,186 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package health serves the sync status over HTTP, so monitoring can detect a
+// stuck or failing sync: /health reports the state of the latest run as JSON
+// and /metrics exposes the same values in the Prometheus text format.
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/wso2-open-operations/common-tools/bigquery-flash-data-sync/internal/model"
+	"go.uber.org/zap"
+)
+
+// Status is a snapshot of the sync runs recorded by a Tracker.
+type Status struct {
+	// Status is "ok", "running" or "failing". It is "failing" only when the
+	// latest finished run failed, and "running" before the first run finishes.
+	Status             string     `json:"status"`
+	Running            bool       `json:"running"`
+	StartedAt          time.Time  `json:"started_at"`
+	LastRunStartedAt   *time.Time `json:"last_run_started_at,omitempty"`
+	LastSuccessAt      *time.Time `json:"last_success_at,omitempty"`
+	LastRunDuration    float64    `json:"last_run_duration_seconds"`
+	LastRunRowsSynced  int64      `json:"last_run_rows_synced"`
+	LastRunTablesOK    int        `json:"last_run_tables_succeeded"`
+	LastRunTablesError int        `json:"last_run_tables_failed"`
+	RowsSyncedTotal    int64      `json:"rows_synced_total"`
+	SuccessfulRuns     int        `json:"successful_runs"`
+	FailedRuns         int        `json:"failed_runs"`
+	LastError          string     `json:"last_error,omitempty"`
+	LastErrorAt        *time.Time `json:"last_error_at,omitempty"`
+}
+
+// Tracker records the outcome of sync runs. It is safe for concurrent use.
+type Tracker struct {
+	mu     sync.Mutex
+	status Status
+}
+
+// NewTracker returns a Tracker with no runs recorded.
+func NewTracker() *Tracker {
+	return &Tracker{status: Status{Status: "running", StartedAt: time.Now().UTC()}}
+}
+
+// RunStarted records the start of a sync run.
+func (t *Tracker) RunStarted() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now().UTC()
+	t.status.Running = true
+	t.status.LastRunStartedAt = &now
+}
+
+// RunFinished records the outcome of the run started last. summary may be nil
+// if the run failed before any table job was started.
+func (t *Tracker) RunFinished(summary *model.SyncSummary, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now().UTC()
+	s := &t.status
+	s.Running = false
+	if s.LastRunStartedAt != nil {
+		s.LastRunDuration = now.Sub(*s.LastRunStartedAt).Seconds()
+	}
+	s.LastRunRowsSynced, s.LastRunTablesOK, s.LastRunTablesError = 0, 0, 0
+	if summary != nil {
+		s.LastRunRowsSynced = summary.TotalRowsSynced
+		s.LastRunTablesOK = summary.SuccessfulSyncs
+		s.LastRunTablesError = summary.FailedSyncs
+		s.RowsSyncedTotal += summary.TotalRowsSynced
+	}
+
+	if err != nil {
+		s.Status = "failing"
+		s.FailedRuns++
+		s.LastError = err.Error()
+		s.LastErrorAt = &now
+		return
+	}
+	s.Status = "ok"
+	s.SuccessfulRuns++
+	s.LastSuccessAt = &now
+}
+
+// Snapshot returns the current status.
+func (t *Tracker) Snapshot() Status {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.status
+}
+
+// Handler returns a handler serving /health and /metrics.
+func (t *Tracker) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", t.serveHealth)
+	mux.HandleFunc("GET /metrics", t.serveMetrics)
+	return mux
+}
+
+// serveHealth writes the status as JSON, with 503 when the latest run failed.
+func (t *Tracker) serveHealth(w http.ResponseWriter, _ *http.Request) {
+	status := t.Snapshot()
+	code := http.StatusOK
+	if status.Status == "failing" {
+		code = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(status)
+}
+
+// serveMetrics writes the status in the Prometheus text exposition format.
+// Timestamps that have no value yet are reported as 0.
+func (t *Tracker) serveMetrics(w http.ResponseWriter, _ *http.Request) {
+	s := t.Snapshot()
+	running := 0
+	if s.Running {
+		running = 1
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	metric := func(name, kind, help string, value any) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %v\n", name, help, name, kind, name, value)
+	}
+	metric("datasync_running", "gauge", "Whether a sync run is in progress.", running)
+	metric("datasync_last_run_start_timestamp_seconds", "gauge", "Start time of the latest sync run.", unixSeconds(s.LastRunStartedAt))
+	metric("datasync_last_success_timestamp_seconds", "gauge", "Completion time of the latest successful sync run.", unixSeconds(s.LastSuccessAt))
+	metric("datasync_last_error_timestamp_seconds", "gauge", "Completion time of the latest failed sync run.", unixSeconds(s.LastErrorAt))
+	metric("datasync_last_run_duration_seconds", "gauge", "Duration of the latest finished sync run.", s.LastRunDuration)
+	metric("datasync_last_run_rows_synced", "gauge", "Rows loaded by the latest finished sync run.", s.LastRunRowsSynced)
+	metric("datasync_last_run_tables_failed", "gauge", "Tables that failed in the latest finished sync run.", s.LastRunTablesError)
+	metric("datasync_rows_synced_total", "counter", "Rows loaded by all finished sync runs.", s.RowsSyncedTotal)
+	fmt.Fprintf(w, "# HELP datasync_runs_total Finished sync runs by result.\n# TYPE datasync_runs_total counter\n")
+	fmt.Fprintf(w, "datasync_runs_total{result=\"success\"} %d\ndatasync_runs_total{result=\"failure\"} %d\n", s.SuccessfulRuns, s.FailedRuns)
+}
+
+// unixSeconds returns t as Unix seconds, or 0 when t is nil.
+func unixSeconds(t *time.Time) float64 {
+	if t == nil {
+		return 0
+	}
+	return float64(t.UnixNano()) / 1e9
+}
+
+// StartServer serves t's handler on addr in the background. It does nothing
+// if addr is empty.
+func StartServer(addr string, t *Tracker, logger *zap.Logger) {
+	if addr == "" {
+		return
+	}
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           t.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		logger.Info("Health endpoint listening", zap.String("addr", addr), zap.Strings("paths", []string{"/health", "/metrics"}))
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Health endpoint stopped", zap.Error(err))
+		}
+	}()
+}
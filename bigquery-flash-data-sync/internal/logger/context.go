@@ -0,0 +1,60 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// fieldsKey is the context key under which ContextWithFields stores fields.
+type fieldsKey struct{}
+
+// ContextWithFields returns a copy of ctx that carries fields in addition to
+// any already stored by earlier calls. Loggers obtained with WithContext from
+// the returned context, or from contexts derived from it, include them all.
+func ContextWithFields(ctx context.Context, fields ...zap.Field) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+	existing := fieldsFromContext(ctx)
+	merged := make([]zap.Field, 0, len(existing)+len(fields))
+	merged = append(merged, existing...)
+	merged = append(merged, fields...)
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// WithContext returns the global Logger with the fields stored in ctx by
+// ContextWithFields. It returns Logger itself if ctx carries no fields, so it
+// is cheap to call at the start of every function that has a context.
+func WithContext(ctx context.Context) *zap.Logger {
+	fields := fieldsFromContext(ctx)
+	if len(fields) == 0 {
+		return Logger
+	}
+	return Logger.With(fields...)
+}
+
+// fieldsFromContext returns the fields stored in ctx, or nil if there are none.
+func fieldsFromContext(ctx context.Context) []zap.Field {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsKey{}).([]zap.Field)
+	return fields
+}
@@ -18,11 +18,26 @@
 package logger
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Defaults for log file rotation, used when LOG_FILE is set and the
+// corresponding environment variable is missing or invalid.
+const (
+	defaultLogMaxSizeMB  = 100
+	defaultLogMaxBackups = 5
+	defaultLogMaxAgeDays = 30
 )
 
 var (
@@ -31,71 +46,335 @@ var (
 	// before InitLogger() is called.
 	Logger = zap.NewNop()
 
+	// level is shared by every core of Logger so that SetLevel and the admin
+	// endpoint change verbosity without rebuilding the logger.
+	level = zap.NewAtomicLevel()
+
 	initOnce sync.Once
 )
 
+// Options configures a logger built by New. The zero value gives a
+// development logger at info level writing to stderr, with the default
+// redaction fields. OptionsFromEnv fills it from the LOG_* variables.
+type Options struct {
+	// Env selects the preset: "prod" for JSON with sampling, anything else
+	// for a colored development console.
+	Env string
+	// Level is the minimum level logged.
+	Level zapcore.Level
+	// Format overrides the encoder of the preset: "json", "console" or empty.
+	Format string
+	// OutputPaths replaces the preset's stderr output, using zap's path
+	// syntax such as "stdout" or a file path.
+	OutputPaths []string
+
+	// File, if set, also receives every entry and is rotated at MaxSizeMB,
+	// keeping MaxBackups files for MaxAgeDays. Zero values use the defaults.
+	File       string
+	MaxSizeMB  int
+	MaxBackups int
+	MaxAgeDays int
+
+	// RedactFields are the field names masked as [REDACTED]. Nil uses the
+	// defaults; an empty, non-nil slice disables field redaction.
+	RedactFields []string
+	// RedactPatterns names the patterns scrubbed from messages and string
+	// fields: "email" and "base64".
+	RedactPatterns []string
+
+	// SampleInitial and SampleThereafter override the sampling of the prod
+	// preset. Zero keeps the preset's value.
+	SampleInitial    int
+	SampleThereafter int
+}
+
+// OptionsFromEnv returns the options described by the LOG_* environment
+// variables. Invalid numbers and levels fall back to their defaults.
+func OptionsFromEnv() Options {
+	opts := Options{
+		Env:              os.Getenv("LOG_ENV"),
+		Level:            getLogLevelFromEnv(),
+		Format:           os.Getenv("LOG_FORMAT"),
+		File:             os.Getenv("LOG_FILE"),
+		MaxSizeMB:        getPositiveIntFromEnv("LOG_MAX_SIZE_MB", 0),
+		MaxBackups:       getPositiveIntFromEnv("LOG_MAX_BACKUPS", 0),
+		MaxAgeDays:       getPositiveIntFromEnv("LOG_MAX_AGE_DAYS", 0),
+		SampleInitial:    getPositiveIntFromEnv("LOG_SAMPLE_INITIAL", 0),
+		SampleThereafter: getPositiveIntFromEnv("LOG_SAMPLE_THEREAFTER", 0),
+		RedactPatterns:   splitList(os.Getenv("LOG_REDACT_PATTERNS")),
+	}
+	if v, ok := os.LookupEnv("LOG_REDACT_FIELDS"); ok {
+		opts.RedactFields = append([]string{}, splitList(v)...)
+	}
+	return opts
+}
+
+// splitList splits a comma-separated value, dropping empty entries.
+func splitList(v string) []string {
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// New builds a logger from opts that the caller owns, independent of the
+// global Logger, so components and tests can be handed an explicit logger.
+// Its level is not changed by SetLevel or the level endpoint. Unlike
+// InitLogger it rejects an unknown Format or redaction pattern.
+func New(opts Options) (*zap.Logger, error) {
+	if opts.Format != "" && opts.Format != "json" && opts.Format != "console" {
+		return nil, fmt.Errorf("invalid log format %q: must be json or console", opts.Format)
+	}
+	l, unknownPatterns, err := build(opts, zap.NewAtomicLevelAt(opts.Level))
+	if err != nil {
+		return nil, err
+	}
+	if len(unknownPatterns) > 0 {
+		return nil, fmt.Errorf("unknown redaction patterns %q: must be email or base64", unknownPatterns)
+	}
+	return l, nil
+}
+
 // InitLogger initializes the global logger based on environment configuration.
 // This function is idempotent and thread-safe.
 func InitLogger() {
 	initOnce.Do(func() {
-		logEnv := os.Getenv("LOG_ENV")
-		logLevel := getLogLevelFromEnv()
-
-		var config zap.Config
-		if logEnv == "prod" {
-			config = zap.NewProductionConfig()
-		} else {
-			config = zap.NewDevelopmentConfig()
-			config.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
-		}
-
-		config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
-		config.Level = zap.NewAtomicLevelAt(logLevel)
-
-		l, err := config.Build(
-			zap.AddCallerSkip(0),
-			zap.AddStacktrace(zapcore.ErrorLevel),
-		)
+		opts := OptionsFromEnv()
+		l, unknownPatterns, err := build(opts, level)
 		if err != nil {
 			panic("Failed to initialize logger: " + err.Error())
 		}
 
 		Logger = l
-		Logger.Info(
-			"Logger initialized",
-			zap.String("LOG_ENV", logEnv),
-			zap.String("LOG_LEVEL", logLevel.String()),
-		)
+		fields := []zap.Field{
+			zap.String("LOG_ENV", opts.Env),
+			zap.String("LOG_LEVEL", opts.Level.String()),
+		}
+		if opts.Format != "" {
+			fields = append(fields, zap.String("LOG_FORMAT", opts.Format))
+		}
+		if opts.Env == "prod" {
+			initial, thereafter := sampling(opts)
+			fields = append(fields,
+				zap.Int("LOG_SAMPLE_INITIAL", initial),
+				zap.Int("LOG_SAMPLE_THEREAFTER", thereafter),
+			)
+		}
+		if opts.File != "" {
+			fields = append(fields, zap.String("LOG_FILE", opts.File))
+		}
+		Logger.Info("Logger initialized", fields...)
+		for _, name := range unknownPatterns {
+			Logger.Warn("Ignoring unknown LOG_REDACT_PATTERNS entry, expected email or base64", zap.String("pattern", name))
+		}
+		if opts.Format != "" && opts.Format != "json" && opts.Format != "console" {
+			Logger.Warn("Ignoring unknown LOG_FORMAT, expected json or console", zap.String("LOG_FORMAT", opts.Format))
+		}
 	})
 }
 
+// sampling returns the sampling settings of the prod preset after applying
+// the overrides in opts.
+func sampling(opts Options) (initial, thereafter int) {
+	preset := zap.NewProductionConfig().Sampling
+	initial, thereafter = preset.Initial, preset.Thereafter
+	if opts.SampleInitial > 0 {
+		initial = opts.SampleInitial
+	}
+	if opts.SampleThereafter > 0 {
+		thereafter = opts.SampleThereafter
+	}
+	return initial, thereafter
+}
+
+// build creates a logger from opts whose cores all use lvl. An unknown Format
+// is ignored; unknown redaction pattern names are returned for the caller to
+// report.
+func build(opts Options, lvl zap.AtomicLevel) (*zap.Logger, []string, error) {
+	var config zap.Config
+	if opts.Env == "prod" {
+		config = zap.NewProductionConfig()
+	} else {
+		config = zap.NewDevelopmentConfig()
+		config.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	}
+
+	// Format overrides the encoder of the preset chosen by Env. JSON always
+	// uses the production field names so log pipelines see the same shape
+	// everywhere, and colored levels are only kept for console output in dev.
+	switch opts.Format {
+	case "json":
+		config.Encoding = "json"
+		config.EncoderConfig = zap.NewProductionEncoderConfig()
+	case "console":
+		if config.Encoding != "console" {
+			config.Encoding = "console"
+			config.EncoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+		}
+	}
+	if len(opts.OutputPaths) > 0 {
+		config.OutputPaths = opts.OutputPaths
+	}
+
+	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	lvl.SetLevel(opts.Level)
+	config.Level = lvl
+
+	zapOpts := []zap.Option{
+		zap.AddCallerSkip(0),
+		zap.AddStacktrace(zapcore.ErrorLevel),
+	}
+
+	var fileCore zapcore.Core
+	if opts.File != "" {
+		fileCore = newFileCore(config, opts)
+	}
+	redact, unknownPatterns := newRedactor(opts.RedactFields, opts.RedactPatterns)
+
+	// Sampling is applied here rather than by config.Build so that it sits
+	// outside redaction and covers the log file as well as the console.
+	// Only the prod preset samples; dev keeps every line for full fidelity.
+	sampled := config.Sampling != nil
+	config.Sampling = nil
+	initial, thereafter := sampling(opts)
+	zapOpts = append(zapOpts, zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		if fileCore != nil {
+			core = zapcore.NewTee(core, fileCore)
+		}
+		core = newRedactingCore(core, redact)
+		if sampled {
+			core = zapcore.NewSamplerWithOptions(core, time.Second, initial, thereafter)
+		}
+		return core
+	}))
+
+	l, err := config.Build(zapOpts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return l, unknownPatterns, nil
+}
+
+// newFileCore returns a core that writes entries to opts.File in the same
+// format as config, rotating the file as configured in opts. Level colors are
+// dropped so the file stays plain text.
+func newFileCore(config zap.Config, opts Options) zapcore.Core {
+	writer := &lumberjack.Logger{
+		Filename:   opts.File,
+		MaxSize:    positiveOr(opts.MaxSizeMB, defaultLogMaxSizeMB),
+		MaxBackups: positiveOr(opts.MaxBackups, defaultLogMaxBackups),
+		MaxAge:     positiveOr(opts.MaxAgeDays, defaultLogMaxAgeDays),
+	}
+
+	var encoder zapcore.Encoder
+	if config.Encoding == "json" {
+		encoder = zapcore.NewJSONEncoder(config.EncoderConfig)
+	} else {
+		encoderConfig := config.EncoderConfig
+		encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	}
+	// Table jobs log from many goroutines; Lock serializes writes and syncs to
+	// the file the same way zap does for the console output.
+	return zapcore.NewCore(encoder, zapcore.Lock(zapcore.AddSync(writer)), config.Level)
+}
+
+// positiveOr returns v, or fallback if v is not positive.
+func positiveOr(v, fallback int) int {
+	if v > 0 {
+		return v
+	}
+	return fallback
+}
+
+// getPositiveIntFromEnv reads a positive integer from the named environment
+// variable. Defaults to fallback if not set or invalid.
+func getPositiveIntFromEnv(key string, fallback int) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || v <= 0 {
+		return fallback
+	}
+	return v
+}
+
 // getLogLevelFromEnv reads the LOG_LEVEL environment variable and returns
 // the corresponding zapcore.Level.
 // Defaults to InfoLevel if not set or invalid.
 func getLogLevelFromEnv() zapcore.Level {
-	switch os.Getenv("LOG_LEVEL") {
+	if l, ok := parseLevel(os.Getenv("LOG_LEVEL")); ok {
+		return l
+	}
+	return zapcore.InfoLevel
+}
+
+// parseLevel maps a level name accepted in LOG_LEVEL to its zapcore.Level.
+func parseLevel(name string) (zapcore.Level, bool) {
+	switch name {
 	case "debug":
-		return zapcore.DebugLevel
+		return zapcore.DebugLevel, true
 	case "info":
-		return zapcore.InfoLevel
+		return zapcore.InfoLevel, true
 	case "warn":
-		return zapcore.WarnLevel
+		return zapcore.WarnLevel, true
 	case "error":
-		return zapcore.ErrorLevel
+		return zapcore.ErrorLevel, true
 	case "dpanic":
-		return zapcore.DPanicLevel
+		return zapcore.DPanicLevel, true
 	case "panic":
-		return zapcore.PanicLevel
+		return zapcore.PanicLevel, true
 	case "fatal":
-		return zapcore.FatalLevel
+		return zapcore.FatalLevel, true
 	default:
-		return zapcore.InfoLevel
+		return zapcore.InfoLevel, false
 	}
 }
 
+// SetLevel changes the level of the global logger while it is running. It
+// accepts the same names as LOG_LEVEL and leaves the level unchanged otherwise.
+func SetLevel(name string) error {
+	l, ok := parseLevel(name)
+	if !ok {
+		return fmt.Errorf("invalid log level %q: must be one of debug, info, warn, error, dpanic, panic, fatal", name)
+	}
+	previous := level.Level()
+	level.SetLevel(l)
+	Logger.Info("Log level changed",
+		zap.String("from", previous.String()),
+		zap.String("to", l.String()),
+	)
+	return nil
+}
+
+// StartLevelServer serves zap's level endpoint at /log/level on the address in
+// LOG_ADMIN_ADDR, so operators can read the level with GET and change it with
+// PUT while a sync is running. It does nothing if LOG_ADMIN_ADDR is not set.
+func StartLevelServer() {
+	addr := os.Getenv("LOG_ADMIN_ADDR")
+	if addr == "" {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/log/level", level)
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		Logger.Info("Log level endpoint listening", zap.String("addr", addr), zap.String("path", "/log/level"))
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			Logger.Error("Log level endpoint stopped", zap.Error(err))
+		}
+	}()
+}
+
 // Sync flushes any buffered log entries.
 // Safe to call even if InitLogger() was never invoked.
 func Sync() {
 	_ = Logger.Sync()
 }
-
@@ -0,0 +1,341 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// redactedValue replaces the value of a redacted field and any text matched by
+// a scrub pattern.
+const redactedValue = "[REDACTED]"
+
+// defaultRedactFields are the field names masked when LOG_REDACT_FIELDS is not
+// set.
+var defaultRedactFields = []string{"password", "secret", "token", "authorization", "service_account_key", "dsn"}
+
+// redactPatterns are the named patterns that LOG_REDACT_PATTERNS can enable to
+// scrub matching text from messages and string values of any field.
+var redactPatterns = map[string]*regexp.Regexp{
+	"email":  regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	"base64": regexp.MustCompile(`[A-Za-z0-9+/_-]{40,}={0,2}`),
+}
+
+// redactor masks sensitive field values and scrubs sensitive text.
+type redactor struct {
+	fields   map[string]bool
+	patterns []*regexp.Regexp
+}
+
+// newRedactor builds a redactor masking the named fields, or
+// defaultRedactFields when fields is nil, and scrubbing the named patterns.
+// Pattern names it does not know are returned so that they can be reported
+// once the logger exists.
+func newRedactor(fields, patterns []string) (*redactor, []string) {
+	if fields == nil {
+		fields = defaultRedactFields
+	}
+
+	r := &redactor{fields: make(map[string]bool)}
+	for _, name := range fields {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			r.fields[name] = true
+		}
+	}
+
+	var unknown []string
+	for _, name := range patterns {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if re, ok := redactPatterns[name]; ok {
+			r.patterns = append(r.patterns, re)
+		} else {
+			unknown = append(unknown, name)
+		}
+	}
+	return r, unknown
+}
+
+// enabled reports whether the redactor would change anything.
+func (r *redactor) enabled() bool {
+	return len(r.fields) > 0 || len(r.patterns) > 0
+}
+
+// redactKey reports whether a field's value must be masked. A key matches a
+// configured name exactly or as a suffix after an underscore or dot, so
+// "password" also covers "db_password", ignoring case.
+func (r *redactor) redactKey(key string) bool {
+	key = strings.ToLower(key)
+	if r.fields[key] {
+		return true
+	}
+	for name := range r.fields {
+		if strings.HasSuffix(key, "_"+name) || strings.HasSuffix(key, "."+name) {
+			return true
+		}
+	}
+	return false
+}
+
+// scrub replaces text matching any enabled pattern.
+func (r *redactor) scrub(s string) string {
+	for _, re := range r.patterns {
+		s = re.ReplaceAllString(s, redactedValue)
+	}
+	return s
+}
+
+// redact returns fields with sensitive values masked and string values
+// scrubbed. The input slice is not modified.
+func (r *redactor) redact(fields []zapcore.Field) []zapcore.Field {
+	var out []zapcore.Field
+	for i, f := range fields {
+		replaced, changed := r.redactField(f)
+		if !changed {
+			if out != nil {
+				out = append(out, f)
+			}
+			continue
+		}
+		if out == nil {
+			out = make([]zapcore.Field, i, len(fields))
+			copy(out, fields[:i])
+		}
+		out = append(out, replaced)
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
+
+// redactField masks or scrubs a single field and reports whether it changed.
+// Objects, arrays and reflected values such as zap.Any structs and maps are
+// searched as well, so keys nested inside them are masked like top-level ones.
+func (r *redactor) redactField(f zapcore.Field) (zapcore.Field, bool) {
+	if r.redactKey(f.Key) {
+		return zap.String(f.Key, redactedValue), true
+	}
+
+	switch f.Type {
+	case zapcore.ObjectMarshalerType, zapcore.InlineMarshalerType:
+		m, ok := f.Interface.(zapcore.ObjectMarshaler)
+		if !ok {
+			return f, false
+		}
+		enc := zapcore.NewMapObjectEncoder()
+		if err := m.MarshalLogObject(enc); err != nil {
+			return f, false
+		}
+		cleaned, changed := r.redactValue(enc.Fields)
+		if !changed {
+			return f, false
+		}
+		if f.Type == zapcore.InlineMarshalerType {
+			return zap.Inline(redactedObject(cleaned.(map[string]any))), true
+		}
+		return zap.Object(f.Key, redactedObject(cleaned.(map[string]any))), true
+	case zapcore.ArrayMarshalerType:
+		m, ok := f.Interface.(zapcore.ArrayMarshaler)
+		if !ok {
+			return f, false
+		}
+		// A map encoder collects the array's elements under a single key.
+		enc := zapcore.NewMapObjectEncoder()
+		if err := enc.AddArray(f.Key, m); err != nil {
+			return f, false
+		}
+		if cleaned, changed := r.redactValue(enc.Fields[f.Key]); changed {
+			return zap.Any(f.Key, cleaned), true
+		}
+		return f, false
+	case zapcore.ReflectType:
+		if cleaned, changed := r.redactValue(f.Interface); changed {
+			return zap.Any(f.Key, cleaned), true
+		}
+		return f, false
+	}
+
+	if len(r.patterns) == 0 {
+		return f, false
+	}
+	var s string
+	switch f.Type {
+	case zapcore.StringType:
+		s = f.String
+	case zapcore.ByteStringType:
+		b, ok := f.Interface.([]byte)
+		if !ok {
+			return f, false
+		}
+		s = string(b)
+	case zapcore.ErrorType:
+		err, ok := f.Interface.(error)
+		if !ok || err == nil {
+			return f, false
+		}
+		s = err.Error()
+	case zapcore.StringerType:
+		str, ok := f.Interface.(interface{ String() string })
+		if !ok {
+			return f, false
+		}
+		s = str.String()
+	default:
+		return f, false
+	}
+	if scrubbed := r.scrub(s); scrubbed != s {
+		return zap.String(f.Key, scrubbed), true
+	}
+	return f, false
+}
+
+// redactValue masks keys and scrubs strings anywhere inside v, returning the
+// cleaned value and whether anything changed. When nothing changes v is
+// returned as is. Structs, maps and slices of other types are first converted
+// through JSON, the form zap writes reflected values in, so their field names
+// are matched as they appear in the log.
+func (r *redactor) redactValue(v any) (any, bool) {
+	switch v := v.(type) {
+	case nil:
+		return nil, false
+	case string:
+		scrubbed := r.scrub(v)
+		return scrubbed, scrubbed != v
+	case []byte:
+		scrubbed := r.scrub(string(v))
+		return scrubbed, scrubbed != string(v)
+	case map[string]any:
+		var out map[string]any
+		for key, value := range v {
+			var cleaned any = redactedValue
+			changed := true
+			if !r.redactKey(key) {
+				cleaned, changed = r.redactValue(value)
+			}
+			if !changed {
+				continue
+			}
+			if out == nil {
+				out = maps.Clone(v)
+			}
+			out[key] = cleaned
+		}
+		if out == nil {
+			return v, false
+		}
+		return out, true
+	case []any:
+		var out []any
+		for i, value := range v {
+			cleaned, changed := r.redactValue(value)
+			if !changed {
+				continue
+			}
+			if out == nil {
+				out = slices.Clone(v)
+			}
+			out[i] = cleaned
+		}
+		if out == nil {
+			return v, false
+		}
+		return out, true
+	}
+
+	switch reflect.Indirect(reflect.ValueOf(v)).Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+	default:
+		return v, false
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v, false
+	}
+	// Numbers are kept as written so large integers survive the round trip.
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var generic any
+	if err := dec.Decode(&generic); err != nil {
+		return v, false
+	}
+	if cleaned, changed := r.redactValue(generic); changed {
+		return cleaned, true
+	}
+	return v, false
+}
+
+// redactedObject logs a cleaned object, its keys in sorted order.
+type redactedObject map[string]any
+
+func (o redactedObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, key := range slices.Sorted(maps.Keys(o)) {
+		if err := enc.AddReflected(key, o[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// redactingCore masks sensitive data in every entry before passing it to the
+// wrapped core, so it applies to all logs whichever logger or field produced
+// them.
+type redactingCore struct {
+	zapcore.Core
+	r *redactor
+}
+
+// newRedactingCore wraps core with r. The core is returned unchanged if r has
+// nothing to redact.
+func newRedactingCore(core zapcore.Core, r *redactor) zapcore.Core {
+	if !r.enabled() {
+		return core
+	}
+	return &redactingCore{Core: core, r: r}
+}
+
+// With redacts fields before they are attached to the child core.
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactingCore{Core: c.Core.With(c.r.redact(fields)), r: c.r}
+}
+
+// Check adds c, rather than the wrapped core, so that Write sees the entry.
+func (c *redactingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write scrubs the message and redacts fields, then writes to the wrapped core.
+func (c *redactingCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if len(c.r.patterns) > 0 {
+		ent.Message = c.r.scrub(ent.Message)
+	}
+	return c.Core.Write(ent, c.r.redact(fields))
+}
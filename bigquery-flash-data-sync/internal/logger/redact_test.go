@@ -0,0 +1,154 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// redactedLog writes one entry with fields through a redacting core and returns
+// the JSON line it produced.
+func redactedLog(t *testing.T, fields, patterns []string, logFields ...zap.Field) string {
+	t.Helper()
+	var buf bytes.Buffer
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"})
+	r, unknown := newRedactor(fields, patterns)
+	if len(unknown) > 0 {
+		t.Fatalf("unknown patterns %v", unknown)
+	}
+	core := newRedactingCore(zapcore.NewCore(enc, zapcore.AddSync(&buf), zapcore.DebugLevel), r)
+	zap.New(core).Info("contact jane@example.com", logFields...)
+	return buf.String()
+}
+
+type dbConfig struct {
+	Host     string
+	Password string
+	Rows     int64
+}
+
+type syncConfig struct {
+	Name string
+	DB   dbConfig
+	Tags []string
+}
+
+func TestRedactingCore(t *testing.T) {
+	objectWithToken := zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+		enc.AddString("user", "jane")
+		enc.AddString("token", "s3cr3t")
+		return nil
+	})
+
+	tests := []struct {
+		name     string
+		patterns []string
+		fields   []zap.Field
+		want     []string
+		wantNot  []string
+	}{
+		{
+			name:    "top-level key",
+			fields:  []zap.Field{zap.String("db_password", "s3cr3t"), zap.String("host", "db.local")},
+			want:    []string{`"db_password":"[REDACTED]"`, `"host":"db.local"`},
+			wantNot: []string{"s3cr3t"},
+		},
+		{
+			name: "nested struct through zap.Any",
+			fields: []zap.Field{zap.Any("config", syncConfig{
+				Name: "finance",
+				DB:   dbConfig{Host: "db.local", Password: "s3cr3t", Rows: 9007199254740993},
+			})},
+			want:    []string{`"Password":"[REDACTED]"`, `"Host":"db.local"`, `"Rows":9007199254740993`},
+			wantNot: []string{"s3cr3t"},
+		},
+		{
+			name: "nested map through zap.Any",
+			fields: []zap.Field{zap.Any("settings", map[string]any{
+				"db": map[string]any{"secret": "s3cr3t", "port": 5432},
+			})},
+			want:    []string{`"secret":"[REDACTED]"`, `"port":5432`},
+			wantNot: []string{"s3cr3t"},
+		},
+		{
+			name:    "object marshaler",
+			fields:  []zap.Field{zap.Object("session", objectWithToken)},
+			want:    []string{`"session":{`, `"token":"[REDACTED]"`, `"user":"jane"`},
+			wantNot: []string{"s3cr3t"},
+		},
+		{
+			name:    "inline marshaler",
+			fields:  []zap.Field{zap.Inline(objectWithToken)},
+			want:    []string{`"token":"[REDACTED]"`, `"user":"jane"`},
+			wantNot: []string{"s3cr3t"},
+		},
+		{
+			name:     "array of strings",
+			patterns: []string{"email"},
+			fields:   []zap.Field{zap.Strings("recipients", []string{"jane@example.com", "ops"})},
+			want:     []string{`"recipients":["[REDACTED]","ops"]`},
+			wantNot:  []string{"jane@example.com"},
+		},
+		{
+			name:     "byte string",
+			patterns: []string{"email"},
+			fields:   []zap.Field{zap.ByteString("raw", []byte("from jane@example.com"))},
+			want:     []string{`"raw":"from [REDACTED]"`},
+		},
+		{
+			name:     "error and message",
+			patterns: []string{"email"},
+			fields:   []zap.Field{zap.Error(errors.New("rejected jane@example.com"))},
+			want:     []string{`"msg":"contact [REDACTED]"`, `"error":"rejected [REDACTED]"`},
+			wantNot:  []string{"jane@example.com"},
+		},
+		{
+			name:     "string nested in a struct",
+			patterns: []string{"email"},
+			fields:   []zap.Field{zap.Any("config", syncConfig{Name: "owner jane@example.com", Tags: []string{"jane@example.com"}})},
+			want:     []string{`"Name":"owner [REDACTED]"`, `"Tags":["[REDACTED]"]`},
+			wantNot:  []string{"jane@example.com"},
+		},
+		{
+			name:   "clean values are left alone",
+			fields: []zap.Field{zap.Any("table", struct{ Name, Mode string }{"finance", "full"}), zap.Int64("rows", 42)},
+			want:   []string{`"table":{"Name":"finance","Mode":"full"}`, `"rows":42`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := redactedLog(t, nil, tt.patterns, tt.fields...)
+			for _, want := range tt.want {
+				if !strings.Contains(line, want) {
+					t.Errorf("log line %s does not contain %s", line, want)
+				}
+			}
+			for _, leaked := range tt.wantNot {
+				if strings.Contains(line, leaked) {
+					t.Errorf("log line %s leaks %q", line, leaked)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2025 WSO2 LLC.  (https://www.wso2.com).
+//
+// WSO2 LLC.  licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/bigquery"
+)
+
+// Schema drift policies, applied when a source schema no longer matches its BigQuery table.
+const (
+	SchemaDriftFail       = "fail"        // Fail the table sync and report the drift
+	SchemaDriftAddColumns = "add_columns" // Add new NULLABLE source columns, fail on any other drift
+)
+
+// ColumnChange describes a column present in both schemas whose definition differs.
+type ColumnChange struct {
+	Name string
+	From *bigquery.FieldSchema // Definition in the destination table
+	To   *bigquery.FieldSchema // Definition inferred from the source
+}
+
+// SchemaDrift is the structured difference between a destination BigQuery schema
+// and the schema inferred from its source, as returned by DiffSchemas. Columns are
+// listed in the order of the schema they come from.
+type SchemaDrift struct {
+	Added       []*bigquery.FieldSchema // Source columns missing from the destination
+	Removed     []*bigquery.FieldSchema // Destination columns missing from the source
+	Retyped     []ColumnChange          // Columns whose type changed
+	ModeChanged []ColumnChange          // Columns whose mode (NULLABLE, REQUIRED, REPEATED) changed
+}
+
+// DiffSchemas compares the top-level columns of destination with source. Column
+// names are matched case-insensitively, as BigQuery does; column order is ignored.
+func DiffSchemas(destination, source bigquery.Schema) SchemaDrift {
+	var drift SchemaDrift
+
+	existing := make(map[string]*bigquery.FieldSchema, len(destination))
+	for _, field := range destination {
+		existing[strings.ToLower(field.Name)] = field
+	}
+	inferred := make(map[string]bool, len(source))
+	for _, field := range source {
+		key := strings.ToLower(field.Name)
+		inferred[key] = true
+		current, ok := existing[key]
+		switch {
+		case !ok:
+			drift.Added = append(drift.Added, field)
+		case current.Type != field.Type:
+			drift.Retyped = append(drift.Retyped, ColumnChange{Name: field.Name, From: current, To: field})
+		case fieldMode(current) != fieldMode(field):
+			drift.ModeChanged = append(drift.ModeChanged, ColumnChange{Name: field.Name, From: current, To: field})
+		}
+	}
+	for _, field := range destination {
+		if !inferred[strings.ToLower(field.Name)] {
+			drift.Removed = append(drift.Removed, field)
+		}
+	}
+
+	return drift
+}
+
+// Empty reports whether the schemas match.
+func (d SchemaDrift) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Retyped) == 0 && len(d.ModeChanged) == 0
+}
+
+// Additive reports whether the only drift is new NULLABLE columns, which BigQuery
+// can add to an existing table in place.
+func (d SchemaDrift) Additive() bool {
+	if len(d.Added) == 0 || len(d.Removed) > 0 || len(d.Retyped) > 0 || len(d.ModeChanged) > 0 {
+		return false
+	}
+	for _, field := range d.Added {
+		if fieldMode(field) != "NULLABLE" {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns a readable report of every difference, e.g.
+// "added column email (STRING NULLABLE); column amount changed type from INTEGER to NUMERIC".
+func (d SchemaDrift) String() string {
+	var parts []string
+	for _, field := range d.Added {
+		parts = append(parts, fmt.Sprintf("added column %s (%s %s)", field.Name, field.Type, fieldMode(field)))
+	}
+	for _, field := range d.Removed {
+		parts = append(parts, fmt.Sprintf("removed column %s", field.Name))
+	}
+	for _, change := range d.Retyped {
+		parts = append(parts, fmt.Sprintf("column %s changed type from %s to %s", change.Name, change.From.Type, change.To.Type))
+	}
+	for _, change := range d.ModeChanged {
+		parts = append(parts, fmt.Sprintf("column %s changed from %s to %s", change.Name, fieldMode(change.From), fieldMode(change.To)))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// ColumnNames returns the names of the given fields.
+func ColumnNames(fields []*bigquery.FieldSchema) []string {
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i] = field.Name
+	}
+	return names
+}
+
+// ChangedColumnNames returns the names of the given changed columns.
+func ChangedColumnNames(changes []ColumnChange) []string {
+	names := make([]string, len(changes))
+	for i, change := range changes {
+		names[i] = change.Name
+	}
+	return names
+}
+
+// fieldMode returns the BigQuery mode of field.
+func fieldMode(field *bigquery.FieldSchema) string {
+	switch {
+	case field.Repeated:
+		return "REPEATED"
+	case field.Required:
+		return "REQUIRED"
+	default:
+		return "NULLABLE"
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2025 WSO2 LLC.  (https://www.wso2.com).
+//
+// WSO2 LLC.  licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package model
+
+import (
+	"slices"
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+)
+
+// field returns a NULLABLE column, or a REQUIRED one when required is set.
+func field(name string, typ bigquery.FieldType, required bool) *bigquery.FieldSchema {
+	return &bigquery.FieldSchema{Name: name, Type: typ, Required: required}
+}
+
+func TestDiffSchemas(t *testing.T) {
+	base := bigquery.Schema{
+		field("id", bigquery.IntegerFieldType, true),
+		field("name", bigquery.StringFieldType, false),
+		field("amount", bigquery.IntegerFieldType, false),
+	}
+	tests := []struct {
+		name        string
+		destination bigquery.Schema
+		source      bigquery.Schema
+		added       []string
+		removed     []string
+		retyped     []string
+		modeChanged []string
+		additive    bool
+		report      string
+	}{
+		{
+			name:        "unchanged",
+			destination: base,
+			source:      base,
+		},
+		{
+			name:        "unchanged ignoring name case and column order",
+			destination: base,
+			source: bigquery.Schema{
+				field("AMOUNT", bigquery.IntegerFieldType, false),
+				field("Id", bigquery.IntegerFieldType, true),
+				field("name", bigquery.StringFieldType, false),
+			},
+		},
+		{
+			name:        "added nullable column",
+			destination: base,
+			source:      append(slices.Clone(base), field("email", bigquery.StringFieldType, false)),
+			added:       []string{"email"},
+			additive:    true,
+			report:      "added column email (STRING NULLABLE)",
+		},
+		{
+			name:        "added required column",
+			destination: base,
+			source:      append(slices.Clone(base), field("email", bigquery.StringFieldType, true)),
+			added:       []string{"email"},
+			report:      "added column email (STRING REQUIRED)",
+		},
+		{
+			name:        "removed column",
+			destination: base,
+			source:      base[:2],
+			removed:     []string{"amount"},
+			report:      "removed column amount",
+		},
+		{
+			name:        "retyped column",
+			destination: base,
+			source: bigquery.Schema{
+				base[0],
+				base[1],
+				field("amount", bigquery.NumericFieldType, false),
+			},
+			retyped: []string{"amount"},
+			report:  "column amount changed type from INTEGER to NUMERIC",
+		},
+		{
+			name:        "mode changed",
+			destination: base,
+			source: bigquery.Schema{
+				base[0],
+				field("name", bigquery.StringFieldType, true),
+				base[2],
+			},
+			modeChanged: []string{"name"},
+			report:      "column name changed from NULLABLE to REQUIRED",
+		},
+		{
+			name:        "retyped and mode changed reports the type only",
+			destination: base,
+			source: bigquery.Schema{
+				base[0],
+				base[1],
+				field("amount", bigquery.FloatFieldType, true),
+			},
+			retyped: []string{"amount"},
+			report:  "column amount changed type from INTEGER to FLOAT",
+		},
+		{
+			name:        "every kind of drift",
+			destination: base,
+			source: bigquery.Schema{
+				field("id", bigquery.StringFieldType, true),
+				field("name", bigquery.StringFieldType, true),
+				field("created_at", bigquery.TimestampFieldType, false),
+			},
+			added:       []string{"created_at"},
+			removed:     []string{"amount"},
+			retyped:     []string{"id"},
+			modeChanged: []string{"name"},
+			report:      "added column created_at (TIMESTAMP NULLABLE); removed column amount; column id changed type from INTEGER to STRING; column name changed from NULLABLE to REQUIRED",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drift := DiffSchemas(tt.destination, tt.source)
+
+			check := func(kind string, got, want []string) {
+				t.Helper()
+				if !slices.Equal(got, want) && (len(got) != 0 || len(want) != 0) {
+					t.Errorf("%s = %v, want %v", kind, got, want)
+				}
+			}
+			check("Added", ColumnNames(drift.Added), tt.added)
+			check("Removed", ColumnNames(drift.Removed), tt.removed)
+			check("Retyped", ChangedColumnNames(drift.Retyped), tt.retyped)
+			check("ModeChanged", ChangedColumnNames(drift.ModeChanged), tt.modeChanged)
+
+			wantEmpty := len(tt.added)+len(tt.removed)+len(tt.retyped)+len(tt.modeChanged) == 0
+			if got := drift.Empty(); got != wantEmpty {
+				t.Errorf("Empty() = %t, want %t", got, wantEmpty)
+			}
+			if got := drift.Additive(); got != tt.additive {
+				t.Errorf("Additive() = %t, want %t", got, tt.additive)
+			}
+			if got := drift.String(); got != tt.report {
+				t.Errorf("String() = %q, want %q", got, tt.report)
+			}
+		})
+	}
+}
@@ -43,15 +43,38 @@ type DynamicRow struct {
 	Values      []any
 }
 
+// Sync modes for a table.
+const (
+	SyncModeFull        = "full"        // Load every row on each run
+	SyncModeIncremental = "incremental" // Load only rows past the stored watermark
+)
+
+// Watermark stores for incremental sync state.
+const (
+	WatermarkStoreFile     = "file"     // JSON file on local disk
+	WatermarkStoreBigQuery = "bigquery" // State table in the target dataset
+)
+
+// Checkpoint stores for resuming interrupted runs.
+const (
+	CheckpointStoreFile     = "file"     // JSON file on local disk
+	CheckpointStoreBigQuery = "bigquery" // State table in the target dataset
+)
+
 // TableConfig holds configuration for a single table to sync.
 type TableConfig struct {
-	Name            string   // Source table name
-	TargetTable     string   // Target BigQuery table name (optional, defaults to source name)
-	PrimaryKey      string   // Primary key column for incremental sync
-	TimestampColumn string   // Column to track changes (e.g., updated_at)
-	Columns         []string // Specific columns to sync (empty means all columns)
-	BatchSize       int      // Number of rows per batch (0 = use default)
-	Enabled         bool     // Whether this table sync is enabled
+	Name             string   // Source table name
+	TargetTable      string   // Target BigQuery table name (optional, defaults to source name)
+	PrimaryKey       string   // Primary key column for incremental sync
+	TimestampColumn  string   // Column to track changes (e.g., updated_at)
+	Columns          []string // Specific columns to sync (empty means all columns)
+	BatchSize        int      // Number of rows per batch (0 = use default)
+	PagedReads       bool     // Read each batch with its own query, in PrimaryKey order
+	DedupeKeys       []string // Columns identifying duplicate rows within a batch (empty disables deduplication)
+	Enabled          bool     // Whether this table sync is enabled
+	SyncMode         string   // SyncModeFull or SyncModeIncremental
+	WatermarkColumn  string   // Column compared against the watermark in incremental mode
+	WatermarkInitial string   // Lower bound for the first incremental run (empty means full load)
 }
 
 // DatabaseConfig holds configuration for a single database source.
@@ -77,6 +100,8 @@ type Config struct {
 	SyncTimeout      time.Duration
 	DateFormat       string
 	DefaultBatchSize int
+	SyncConcurrency  int    // Maximum number of table jobs running at once
+	HealthAddr       string // Address serving /health and /metrics; empty disables them
 
 	MaxOpenConns    int
 	MaxIdleConns    int
@@ -86,6 +111,25 @@ type Config struct {
 	CreateTables        bool
 	TruncateOnSync      bool
 	MaxRowParseFailures int
+	SchemaDrift         string // SchemaDriftFail or SchemaDriftAddColumns
+
+	WatermarkStore      string // WatermarkStoreFile or WatermarkStoreBigQuery
+	WatermarkStateFile  string // Path of the state file for the file store
+	WatermarkStateTable string // BigQuery table holding state for the bigquery store
+
+	RetryMaxAttempts int           // Attempts per retried operation, including the first
+	RetryBaseDelay   time.Duration // Delay before the first retry, doubled for each later one
+	RetryMaxDelay    time.Duration // Upper bound for the delay between retries
+
+	CheckpointStore string // Empty to disable checkpoints, CheckpointStoreFile or CheckpointStoreBigQuery
+	CheckpointFile  string // Path of the checkpoint file for the file store
+	CheckpointTable string // BigQuery table holding checkpoints for the bigquery store
+}
+
+// BatchTransform rewrites a batch of parsed rows before it is loaded.
+type BatchTransform struct {
+	Name  string                          // Identifies the transform in logs
+	Apply func(batch []Savable) []Savable // Returns the rows to load; must not modify batch
 }
 
 // Job represents a sync job for a specific table.
@@ -101,7 +145,11 @@ type Job struct {
 	PrimaryKey       string
 	TimestampColumn  string
 	BatchSize        int
+	Args             []any // Query arguments, such as the incremental watermark bounds
 	ParseFunc        func(*sql.Rows, *zap.Logger) (Savable, error)
+	OnBatch          func(rows int64, last Savable) error      // Called after each batch is loaded, with its last row
+	Transforms       []BatchTransform                          // Applied in order to each batch before it is loaded
+	PageQuery        func(last Savable) (string, []any, error) // Returns the query and arguments reading the page after last; nil reads every row with Query
 }
 
 // SyncResult holds the result of a sync operation.
@@ -135,49 +183,6 @@ type DataSource struct {
 	SourceTables []string
 }
 
-// SchemasMatch validates equality of two BigQuery schemas by comparing field names, types, and required attributes.
-// Records debug and warning logs for field count differences, type mismatches, and required attribute changes.
-// Returns true only if no missing or mismatched fields are detected.
-func SchemasMatch(s1, s2 bigquery.Schema, logger *zap.Logger) bool {
-	logger.Debug("Comparing BigQuery schemas",
-		zap.Int("schema1_fields", len(s1)),
-		zap.Int("schema2_fields", len(s2)))
-
-	if len(s1) != len(s2) {
-		logger.Warn("Schema field count mismatch",
-			zap.Int("schema1_count", len(s1)),
-			zap.Int("schema2_count", len(s2)))
-		return false
-	}
-
-	// Store full field schema to compare Type and Required attributes
-	mapS1 := make(map[string]*bigquery.FieldSchema)
-	for _, field := range s1 {
-		mapS1[field.Name] = field
-	}
-
-	mismatches := []string{}
-	for _, field := range s2 {
-		s1Field, ok := mapS1[field.Name]
-		if !ok {
-			mismatches = append(mismatches, "missing:"+field.Name)
-		} else if s1Field.Type != field.Type {
-			mismatches = append(mismatches, "type:"+field.Name)
-		} else if s1Field.Required != field.Required {
-			mismatches = append(mismatches, "required:"+field.Name)
-		}
-	}
-
-	if len(mismatches) > 0 {
-		logger.Warn("Schema mismatches detected",
-			zap.Strings("mismatches", mismatches))
-		return false
-	}
-
-	logger.Debug("Schemas match")
-	return true
-}
-
 // ToSaveable converts a DynamicRow into a map representation using column names as keys.
 // Iterates through all columns and assigns corresponding values from the row.
 // Returns a generic map[string]any suitable for serialization or database storage.
@@ -236,6 +241,23 @@ func (t *TableConfig) GetBatchSize(defaultSize int) int {
 	return defaultSize
 }
 
+// IsIncremental reports whether the table syncs only rows past the stored watermark.
+func (t *TableConfig) IsIncremental() bool {
+	return t.SyncMode == SyncModeIncremental
+}
+
+// HasIncrementalTables reports whether any enabled table uses incremental sync.
+func (c *Config) HasIncrementalTables() bool {
+	for _, db := range c.GetEnabledDatabases() {
+		for _, tbl := range db.GetEnabledTables() {
+			if tbl.IsIncremental() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // CountEnabledTables returns the total number of enabled tables across all enabled databases.
 func (c *Config) CountEnabledTables() int {
 	count := 0
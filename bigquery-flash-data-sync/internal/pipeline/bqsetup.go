@@ -242,105 +242,95 @@ func InferSchemaFromPostgres(db *sql.DB, dbName string, query string, logger *za
 	return schema, nil
 }
 
-// createOrUpdateTable ensures that a target table in BigQuery exists and that its schema matches the provided schema.
-// If the table does not exist, it is created. If the schema differs, the table schema is updated.
-func createOrUpdateTable(ctx context.Context, client *bigquery.Client, datasetID string, table model.BQTable, logger *zap.Logger) error {
+// Table changes a sync makes to a BigQuery table, as reported by prepareTable.
+const (
+	tableChangeNone   = "none"
+	tableChangeCreate = "create"
+	tableChangeUpdate = "update_schema"
+)
+
+// prepareTable compares the schema of the target table with the one inferred
+// from the source before anything is loaded, and returns the change it made,
+// or in a dry run would make. A missing table is created when cfg.CreateTables
+// is set. Drift between the two schemas is logged with the affected columns and
+// fails the table unless cfg.SchemaDrift is model.SchemaDriftAddColumns and the
+// drift is only new NULLABLE columns, which are then added in place.
+func prepareTable(ctx context.Context, client *bigquery.Client, cfg *model.Config, table model.BQTable, logger *zap.Logger) (string, error) {
 	if err := validateBigQueryIdentifier(table.Name, "Target table name"); err != nil {
-		return err
+		return "", err
 	}
 
+	datasetID := cfg.BigQueryDatasetID
 	logger.Info("Checking BigQuery table",
 		zap.String("dataset", datasetID),
 		zap.String("table", table.Name))
 
 	tableRef := client.Dataset(datasetID).Table(table.Name)
 	metadata, err := tableRef.Metadata(ctx)
-
 	if err != nil {
-		if strings.Contains(err.Error(), "Not found") || strings.Contains(err.Error(), "notFound") {
-			logger.Info("Table not found, creating new table",
-				zap.String("dataset", datasetID),
-				zap.String("table", table.Name),
-				zap.Int("schema_fields", len(table.Schema)))
-
-			err = tableRef.Create(ctx, &bigquery.TableMetadata{
-				Name:   table.Name,
-				Schema: table.Schema,
-			})
-			if err != nil {
-				return fmt.Errorf("failed to create table '%s': %w", table.Name, err)
-			}
-
-			logger.Info("Table created successfully",
-				zap.String("dataset", datasetID),
-				zap.String("table", table.Name))
-			return nil
+		if !strings.Contains(err.Error(), "Not found") && !strings.Contains(err.Error(), "notFound") {
+			return "", fmt.Errorf("failed to get table metadata for '%s': %w", table.Name, err)
+		}
+		if !cfg.CreateTables {
+			return tableChangeNone, nil
+		}
+		if cfg.DryRun {
+			return tableChangeCreate, nil
 		}
-		return fmt.Errorf("failed to get table metadata for '%s': %w", table.Name, err)
-	}
 
-	// Table exists, check if schema matches
-	if !model.SchemasMatch(metadata.Schema, table.Schema, logger) {
-		logger.Warn("Schema mismatch detected, attempting update",
+		logger.Info("Table not found, creating new table",
 			zap.String("dataset", datasetID),
 			zap.String("table", table.Name),
-			zap.Int("existing_fields", len(metadata.Schema)),
-			zap.Int("new_fields", len(table.Schema)))
-
-		update := bigquery.TableMetadataToUpdate{Schema: table.Schema}
-		_, updateErr := tableRef.Update(ctx, update, metadata.ETag)
-
-		if updateErr != nil {
-			// Check for critical schema errors that require table recreation
-			isCriticalError := (strings.Contains(updateErr.Error(), "changed type") ||
-				strings.Contains(updateErr.Error(), "is missing") ||
-				strings.Contains(updateErr.Error(), "Precondition")) &&
-				(strings.Contains(updateErr.Error(), "invalid") ||
-					strings.Contains(updateErr.Error(), "cannot"))
-
-			if isCriticalError {
-				logger.Error("Critical schema error detected, recreating table",
-					zap.String("dataset", datasetID),
-					zap.String("table", table.Name),
-					zap.Error(updateErr))
-
-				logger.Warn("Recreating table will delete all existing data",
-					zap.String("dataset", datasetID),
-					zap.String("table", table.Name))
-
-				// Delete existing table
-				if delErr := tableRef.Delete(ctx); delErr != nil {
-					return fmt.Errorf("failed to delete table '%s' with bad schema: %w", table.Name, delErr)
-				}
-				logger.Info("Table deleted",
-					zap.String("dataset", datasetID),
-					zap.String("table", table.Name))
-
-				if createErr := tableRef.Create(ctx, &bigquery.TableMetadata{
-					Name:   table.Name,
-					Schema: table.Schema,
-				}); createErr != nil {
-					return fmt.Errorf("failed to recreate table '%s' with correct schema: %w", table.Name, createErr)
-				}
-
-				logger.Info("Table successfully recreated with corrected schema",
-					zap.String("dataset", datasetID),
-					zap.String("table", table.Name))
-				return nil
-			}
-
-			return fmt.Errorf("failed to update table schema for '%s': %w", table.Name, updateErr)
+			zap.Int("schema_fields", len(table.Schema)))
+
+		err = tableRef.Create(ctx, &bigquery.TableMetadata{
+			Name:   table.Name,
+			Schema: table.Schema,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to create table '%s': %w", table.Name, err)
 		}
 
-		logger.Info("Table schema updated successfully",
+		logger.Info("Table created successfully",
 			zap.String("dataset", datasetID),
 			zap.String("table", table.Name))
-	} else {
+		return tableChangeCreate, nil
+	}
+
+	drift := model.DiffSchemas(metadata.Schema, table.Schema)
+	if drift.Empty() {
 		logger.Debug("Table schema is up to date",
 			zap.String("dataset", datasetID),
 			zap.String("table", table.Name))
+		return tableChangeNone, nil
 	}
 
-	return nil
-}
+	logger.Warn("Schema drift detected",
+		zap.String("dataset", datasetID),
+		zap.String("table", table.Name),
+		zap.String("policy", cfg.SchemaDrift),
+		zap.Strings("added_columns", model.ColumnNames(drift.Added)),
+		zap.Strings("removed_columns", model.ColumnNames(drift.Removed)),
+		zap.Strings("retyped_columns", model.ChangedColumnNames(drift.Retyped)),
+		zap.Strings("mode_changed_columns", model.ChangedColumnNames(drift.ModeChanged)))
+
+	if cfg.SchemaDrift != model.SchemaDriftAddColumns || !drift.Additive() {
+		return "", fmt.Errorf("schema of table '%s' has drifted from its source (policy %s): %s", table.Name, cfg.SchemaDrift, drift)
+	}
+	if cfg.DryRun {
+		return tableChangeUpdate, nil
+	}
 
+	// Appending keeps the existing columns exactly as they are; BigQuery only
+	// accepts schema updates that add NULLABLE columns or relax existing ones.
+	schema := append(append(bigquery.Schema{}, metadata.Schema...), drift.Added...)
+	if _, err := tableRef.Update(ctx, bigquery.TableMetadataToUpdate{Schema: schema}, metadata.ETag); err != nil {
+		return "", fmt.Errorf("failed to add columns to table '%s': %w", table.Name, err)
+	}
+
+	logger.Info("Added new source columns to table",
+		zap.String("dataset", datasetID),
+		zap.String("table", table.Name),
+		zap.Strings("columns", model.ColumnNames(drift.Added)))
+	return tableChangeUpdate, nil
+}
@@ -0,0 +1,287 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/wso2-open-operations/common-tools/bigquery-flash-data-sync/internal/model"
+	"go.uber.org/zap"
+	"google.golang.org/api/iterator"
+)
+
+// Checkpoint records the progress of a sync run so an interrupted run can be
+// resumed. It is saved whole after every loaded batch.
+type Checkpoint struct {
+	RunID     string                      `json:"run_id"`
+	StartedAt time.Time                   `json:"started_at"`
+	UpdatedAt time.Time                   `json:"updated_at"`
+	Completed bool                        `json:"completed"`
+	Tables    map[string]*TableCheckpoint `json:"tables"`
+}
+
+// TableCheckpoint is the progress of one table within a run. Rows are loaded in
+// primary key order, so every row up to LastKey is already in BigQuery.
+type TableCheckpoint struct {
+	Window     *incrementalWindow `json:"window,omitempty"`
+	LastKey    *Watermark         `json:"last_key,omitempty"`
+	RowsLoaded int64              `json:"rows_loaded"`
+	Done       bool               `json:"done"`
+}
+
+// CheckpointStore persists the checkpoint of the latest run.
+type CheckpointStore interface {
+	// Load returns the latest saved checkpoint, or nil when there is none.
+	Load(ctx context.Context) (*Checkpoint, error)
+	// Save replaces the latest checkpoint.
+	Save(ctx context.Context, cp *Checkpoint) error
+}
+
+// newCheckpointStore returns the store selected by cfg.CheckpointStore, or nil
+// when checkpointing is disabled.
+func newCheckpointStore(ctx context.Context, cfg *model.Config, client *bigquery.Client, logger *zap.Logger) (CheckpointStore, error) {
+	switch cfg.CheckpointStore {
+	case model.CheckpointStoreBigQuery:
+		table, exists, err := ensureStateTable(ctx, client, cfg.BigQueryDatasetID, cfg.CheckpointTable, checkpointStateSchema, cfg.DryRun, logger)
+		if err != nil {
+			return nil, fmt.Errorf("checkpoint table: %w", err)
+		}
+		if !exists {
+			return emptyCheckpointStore{}, nil
+		}
+		logger.Info("Using checkpoint table",
+			zap.String("dataset", cfg.BigQueryDatasetID),
+			zap.String("table", cfg.CheckpointTable))
+		return &bigQueryCheckpointStore{client: client, table: table, dataset: cfg.BigQueryDatasetID}, nil
+	case model.CheckpointStoreFile:
+		logger.Info("Using checkpoint file", zap.String("path", cfg.CheckpointFile))
+		return &fileCheckpointStore{path: cfg.CheckpointFile}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// fileCheckpointStore keeps the checkpoint in a JSON file that is replaced
+// atomically on every save.
+type fileCheckpointStore struct {
+	path string
+}
+
+func (s *fileCheckpointStore) Load(context.Context) (*Checkpoint, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint file: %w", err)
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint file %s: %w", s.path, err)
+	}
+	return &cp, nil
+}
+
+func (s *fileCheckpointStore) Save(_ context.Context, cp *Checkpoint) error {
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+	if err := writeFileAtomic(s.path, append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write checkpoint file: %w", err)
+	}
+	return nil
+}
+
+// emptyCheckpointStore holds no checkpoint and discards saves.
+type emptyCheckpointStore struct{}
+
+func (emptyCheckpointStore) Load(context.Context) (*Checkpoint, error) { return nil, nil }
+func (emptyCheckpointStore) Save(context.Context, *Checkpoint) error   { return nil }
+
+// checkpointStateSchema is the schema of the BigQuery checkpoint table.
+var checkpointStateSchema = bigquery.Schema{
+	{Name: "run_id", Type: bigquery.StringFieldType, Required: true},
+	{Name: "state", Type: bigquery.StringFieldType, Required: true},
+	{Name: "updated_at", Type: bigquery.TimestampFieldType, Required: true},
+}
+
+// bigQueryCheckpointStore appends every saved checkpoint as a row holding its
+// JSON encoding and loads the newest one. A streaming insert either lands whole
+// or not at all, so a crash never leaves a partial checkpoint.
+type bigQueryCheckpointStore struct {
+	client  *bigquery.Client
+	table   *bigquery.Table
+	dataset string
+}
+
+func (s *bigQueryCheckpointStore) Load(ctx context.Context) (*Checkpoint, error) {
+	q := s.client.Query(fmt.Sprintf(
+		"SELECT state FROM `%s.%s` ORDER BY updated_at DESC LIMIT 1",
+		s.dataset, s.table.TableID,
+	))
+	it, err := q.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query checkpoint table: %w", err)
+	}
+
+	var row struct {
+		State string `bigquery:"state"`
+	}
+	if err := it.Next(&row); err != nil {
+		if errors.Is(err, iterator.Done) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal([]byte(row.State), &cp); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint: %w", err)
+	}
+	return &cp, nil
+}
+
+func (s *bigQueryCheckpointStore) Save(ctx context.Context, cp *Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+	// Values follow the column order of checkpointStateSchema.
+	row := &bigquery.ValuesSaver{
+		Schema: checkpointStateSchema,
+		Row:    []bigquery.Value{cp.RunID, string(data), cp.UpdatedAt},
+	}
+	if err := s.table.Inserter().Put(ctx, row); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return nil
+}
+
+// checkpointer holds the checkpoint of the current run and saves it as table
+// jobs report progress. Table jobs run concurrently, so updates are serialized.
+// In a dry run nothing is saved.
+type checkpointer struct {
+	store  CheckpointStore
+	dryRun bool
+
+	mu sync.Mutex
+	cp *Checkpoint
+}
+
+// newCheckpointer loads the latest checkpoint from store. An unfinished one is
+// resumed; otherwise a new run is started.
+func newCheckpointer(ctx context.Context, store CheckpointStore, dryRun bool, logger *zap.Logger) (*checkpointer, error) {
+	cp, err := store.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if cp != nil && !cp.Completed {
+		done := 0
+		for _, tc := range cp.Tables {
+			if tc.Done {
+				done++
+			}
+		}
+		logger.Info("Resuming interrupted sync run from checkpoint",
+			zap.String("run_id", cp.RunID),
+			zap.Time("started_at", cp.StartedAt),
+			zap.Time("checkpoint_updated_at", cp.UpdatedAt),
+			zap.Int("tables_done", done),
+			zap.Int("tables_started", len(cp.Tables)),
+		)
+		if cp.Tables == nil {
+			cp.Tables = make(map[string]*TableCheckpoint)
+		}
+		return &checkpointer{store: store, dryRun: dryRun, cp: cp}, nil
+	}
+
+	now := time.Now().UTC()
+	cp = &Checkpoint{
+		RunID:     now.Format("20060102T150405.000000000Z"),
+		StartedAt: now,
+		UpdatedAt: now,
+		Tables:    make(map[string]*TableCheckpoint),
+	}
+	logger.Info("Starting new checkpointed sync run", zap.String("run_id", cp.RunID))
+	return &checkpointer{store: store, dryRun: dryRun, cp: cp}, nil
+}
+
+// table returns a copy of the saved progress of a table, or nil when the run
+// has not reached it. It is safe to call on a nil checkpointer.
+func (c *checkpointer) table(database, table string) *TableCheckpoint {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tc, ok := c.cp.Tables[watermarkKey(database, table)]
+	if !ok {
+		return nil
+	}
+	out := *tc
+	return &out
+}
+
+// update applies fn to the progress of a table and saves the checkpoint. It is
+// a no-op on a nil checkpointer.
+func (c *checkpointer) update(ctx context.Context, database, table string, fn func(tc *TableCheckpoint)) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := watermarkKey(database, table)
+	tc, ok := c.cp.Tables[key]
+	if !ok {
+		tc = &TableCheckpoint{}
+		c.cp.Tables[key] = tc
+	}
+	fn(tc)
+	return c.save(ctx)
+}
+
+// complete marks the run as finished, so the next run starts afresh. It is a
+// no-op on a nil checkpointer.
+func (c *checkpointer) complete(ctx context.Context) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.cp.Completed = true
+	return c.save(ctx)
+}
+
+// save writes the checkpoint to the store. The caller holds c.mu.
+func (c *checkpointer) save(ctx context.Context) error {
+	if c.dryRun {
+		return nil
+	}
+	c.cp.UpdatedAt = time.Now().UTC()
+	return c.store.Save(ctx, c.cp)
+}
@@ -24,16 +24,16 @@ import (
     "context"
     "database/sql"
     "encoding/json"
+    "errors"
     "fmt"
     "regexp"
+    "slices"
     "strings"
-    "sync"
     "time"
 
     "cloud.google.com/go/bigquery"
     "github.com/wso2-open-operations/common-tools/bigquery-flash-data-sync/internal/model"
     "go.uber.org/zap"
-    "golang.org/x/sync/errgroup"
 )
 
 // DBDriver defines the function signature for getting the SQL driver name.
@@ -55,10 +55,12 @@ func getDBDriver(dbType string) string {
     return driverName
 }
 
-// Start initializes the BigQuery client and orchestrates multiple concurrent ETL jobs.
+// Start initializes the BigQuery client and orchestrates multiple concurrent ETL jobs,
+// running at most SYNC_CONCURRENCY table jobs at a time. It returns the summary of the
+// run, which is nil if no table job was started, and an error if any table failed.
 // NOTE: We intentionally do NOT cancel all jobs on first failure, to avoid "context canceled"
 // hiding the real errors from other tables.
-func Start(ctx context.Context, cfg *model.Config, logger *zap.Logger) error {
+func Start(ctx context.Context, cfg *model.Config, logger *zap.Logger) (*model.SyncSummary, error) {
     logger.Info("Initializing BigQuery client",
         zap.String("project_id", cfg.GCPProjectID),
         zap.String("dataset_id", cfg.BigQueryDatasetID),
@@ -66,7 +68,7 @@ func Start(ctx context.Context, cfg *model.Config, logger *zap.Logger) error {
 
     bqClient, err := bigquery.NewClient(ctx, cfg.GCPProjectID)
     if err != nil {
-        return fmt.Errorf("failed to create BigQuery client: %w", err)
+        return nil, fmt.Errorf("failed to create BigQuery client: %w", err)
     }
     defer bqClient.Close()
 
@@ -75,33 +77,46 @@ func Start(ctx context.Context, cfg *model.Config, logger *zap.Logger) error {
 
     if len(enabledDatabases) == 0 {
         logger.Warn("No enabled databases found in configuration")
-        return nil
+        return nil, nil
     }
 
     logger.Info("Starting sync pipeline",
         zap.Int("enabled_databases", len(enabledDatabases)),
         zap.Int("total_tables", totalTables),
+        zap.Int("sync_concurrency", cfg.SyncConcurrency),
         zap.Bool("dry_run", cfg.DryRun),
     )
 
+    var store WatermarkStore
+    if cfg.HasIncrementalTables() {
+        store, err = newWatermarkStore(ctx, cfg, bqClient, logger)
+        if err != nil {
+            return nil, fmt.Errorf("failed to set up watermark store: %w", err)
+        }
+    }
+
+    var checkpoints *checkpointer
+    checkpointStore, err := newCheckpointStore(ctx, cfg, bqClient, logger)
+    if err != nil {
+        return nil, fmt.Errorf("failed to set up checkpoint store: %w", err)
+    }
+    if checkpointStore != nil {
+        checkpoints, err = newCheckpointer(ctx, checkpointStore, cfg.DryRun, logger)
+        if err != nil {
+            return nil, fmt.Errorf("failed to load checkpoint: %w", err)
+        }
+    }
+
+    startedAt := time.Now()
     summary := &model.SyncSummary{
         TotalDatabases: len(enabledDatabases),
         TotalTables:    totalTables,
         Results:        make([]*model.SyncResult, 0, totalTables),
     }
 
-    // Use mutex to protect concurrent access to summary counters
-    var mu sync.Mutex
-    resultsChan := make(chan *model.SyncResult, totalTables)
-
-    var g errgroup.Group
-
-    for _, dbConfig := range enabledDatabases {
-        db := dbConfig
-
-        for _, tableConfig := range db.GetEnabledTables() {
-            tbl := tableConfig
-
+    units := make([]syncUnit, 0, totalTables)
+    for _, db := range enabledDatabases {
+        for _, tbl := range db.GetEnabledTables() {
             rawTarget := tbl.GetTargetTableName()
             safeTarget, terr := bigQueryTableID(rawTarget)
             if terr != nil {
@@ -109,52 +124,54 @@ func Start(ctx context.Context, cfg *model.Config, logger *zap.Logger) error {
                 safeTarget = rawTarget
             }
 
-            jobLogger := logger.With(
-                zap.String("database", db.Name),
-                zap.String("source_table", tbl.Name),
-                zap.String("target_table", safeTarget),
-            )
-
-            g.Go(func() error {
-                // Use the original ctx (no group-cancel context) so one failing table
-                // doesn't cancel all other in-flight table jobs.
-                result := runTableJob(ctx, bqClient, cfg, db, tbl, jobLogger)
-
-                resultsChan <- result
-
-                if result.Error != nil {
-                    mu.Lock()
-                    summary.FailedSyncs++
-                    mu.Unlock()
-                    return fmt.Errorf("failed sync at %s.%s: %w", db.Name, tbl.Name, result.Error)
-                }
-
-                mu.Lock()
-                summary.SuccessfulSyncs++
-                summary.TotalRowsSynced += result.RowsSynced
-                mu.Unlock()
-                return nil
+            units = append(units, syncUnit{
+                db:    db,
+                table: tbl,
+                logger: logger.With(
+                    zap.String("database", db.Name),
+                    zap.String("source_table", tbl.Name),
+                    zap.String("target_table", safeTarget),
+                ),
             })
         }
     }
 
-    err = g.Wait()
-    close(resultsChan)
+    // Every unit runs with the original ctx, so one failing table doesn't cancel
+    // the other in-flight table jobs.
+    pool := newWorkerPool(cfg.SyncConcurrency)
+    results := pool.run(ctx, units, func(ctx context.Context, u syncUnit) *model.SyncResult {
+        return runTableJob(ctx, bqClient, store, checkpoints, cfg, u.db, u.table, u.logger)
+    })
 
-    for result := range resultsChan {
+    var failures []error
+    for _, result := range results {
         summary.Results = append(summary.Results, result)
+        if result.Error != nil {
+            summary.FailedSyncs++
+            failures = append(failures, fmt.Errorf("failed sync at %s.%s: %w", result.DatabaseName, result.TableName, result.Error))
+            continue
+        }
+        summary.SuccessfulSyncs++
+        summary.TotalRowsSynced += result.RowsSynced
     }
+    summary.TotalDuration = time.Since(startedAt)
 
-    if err != nil {
+    logSyncSummary(logger, summary)
+
+    if len(failures) > 0 {
+        err := fmt.Errorf("%d of %d table syncs failed: %w", len(failures), len(results), errors.Join(failures...))
         logger.Error("One or more sync jobs failed",
             zap.Error(err),
             zap.Int("successful", summary.SuccessfulSyncs),
             zap.Int("failed", summary.FailedSyncs),
         )
-        return err
+        return summary, err
     }
 
-    logSyncSummary(logger, summary)
+    // Only a fully successful run clears the checkpoint, so a failed one resumes.
+    if err := checkpoints.complete(ctx); err != nil {
+        return summary, fmt.Errorf("failed to complete checkpoint: %w", err)
+    }
 
     logger.Info("All sync jobs completed successfully",
         zap.Int("databases", summary.TotalDatabases),
@@ -162,12 +179,16 @@ func Start(ctx context.Context, cfg *model.Config, logger *zap.Logger) error {
         zap.Int64("total_rows", summary.TotalRowsSynced),
     )
 
-    return nil
+    return summary, nil
 }
 
 // runTableJob handles the ETL process for a single table, including schema inference,
-// BigQuery table creation/update, data extraction, and load.
-func runTableJob(ctx context.Context, bqClient *bigquery.Client, cfg *model.Config, dbConfig *model.DatabaseConfig, tableConfig *model.TableConfig, logger *zap.Logger) *model.SyncResult {
+// BigQuery table creation/update, data extraction, and load. Incremental tables load
+// only the rows past their stored watermark, which advances once the load succeeds.
+// With checkpoints enabled, rows load in primary key order and progress is saved after
+// each batch, so a table finished by an interrupted run is skipped and a partly loaded
+// one resumes after its last loaded key.
+func runTableJob(ctx context.Context, bqClient *bigquery.Client, store WatermarkStore, checkpoints *checkpointer, cfg *model.Config, dbConfig *model.DatabaseConfig, tableConfig *model.TableConfig, logger *zap.Logger) *model.SyncResult {
     startedAt := time.Now()
 
     rawTarget := tableConfig.GetTargetTableName()
@@ -193,7 +214,7 @@ func runTableJob(ctx context.Context, bqClient *bigquery.Client, cfg *model.Conf
 
     finishErr := func(publicMsg string, err error) *model.SyncResult {
         if err == nil {
-            err = fmt.Errorf(publicMsg)
+            err = fmt.Errorf("%s", publicMsg)
         } else if publicMsg != "" {
             err = fmt.Errorf("%s: %w", publicMsg, err)
         }
@@ -215,6 +236,14 @@ func runTableJob(ctx context.Context, bqClient *bigquery.Client, cfg *model.Conf
         return result
     }
 
+    progress := checkpoints.table(dbConfig.Name, tableConfig.Name)
+    if progress != nil && progress.Done {
+        logger.Info("Skipping table completed by the interrupted run",
+            zap.Int64("rows_loaded", progress.RowsLoaded),
+        )
+        return finishOK()
+    }
+
     logger.Info("Starting table sync job")
 
     sourceQuery, err := buildSourceQuery(dbConfig, tableConfig)
@@ -242,19 +271,112 @@ func runTableJob(ctx context.Context, bqClient *bigquery.Client, cfg *model.Conf
         zap.Int("columns", len(inferredSchema)),
     )
 
+    bqTable := model.BQTable{Name: targetTableName, Schema: inferredSchema}
+
+    change, err := prepareTable(ctx, bqClient, cfg, bqTable, logger)
+    if err != nil {
+        return finishErr("BigQuery schema check failed", err)
+    }
     if cfg.DryRun {
-        logger.Info("Dry run mode - skipping BigQuery operations")
-        return finishOK()
+        logger.Info("Dry run: planned table change",
+            zap.String("operation", change),
+            zap.String("dataset", cfg.BigQueryDatasetID),
+        )
     }
 
-    bqTable := model.BQTable{Name: targetTableName, Schema: inferredSchema}
+    filter := newSourceFilter(dbConfig.Type)
+    truncate := cfg.TruncateOnSync
+    var window *incrementalWindow
+    if tableConfig.IsIncremental() {
+        if progress != nil && progress.Window != nil {
+            window = progress.Window
+            logger.Info("Resuming incremental window from checkpoint",
+                zap.String("upper_watermark", window.Upper.Value),
+            )
+        } else {
+            window, err = planIncrementalWindow(ctx, db, store, newRetryPolicy(cfg), dbConfig, tableConfig, sourceQuery, logger)
+            if err != nil {
+                return finishErr("Incremental sync planning failed", err)
+            }
+        }
+        if window == nil {
+            logger.Info("No rows past the stored watermark, nothing to sync")
+            if err := checkpoints.update(ctx, dbConfig.Name, tableConfig.Name, func(tc *TableCheckpoint) { tc.Done = true }); err != nil {
+                return finishErr("Failed to save checkpoint", err)
+            }
+            return finishOK()
+        }
+        if err := window.apply(filter); err != nil {
+            return finishErr("Incremental sync planning failed", err)
+        }
+        // Truncating would discard the rows loaded by earlier runs.
+        truncate = truncate && window.fullLoad()
+    }
 
-    if cfg.CreateTables {
-        if err := createOrUpdateTable(ctx, bqClient, cfg.BigQueryDatasetID, bqTable, logger); err != nil {
-            return finishErr("BigQuery table creation failed", err)
+    // Checkpoints and paged reads both continue after the last primary key
+    // loaded, so rows are read in primary key order.
+    orderBy := ""
+    if checkpoints != nil || tableConfig.PagedReads {
+        orderBy = tableConfig.PrimaryKey
+        if err := validateSQLIdentifier(orderBy); err != nil {
+            return finishErr("Invalid primary key for checkpointing or paged reads", err)
+        }
+        if len(tableConfig.Columns) > 0 && !slices.Contains(tableConfig.Columns, orderBy) {
+            return finishErr("Invalid column list for checkpointing or paged reads", fmt.Errorf("columns must include the primary key '%s'", orderBy))
+        }
+        if progress != nil && progress.LastKey != nil {
+            lastKey, err := progress.LastKey.queryArg()
+            if err != nil {
+                return finishErr("Invalid checkpoint", err)
+            }
+            filter.where(orderBy, ">", lastKey)
+            // Earlier batches of this table are already loaded.
+            truncate = false
+            logger.Info("Resuming table from checkpoint",
+                zap.String("last_key", progress.LastKey.Value),
+                zap.Int64("rows_loaded", progress.RowsLoaded),
+            )
         }
     }
 
+    batchSize := tableConfig.GetBatchSize(cfg.DefaultBatchSize)
+    jobQuery, jobArgs := filter.apply(sourceQuery), filter.args
+    if orderBy != "" {
+        jobQuery += " ORDER BY " + orderBy
+    }
+    logger.Debug("Planned source query",
+        zap.String("source_query", jobQuery),
+        zap.Bool("truncate", truncate),
+    )
+
+    if cfg.DryRun {
+        rowCount, err := countSourceRows(ctx, db, cfg, jobQuery, jobArgs, logger)
+        if err != nil {
+            return finishErr("Failed to count source rows", err)
+        }
+        writeDisposition := "append"
+        if truncate && rowCount > 0 {
+            writeDisposition = "truncate"
+        }
+        fields := []zap.Field{
+            zap.Int64("rows", rowCount),
+            zap.Int64("load_jobs", (rowCount+int64(batchSize)-1)/int64(batchSize)),
+            zap.String("write_disposition", writeDisposition),
+        }
+        if window != nil {
+            fields = append(fields, zap.String("next_watermark", window.Upper.Value))
+        }
+        if progress != nil && progress.LastKey != nil {
+            fields = append(fields, zap.String("resume_after_key", progress.LastKey.Value))
+        }
+        logger.Info("Dry run: planned load", fields...)
+        return finishOK()
+    }
+
+    if tableConfig.PagedReads {
+        jobQuery += fmt.Sprintf(" LIMIT %d", batchSize)
+    }
+
     job := model.Job{
         Name:             tableConfig.Name,
         DatabaseName:     dbConfig.Name,
@@ -262,21 +384,85 @@ func runTableJob(ctx context.Context, bqClient *bigquery.Client, cfg *model.Conf
         ConnectionString: dbConfig.ConnectionString,
         SourceTable:      tableConfig.Name,
         TargetTable:      targetTableName,
-        Query:            sourceQuery,
+        Query:            jobQuery,
+        Args:             jobArgs,
         Columns:          tableConfig.Columns,
         PrimaryKey:       tableConfig.PrimaryKey,
         TimestampColumn:  tableConfig.TimestampColumn,
-        BatchSize:        tableConfig.GetBatchSize(cfg.DefaultBatchSize),
+        BatchSize:        batchSize,
         ParseFunc: func(rows *sql.Rows, logger *zap.Logger) (model.Savable, error) {
             return model.ParseDynamicRow(rows, logger, cfg.DateFormat)
         },
     }
 
-    rowsSynced, err := executeJob(ctx, bqClient, cfg, job, db, logger)
+    if len(tableConfig.DedupeKeys) > 0 {
+        for _, key := range tableConfig.DedupeKeys {
+            if err := validateSQLIdentifier(key); err != nil {
+                return finishErr("Invalid dedupe key", err)
+            }
+            // Rows are keyed by the scanned column names, so a key that is not
+            // one of them would be NULL for every row and collapse each batch
+            // to a single row.
+            if !slices.ContainsFunc(inferredSchema, func(f *bigquery.FieldSchema) bool { return f.Name == key }) {
+                return finishErr("Invalid dedupe key", fmt.Errorf("dedupe key '%s' is not a column of the source query", key))
+            }
+        }
+        job.Transforms = append(job.Transforms, newDedupeTransform(tableConfig.DedupeKeys, tableConfig.WatermarkColumn))
+        logger.Info("Deduplicating rows within each batch",
+            zap.Strings("dedupe_keys", tableConfig.DedupeKeys),
+            zap.String("order_column", tableConfig.WatermarkColumn),
+        )
+    }
+
+    if tableConfig.PagedReads {
+        job.PageQuery = func(last model.Savable) (string, []any, error) {
+            value, ok := last.ToSaveable()[orderBy]
+            if !ok || value == nil {
+                return "", nil, fmt.Errorf("primary key '%s' is missing from the last row read", orderBy)
+            }
+            key, err := newWatermark(orderBy, value).queryArg()
+            if err != nil {
+                return "", nil, err
+            }
+            page := filter.clone()
+            page.where(orderBy, ">", key)
+            return fmt.Sprintf("%s ORDER BY %s LIMIT %d", page.apply(sourceQuery), orderBy, batchSize), page.args, nil
+        }
+    }
+
+    if checkpoints != nil {
+        job.OnBatch = func(rows int64, last model.Savable) error {
+            return checkpoints.update(ctx, dbConfig.Name, tableConfig.Name, func(tc *TableCheckpoint) {
+                tc.Window = window
+                tc.RowsLoaded += rows
+                if key, ok := last.ToSaveable()[orderBy]; ok {
+                    wm := newWatermark(orderBy, key)
+                    tc.LastKey = &wm
+                }
+            })
+        }
+    }
+
+    rowsSynced, err := executeJob(ctx, bqClient, cfg, job, db, truncate, logger)
     if err != nil {
         return finishErr("Job execution failed", err)
     }
 
+    if window != nil {
+        window.Upper.UpdatedAt = time.Now().UTC()
+        if err := store.Save(ctx, dbConfig.Name, tableConfig.Name, window.Upper); err != nil {
+            return finishErr("Failed to save watermark", err)
+        }
+        logger.Info("Watermark advanced",
+            zap.String("watermark_column", window.Upper.Column),
+            zap.String("watermark", window.Upper.Value),
+        )
+    }
+
+    if err := checkpoints.update(ctx, dbConfig.Name, tableConfig.Name, func(tc *TableCheckpoint) { tc.Done = true }); err != nil {
+        return finishErr("Failed to save checkpoint", err)
+    }
+
     result.RowsSynced = rowsSynced
     finishOK()
 
@@ -455,15 +641,17 @@ func openDatabaseConnection(ctx context.Context, dbConfig *model.DatabaseConfig,
         db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
     }
 
-    // Apply timeout to ping operation
-    pingCtx := ctx
-    if cfg.SyncTimeout > 0 {
-        var cancel context.CancelFunc
-        pingCtx, cancel = context.WithTimeout(ctx, cfg.SyncTimeout)
-        defer cancel()
-    }
-
-    if err := db.PingContext(pingCtx); err != nil {
+    err = newRetryPolicy(cfg).do(ctx, logger, "database ping", func(ctx context.Context) error {
+        // Apply timeout to ping operation
+        pingCtx := ctx
+        if cfg.SyncTimeout > 0 {
+            var cancel context.CancelFunc
+            pingCtx, cancel = context.WithTimeout(ctx, cfg.SyncTimeout)
+            defer cancel()
+        }
+        return db.PingContext(pingCtx)
+    })
+    if err != nil {
         db.Close()
         return nil, fmt.Errorf("failed to ping database: %w", err)
     }
@@ -472,22 +660,34 @@ func openDatabaseConnection(ctx context.Context, dbConfig *model.DatabaseConfig,
     return db, nil
 }
 
+// countSourceRows returns the number of rows query would extract.
+func countSourceRows(ctx context.Context, db *sql.DB, cfg *model.Config, query string, args []any, logger *zap.Logger) (int64, error) {
+    var count int64
+    err := newRetryPolicy(cfg).do(ctx, logger, "source row count", func(ctx context.Context) error {
+        return db.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM (%s) AS src", query), args...).Scan(&count)
+    })
+    if err != nil {
+        return 0, fmt.Errorf("failed to count source rows: %w", err)
+    }
+    return count, nil
+}
+
 // executeJob runs a full extract-and-load process by querying the source database, buffering results in memory,
-// and uploading the extracted JSON data to BigQuery using a load job.
+// and uploading the extracted JSON data to BigQuery using a load job for every job.BatchSize rows.
+// When job.PageQuery is set, rows are read in pages of job.BatchSize rows, each with its own query, instead of
+// through a single query that stays open for the whole table.
+// When truncate is set, the first load job replaces the table contents.
 // Returns the number of rows synced and an error if any stage fails.
-func executeJob(ctx context.Context, bqClient *bigquery.Client, cfg *model.Config, job model.Job, db *sql.DB, logger *zap.Logger) (int64, error) {
+func executeJob(ctx context.Context, bqClient *bigquery.Client, cfg *model.Config, job model.Job, db *sql.DB, truncate bool, logger *zap.Logger) (int64, error) {
     if db == nil {
         return 0, fmt.Errorf("database connection is nil")
     }
 
-    logger.Info("Executing source query", zap.String("job_name", job.Name))
-
-    rows, err := db.QueryContext(ctx, job.Query)
-    if err != nil {
-        logger.Error("Failed to query database", zap.Error(err))
-        return 0, fmt.Errorf("failed to query database: %w", err)
-    }
-    defer rows.Close()
+    logger.Info("Executing source query",
+        zap.String("job_name", job.Name),
+        zap.Int("batch_size", job.BatchSize),
+        zap.Bool("paged_reads", job.PageQuery != nil),
+    )
 
     maxRowsPerBatch := job.BatchSize
     maxRowParseFailures := cfg.MaxRowParseFailures
@@ -499,74 +699,140 @@ func executeJob(ctx context.Context, bqClient *bigquery.Client, cfg *model.Confi
     var totalRowsExtracted int64
     var skippedRows int
     var lastParseError error
+    batchNum := 0
     rowNum := 0
+    removedByTransform := make([]int, len(job.Transforms))
+
+    // loadBatch passes the buffered rows through the job's transforms, uploads
+    // what is left as one load job and logs the progress.
+    loadBatch := func() error {
+        load := batch
+        for i, t := range job.Transforms {
+            before := len(load)
+            load = t.Apply(load)
+            removedByTransform[i] += before - len(load)
+        }
 
-    for rows.Next() {
-        rowNum++
-        rowData, err := job.ParseFunc(rows, logger)
-        if err != nil {
-            logger.Error("Failed to parse row", zap.Int("row_number", rowNum), zap.Error(err))
-            skippedRows++
-            lastParseError = err
-
-            // A negative value (-1) means unlimited failures are allowed
-            if maxRowParseFailures >= 0 && skippedRows > maxRowParseFailures {
-                logger.Error("Exceeded maximum row parse failures, aborting sync",
-                    zap.Int("max_failures_allowed", maxRowParseFailures),
-                    zap.Int("total_failures", skippedRows),
-                    zap.Int("rows_processed", rowNum),
-                    zap.Int64("rows_successfully_extracted", totalRowsExtracted),
-                    zap.Error(lastParseError),
-                )
-                return totalRowsExtracted, fmt.Errorf("exceeded maximum row parse failures (%d/%d), last error: %w",
-                    skippedRows, maxRowParseFailures, lastParseError)
+        batchNum++
+        if len(load) > 0 {
+            for _, r := range load {
+                if err := encoder.Encode(r.ToSaveable()); err != nil {
+                    return fmt.Errorf("failed to encode batch: %w", err)
+                }
             }
-            continue
+            if err := uploadBufferToBigQuery(ctx, bqClient, cfg, job.TargetTable, &buf, truncate && totalRowsExtracted == 0, logger); err != nil {
+                return err
+            }
+        }
+        if err := notifyBatch(job, len(load), batch); err != nil {
+            return err
         }
 
-        batch = append(batch, rowData)
+        totalRowsExtracted += int64(len(load))
+        logger.Info("Loaded batch",
+            zap.Int("batch", batchNum),
+            zap.Int("rows", len(load)),
+            zap.Int("rows_removed", len(batch)-len(load)),
+            zap.Int64("rows_loaded", totalRowsExtracted),
+        )
+        buf.Reset()
+        batch = batch[:0]
+        return nil
+    }
 
-        if len(batch) >= maxRowsPerBatch {
-            for _, r := range batch {
-                if err := encoder.Encode(r.ToSaveable()); err != nil {
-                    return 0, fmt.Errorf("failed to encode batch: %w", err)
+    query, args := job.Query, job.Args
+    for page := 1; ; page++ {
+        // Only opening a query is retried. Once rows are streaming, batches may
+        // already be loaded, so a retry would duplicate them.
+        var rows *sql.Rows
+        err := newRetryPolicy(cfg).do(ctx, logger, "source query", func(ctx context.Context) error {
+            var qerr error
+            rows, qerr = db.QueryContext(ctx, query, args...)
+            return qerr
+        })
+        if err != nil {
+            logger.Error("Failed to query database", zap.Int("page", page), zap.Error(err))
+            return 0, fmt.Errorf("failed to query database: %w", err)
+        }
+
+        pageRows := 0
+        var lastRow model.Savable
+        for rows.Next() {
+            rowNum++
+            pageRows++
+            rowData, err := job.ParseFunc(rows, logger)
+            if err != nil {
+                logger.Error("Failed to parse row", zap.Int("row_number", rowNum), zap.Error(err))
+                skippedRows++
+                lastParseError = err
+
+                // A negative value (-1) means unlimited failures are allowed
+                if maxRowParseFailures >= 0 && skippedRows > maxRowParseFailures {
+                    rows.Close()
+                    logger.Error("Exceeded maximum row parse failures, aborting sync",
+                        zap.Int("max_failures_allowed", maxRowParseFailures),
+                        zap.Int("total_failures", skippedRows),
+                        zap.Int("rows_processed", rowNum),
+                        zap.Int64("rows_successfully_extracted", totalRowsExtracted),
+                        zap.Error(lastParseError),
+                    )
+                    return totalRowsExtracted, fmt.Errorf("exceeded maximum row parse failures (%d/%d), last error: %w",
+                        skippedRows, maxRowParseFailures, lastParseError)
                 }
+                continue
             }
 
-            if err := uploadBufferToBigQuery(ctx, bqClient, cfg, job.TargetTable, &buf, cfg.TruncateOnSync && totalRowsExtracted == 0); err != nil {
-                return 0, err
+            batch = append(batch, rowData)
+            lastRow = rowData
+
+            if len(batch) >= maxRowsPerBatch {
+                if err := loadBatch(); err != nil {
+                    rows.Close()
+                    return 0, err
+                }
             }
+        }
+        iterErr := rows.Err()
+        rows.Close()
+        if iterErr != nil {
+            logger.Error("Error during row iteration", zap.Error(iterErr))
+            return 0, fmt.Errorf("error during row iteration: %w", iterErr)
+        }
 
-            totalRowsExtracted += int64(len(batch))
-            buf.Reset()
-            batch = batch[:0]
+        // A short page is the last one.
+        if job.PageQuery == nil || pageRows < maxRowsPerBatch {
+            break
+        }
+        if lastRow == nil {
+            return 0, fmt.Errorf("no row of page %d could be parsed to continue paging after", page)
+        }
+        query, args, err = job.PageQuery(lastRow)
+        if err != nil {
+            return 0, fmt.Errorf("failed to build query for page %d: %w", page+1, err)
         }
     }
 
     // Upload any remaining rows
     if len(batch) > 0 {
-        for _, r := range batch {
-            if err := encoder.Encode(r.ToSaveable()); err != nil {
-                return 0, fmt.Errorf("failed to encode batch: %w", err)
-            }
-        }
-        if err := uploadBufferToBigQuery(ctx, bqClient, cfg, job.TargetTable, &buf, cfg.TruncateOnSync && totalRowsExtracted == 0); err != nil {
+        if err := loadBatch(); err != nil {
             return 0, err
         }
-        totalRowsExtracted += int64(len(batch))
-    }
-
-    if err := rows.Err(); err != nil {
-        logger.Error("Error during row iteration", zap.Error(err))
-        return 0, fmt.Errorf("error during row iteration: %w", err)
     }
 
     logger.Info("Extraction complete",
         zap.Int("total_rows_processed", rowNum),
         zap.Int64("rows_extracted", totalRowsExtracted),
         zap.Int("rows_skipped", skippedRows),
+        zap.Int("batches", batchNum),
     )
 
+    for i, t := range job.Transforms {
+        logger.Info("Rows removed by batch transform",
+            zap.String("transform", t.Name),
+            zap.Int("rows_removed", removedByTransform[i]),
+        )
+    }
+
     if skippedRows > 0 {
         logger.Warn("Some rows were skipped during parsing",
             zap.Int("skipped_rows", skippedRows),
@@ -591,6 +857,7 @@ func logSyncSummary(logger *zap.Logger, summary *model.SyncSummary) {
         zap.Int("successful_syncs", summary.SuccessfulSyncs),
         zap.Int("failed_syncs", summary.FailedSyncs),
         zap.Int64("total_rows_synced", summary.TotalRowsSynced),
+        zap.Duration("total_duration", summary.TotalDuration),
     )
 
     for _, result := range summary.Results {
@@ -631,34 +898,63 @@ func formatBigQueryStatusErrors(status *bigquery.JobStatus) string {
     return b.String()
 }
 
+// notifyBatch reports a loaded batch to the job's OnBatch callback, if any, with
+// the number of rows loaded from it and the last row read.
+func notifyBatch(job model.Job, loaded int, batch []model.Savable) error {
+    if job.OnBatch == nil || len(batch) == 0 {
+        return nil
+    }
+    if err := job.OnBatch(int64(loaded), batch[len(batch)-1]); err != nil {
+        return fmt.Errorf("failed to record batch progress: %w", err)
+    }
+    return nil
+}
+
 // uploadBufferToBigQuery uploads the JSON data stored in an in-memory buffer to a BigQuery table.
 // It creates a BigQuery load job using the provided buffer as the source. The `truncate` flag
 // controls whether the target table is overwritten (WriteTruncate) or appended to (WriteAppend).
+// A load job that fails for a transient reason leaves the table unchanged and is run again
+// from the same buffer. Waiting for a job that was created is retried on its own, since
+// running the job again could load its rows twice.
 // After the upload completes successfully, the buffer is reset for reuse.
 // Returns an error if the load job creation, execution, or completion fails.
-func uploadBufferToBigQuery(ctx context.Context, bqClient *bigquery.Client, cfg *model.Config, table string, buf *bytes.Buffer, truncate bool) error {
-    source := bigquery.NewReaderSource(buf)
-    source.SourceFormat = bigquery.JSON
+func uploadBufferToBigQuery(ctx context.Context, bqClient *bigquery.Client, cfg *model.Config, table string, buf *bytes.Buffer, truncate bool, logger *zap.Logger) error {
+    retry := newRetryPolicy(cfg)
+    data := buf.Bytes()
 
-    loader := bqClient.Dataset(cfg.BigQueryDatasetID).Table(table).LoaderFrom(source)
-    if truncate {
-        loader.WriteDisposition = bigquery.WriteTruncate
-    } else {
-        loader.WriteDisposition = bigquery.WriteAppend
-    }
+    err := retry.do(ctx, logger, "BigQuery load job", func(ctx context.Context) error {
+        source := bigquery.NewReaderSource(bytes.NewReader(data))
+        source.SourceFormat = bigquery.JSON
 
-    bqJob, err := loader.Run(ctx)
-    if err != nil {
-        return fmt.Errorf("failed to create BigQuery load job: %w", err)
-    }
+        loader := bqClient.Dataset(cfg.BigQueryDatasetID).Table(table).LoaderFrom(source)
+        if truncate {
+            loader.WriteDisposition = bigquery.WriteTruncate
+        } else {
+            loader.WriteDisposition = bigquery.WriteAppend
+        }
 
-    status, err := bqJob.Wait(ctx)
-    if err != nil {
-        return fmt.Errorf("failed to wait for BigQuery job: %w", err)
-    }
+        bqJob, err := loader.Run(ctx)
+        if err != nil {
+            return fmt.Errorf("failed to create BigQuery load job: %w", err)
+        }
+
+        var status *bigquery.JobStatus
+        err = retry.do(ctx, logger, "BigQuery load job wait", func(ctx context.Context) error {
+            var werr error
+            status, werr = bqJob.Wait(ctx)
+            return werr
+        })
+        if err != nil {
+            return permanentError{fmt.Errorf("failed to wait for BigQuery job: %w", err)}
+        }
 
-    if stErr := status.Err(); stErr != nil {
-        return fmt.Errorf("BigQuery load job failed: %w.%s", stErr, formatBigQueryStatusErrors(status))
+        if stErr := status.Err(); stErr != nil {
+            return fmt.Errorf("BigQuery load job failed: %w.%s", stErr, formatBigQueryStatusErrors(status))
+        }
+        return nil
+    })
+    if err != nil {
+        return err
     }
 
     buf.Reset()
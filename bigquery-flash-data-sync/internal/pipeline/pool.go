@@ -0,0 +1,80 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/wso2-open-operations/common-tools/bigquery-flash-data-sync/internal/model"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// syncUnit is one table sync scheduled on a workerPool.
+type syncUnit struct {
+	db     *model.DatabaseConfig
+	table  *model.TableConfig
+	logger *zap.Logger
+}
+
+// workerPool runs sync units on a bounded number of workers, so a large
+// configuration does not open more database connections and BigQuery load
+// jobs at once than the quotas allow.
+type workerPool struct {
+	size int
+}
+
+// newWorkerPool returns a pool running at most size units at a time. Sizes
+// below 1 are raised to 1.
+func newWorkerPool(size int) *workerPool {
+	return &workerPool{size: max(size, 1)}
+}
+
+// run calls fn for every unit and returns the results in unit order. A failed
+// unit never stops the others; its error is kept in its result. Units still
+// queued when ctx is done are not started and fail with the context error.
+func (p *workerPool) run(ctx context.Context, units []syncUnit, fn func(context.Context, syncUnit) *model.SyncResult) []*model.SyncResult {
+	results := make([]*model.SyncResult, len(units))
+
+	var g errgroup.Group
+	g.SetLimit(p.size)
+	for i, unit := range units {
+		g.Go(func() error {
+			if err := ctx.Err(); err != nil {
+				now := time.Now()
+				results[i] = &model.SyncResult{
+					DatabaseName: unit.db.Name,
+					TableName:    unit.table.Name,
+					TargetTable:  unit.table.GetTargetTableName(),
+					StartedAt:    now,
+					CompletedAt:  now,
+					Error:        fmt.Errorf("not started: %w", err),
+				}
+				unit.logger.Error("Table job not started", zap.Error(err))
+				return nil
+			}
+			results[i] = fn(ctx, unit)
+			return nil
+		})
+	}
+	// Units report failures through their results, so Wait never returns an error.
+	_ = g.Wait()
+
+	return results
+}
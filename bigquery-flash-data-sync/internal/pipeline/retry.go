@@ -0,0 +1,190 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package pipeline
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/wso2-open-operations/common-tools/bigquery-flash-data-sync/internal/model"
+	"go.uber.org/zap"
+	"google.golang.org/api/googleapi"
+)
+
+// retryableBigQueryReasons are the BigQuery error reasons that describe a
+// transient condition on the service side.
+var retryableBigQueryReasons = map[string]bool{
+	"backendError":      true,
+	"internalError":     true,
+	"rateLimitExceeded": true,
+	"timeout":           true,
+}
+
+// retryableMySQLErrors are MySQL error numbers worth retrying: lock wait
+// timeouts, deadlocks and a full connection table.
+var retryableMySQLErrors = map[uint16]bool{
+	1040: true, // ER_CON_COUNT_ERROR
+	1205: true, // ER_LOCK_WAIT_TIMEOUT
+	1213: true, // ER_LOCK_DEADLOCK
+}
+
+// retryablePostgresCodes are PostgreSQL SQLSTATE codes worth retrying, beyond
+// the connection exception class 08.
+var retryablePostgresCodes = map[pq.ErrorCode]bool{
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected
+	"53300": true, // too_many_connections
+	"57P01": true, // admin_shutdown
+	"57P03": true, // cannot_connect_now
+}
+
+// retryPolicy retries transient failures with exponential backoff and jitter.
+type retryPolicy struct {
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+}
+
+// newRetryPolicy returns the retry policy configured in cfg.
+func newRetryPolicy(cfg *model.Config) retryPolicy {
+	return retryPolicy{
+		maxAttempts: max(cfg.RetryMaxAttempts, 1),
+		baseDelay:   cfg.RetryBaseDelay,
+		maxDelay:    cfg.RetryMaxDelay,
+	}
+}
+
+// do runs op until it succeeds, fails with an error that is not retryable, or
+// has been attempted maxAttempts times, and returns the last error. Each retry
+// is logged at warn level.
+func (p retryPolicy) do(ctx context.Context, logger *zap.Logger, operation string, op func(context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		err := op(ctx)
+		if err == nil {
+			return nil
+		}
+		if attempt >= p.maxAttempts || ctx.Err() != nil || !isRetryable(err) {
+			return err
+		}
+
+		delay := p.backoff(attempt)
+		logger.Warn("Transient error, retrying",
+			zap.String("operation", operation),
+			zap.Int("attempt", attempt),
+			zap.Int("max_attempts", p.maxAttempts),
+			zap.Duration("delay", delay),
+			zap.Error(err),
+		)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the delay before the retry that follows attempt. The delay
+// doubles from baseDelay up to maxDelay, and a random half of it is dropped so
+// concurrent table jobs do not retry in lockstep.
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	delay := p.baseDelay
+	for i := 1; i < attempt && delay < p.maxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, p.maxDelay)
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+// permanentError marks an error that must not be retried even though its cause
+// looks transient, such as a wait failure on a load job that may have succeeded.
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// isRetryable reports whether err is a transient failure: a timeout, a dropped
+// connection, a 5xx or rate limit response, or a database lock conflict.
+// Authentication, permission and malformed query errors are not retried.
+func isRetryable(err error) bool {
+	var perm permanentError
+	if errors.As(err, &perm) {
+		return false
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		if apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= http.StatusInternalServerError {
+			return true
+		}
+		for _, item := range apiErr.Errors {
+			if retryableBigQueryReasons[item.Reason] {
+				return true
+			}
+		}
+		return false
+	}
+
+	var bqErr *bigquery.Error
+	if errors.As(err, &bqErr) {
+		return retryableBigQueryReasons[bqErr.Reason]
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return retryableMySQLErrors[mysqlErr.Number]
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code.Class() == "08" || retryablePostgresCodes[pqErr.Code]
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, mysql.ErrInvalidConn) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE)
+}
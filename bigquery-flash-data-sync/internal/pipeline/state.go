@@ -0,0 +1,119 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"cloud.google.com/go/bigquery"
+	"go.uber.org/zap"
+)
+
+// writeFileAtomic replaces the file at path with data. The data is written to a
+// temporary file in the same directory, synced to disk and renamed over path, so
+// a crash leaves either the old contents or the new ones, never a partial file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// ensureStateTable returns the BigQuery table datasetID.tableID that holds sync
+// state, creating it with schema if it does not exist. In a dry run a missing
+// table is not created and exists is false.
+func ensureStateTable(ctx context.Context, client *bigquery.Client, datasetID, tableID string, schema bigquery.Schema, dryRun bool, logger *zap.Logger) (table *bigquery.Table, exists bool, err error) {
+	if err := validateBigQueryIdentifier(tableID, "State table name"); err != nil {
+		return nil, false, err
+	}
+
+	table = client.Dataset(datasetID).Table(tableID)
+	if _, err := table.Metadata(ctx); err != nil {
+		if !strings.Contains(err.Error(), "Not found") && !strings.Contains(err.Error(), "notFound") {
+			return nil, false, fmt.Errorf("failed to get metadata for '%s': %w", tableID, err)
+		}
+		if dryRun {
+			logger.Info("Dry run: state table would be created",
+				zap.String("dataset", datasetID),
+				zap.String("table", tableID))
+			return table, false, nil
+		}
+		logger.Info("Creating state table",
+			zap.String("dataset", datasetID),
+			zap.String("table", tableID))
+		if err := table.Create(ctx, &bigquery.TableMetadata{Name: tableID, Schema: schema}); err != nil {
+			return nil, false, fmt.Errorf("failed to create '%s': %w", tableID, err)
+		}
+	}
+	return table, true, nil
+}
+
+// sourceFilter collects the WHERE conditions of a source query along with their
+// arguments, using the placeholder syntax of the source database.
+type sourceFilter struct {
+	postgres   bool
+	conditions []string
+	args       []any
+}
+
+// newSourceFilter returns an empty filter for a database of dbType.
+func newSourceFilter(dbType string) *sourceFilter {
+	return &sourceFilter{postgres: strings.EqualFold(dbType, "postgres")}
+}
+
+// where adds the condition "column op arg". column must be a validated identifier.
+func (f *sourceFilter) where(column, op string, arg any) {
+	f.args = append(f.args, arg)
+	placeholder := "?"
+	if f.postgres {
+		placeholder = fmt.Sprintf("$%d", len(f.args))
+	}
+	f.conditions = append(f.conditions, fmt.Sprintf("%s %s %s", column, op, placeholder))
+}
+
+// clone returns a copy of f that further conditions can be added to without
+// changing f.
+func (f *sourceFilter) clone() *sourceFilter {
+	return &sourceFilter{postgres: f.postgres, conditions: slices.Clone(f.conditions), args: slices.Clone(f.args)}
+}
+
+// apply appends the collected conditions to query as a WHERE clause.
+func (f *sourceFilter) apply(query string) string {
+	if len(f.conditions) == 0 {
+		return query
+	}
+	return query + " WHERE " + strings.Join(f.conditions, " AND ")
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package pipeline
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/wso2-open-operations/common-tools/bigquery-flash-data-sync/internal/model"
+)
+
+// dedupeTransformName names the deduplication transform in logs.
+const dedupeTransformName = "dedupe"
+
+// newDedupeTransform returns a transform that keeps one row per distinct value
+// of the keys columns within each batch. Of rows sharing a key, the one with the
+// greatest orderColumn value is kept, the last one read on a tie or when
+// orderColumn is empty.
+func newDedupeTransform(keys []string, orderColumn string) model.BatchTransform {
+	return model.BatchTransform{
+		Name: dedupeTransformName,
+		Apply: func(batch []model.Savable) []model.Savable {
+			return dedupeRows(batch, keys, orderColumn)
+		},
+	}
+}
+
+// dedupeRows returns the rows of batch left after removing duplicates of the
+// keys columns as described for newDedupeTransform. Kept rows stay in the order
+// they were read.
+func dedupeRows(batch []model.Savable, keys []string, orderColumn string) []model.Savable {
+	if len(batch) < 2 {
+		return batch
+	}
+
+	type candidate struct {
+		index int
+		order any
+	}
+	kept := make(map[string]candidate, len(batch))
+	for i, row := range batch {
+		values := row.ToSaveable()
+		key := dedupeKey(values, keys)
+		next := candidate{index: i, order: values[orderColumn]}
+		if current, ok := kept[key]; ok && orderColumn != "" && compareValues(next.order, current.order) < 0 {
+			continue
+		}
+		kept[key] = next
+	}
+	if len(kept) == len(batch) {
+		return batch
+	}
+
+	keep := make([]bool, len(batch))
+	for _, c := range kept {
+		keep[c.index] = true
+	}
+	out := make([]model.Savable, 0, len(kept))
+	for i, row := range batch {
+		if keep[i] {
+			out = append(out, row)
+		}
+	}
+	return out
+}
+
+// dedupeKey encodes the values of the keys columns of a row as a map key. The
+// type is part of each value, so 1 and "1" are distinct, and so is NULL.
+func dedupeKey(values map[string]any, keys []string) string {
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%T:%v\x00", values[k], values[k])
+	}
+	return b.String()
+}
+
+// compareValues orders two parsed column values, returning -1, 0 or 1. NULL
+// sorts first. Numbers compare numerically, and strings holding RFC 3339
+// timestamps, as parsed timestamps are, compare as times. Any other pair is
+// compared as text.
+func compareValues(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	if x, ok := a.(int64); ok {
+		if y, ok := b.(int64); ok {
+			return cmp.Compare(x, y)
+		}
+	}
+	if x, ok := toFloat(a); ok {
+		if y, ok := toFloat(b); ok {
+			return cmp.Compare(x, y)
+		}
+	}
+
+	sa, sb := fmt.Sprintf("%v", a), fmt.Sprintf("%v", b)
+	if ta, err := time.Parse(time.RFC3339Nano, sa); err == nil {
+		if tb, err := time.Parse(time.RFC3339Nano, sb); err == nil {
+			return ta.Compare(tb)
+		}
+	}
+	return strings.Compare(sa, sb)
+}
+
+// toFloat returns v as a float64 if it is a number.
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
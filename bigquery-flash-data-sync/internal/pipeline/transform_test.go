@@ -0,0 +1,165 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package pipeline
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/wso2-open-operations/common-tools/bigquery-flash-data-sync/internal/model"
+)
+
+// row returns a row with the given id and column values.
+func row(id string, values map[string]any) model.Savable {
+	names := []string{"id"}
+	vals := []any{id}
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		names = append(names, name)
+		vals = append(vals, values[name])
+	}
+	return &model.DynamicRow{ColumnNames: names, Values: vals}
+}
+
+func TestDedupeRows(t *testing.T) {
+	tests := []struct {
+		name        string
+		batch       []model.Savable
+		keys        []string
+		orderColumn string
+		want        []string
+	}{
+		{
+			name: "distinct keys are all kept",
+			batch: []model.Savable{
+				row("a", map[string]any{"k": int64(1)}),
+				row("b", map[string]any{"k": int64(2)}),
+			},
+			keys: []string{"k"},
+			want: []string{"a", "b"},
+		},
+		{
+			name: "duplicate keys keep the last row read",
+			batch: []model.Savable{
+				row("a", map[string]any{"k": int64(1)}),
+				row("b", map[string]any{"k": int64(2)}),
+				row("c", map[string]any{"k": int64(1)}),
+			},
+			keys: []string{"k"},
+			want: []string{"b", "c"},
+		},
+		{
+			name: "composite keys must match on every column",
+			batch: []model.Savable{
+				row("a", map[string]any{"k": int64(1), "j": "x"}),
+				row("b", map[string]any{"k": int64(1), "j": "y"}),
+				row("c", map[string]any{"k": int64(1), "j": "x"}),
+			},
+			keys: []string{"k", "j"},
+			want: []string{"b", "c"},
+		},
+		{
+			name: "values of different types are distinct",
+			batch: []model.Savable{
+				row("a", map[string]any{"k": int64(1)}),
+				row("b", map[string]any{"k": "1"}),
+			},
+			keys: []string{"k"},
+			want: []string{"a", "b"},
+		},
+		{
+			name: "greatest order value wins",
+			batch: []model.Savable{
+				row("a", map[string]any{"k": int64(1), "v": int64(3)}),
+				row("b", map[string]any{"k": int64(1), "v": int64(1)}),
+				row("c", map[string]any{"k": int64(1), "v": int64(2)}),
+			},
+			keys:        []string{"k"},
+			orderColumn: "v",
+			want:        []string{"a"},
+		},
+		{
+			name: "order ties keep the last row read",
+			batch: []model.Savable{
+				row("a", map[string]any{"k": int64(1), "v": int64(2)}),
+				row("b", map[string]any{"k": int64(1), "v": int64(2)}),
+				row("c", map[string]any{"k": int64(1), "v": int64(1)}),
+			},
+			keys:        []string{"k"},
+			orderColumn: "v",
+			want:        []string{"b"},
+		},
+		{
+			name: "timestamps order as times",
+			batch: []model.Savable{
+				row("a", map[string]any{"k": int64(1), "v": "2026-01-02T00:00:00+05:30"}),
+				row("b", map[string]any{"k": int64(1), "v": "2026-01-01T23:00:00Z"}),
+			},
+			keys:        []string{"k"},
+			orderColumn: "v",
+			want:        []string{"b"},
+		},
+		{
+			name: "NULL order values lose to any value",
+			batch: []model.Savable{
+				row("a", map[string]any{"k": int64(1), "v": int64(1)}),
+				row("b", map[string]any{"k": int64(1), "v": nil}),
+			},
+			keys:        []string{"k"},
+			orderColumn: "v",
+			want:        []string{"a"},
+		},
+		{
+			name: "NULL order values tie with each other",
+			batch: []model.Savable{
+				row("a", map[string]any{"k": int64(1), "v": nil}),
+				row("b", map[string]any{"k": int64(1), "v": nil}),
+			},
+			keys:        []string{"k"},
+			orderColumn: "v",
+			want:        []string{"b"},
+		},
+		{
+			name: "NULL keys are equal to each other only",
+			batch: []model.Savable{
+				row("a", map[string]any{"k": nil}),
+				row("b", map[string]any{"k": "<nil>"}),
+				row("c", map[string]any{"k": nil}),
+			},
+			keys: []string{"k"},
+			want: []string{"b", "c"},
+		},
+		{
+			name:  "single row batch is unchanged",
+			batch: []model.Savable{row("a", map[string]any{"k": int64(1)})},
+			keys:  []string{"k"},
+			want:  []string{"a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, r := range dedupeRows(tt.batch, tt.keys, tt.orderColumn) {
+				got = append(got, r.ToSaveable()["id"].(string))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("dedupeRows() kept %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
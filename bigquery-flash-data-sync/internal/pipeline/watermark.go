@@ -0,0 +1,354 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package pipeline
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/wso2-open-operations/common-tools/bigquery-flash-data-sync/internal/model"
+	"go.uber.org/zap"
+	"google.golang.org/api/iterator"
+)
+
+// Kinds of watermark value, so a stored value is sent back to the source
+// database with the type it was read as.
+const (
+	watermarkKindTimestamp = "timestamp"
+	watermarkKindInteger   = "integer"
+	watermarkKindString    = "string"
+)
+
+// Watermark is the high-water mark of an incremental table: the largest value of
+// its watermark column that has been loaded into BigQuery.
+type Watermark struct {
+	Column    string    `json:"column"`
+	Kind      string    `json:"kind"`
+	Value     string    `json:"value"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// newWatermark records a value scanned from the source database as a watermark
+// for column.
+func newWatermark(column string, v any) Watermark {
+	wm := Watermark{Column: column, Kind: watermarkKindString}
+	switch val := v.(type) {
+	case time.Time:
+		wm.Kind = watermarkKindTimestamp
+		wm.Value = val.UTC().Format(time.RFC3339Nano)
+	case int64, int32, int16, int8, int, uint64, uint32, uint16, uint8, uint:
+		wm.Kind = watermarkKindInteger
+		wm.Value = fmt.Sprintf("%d", val)
+	case []byte:
+		wm.Value = string(val)
+	default:
+		wm.Value = fmt.Sprintf("%v", val)
+	}
+	return wm
+}
+
+// queryArg returns the watermark value as a source query argument of its kind.
+func (w Watermark) queryArg() (any, error) {
+	switch w.Kind {
+	case watermarkKindTimestamp:
+		t, err := time.Parse(time.RFC3339Nano, w.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp watermark %q: %w", w.Value, err)
+		}
+		return t, nil
+	case watermarkKindInteger:
+		i, err := strconv.ParseInt(w.Value, 10, 64)
+		if err != nil {
+			// Unsigned values above MaxInt64 are compared as strings.
+			return w.Value, nil
+		}
+		return i, nil
+	default:
+		return w.Value, nil
+	}
+}
+
+// WatermarkStore persists the watermark of each incremental table between runs.
+type WatermarkStore interface {
+	// Load returns the stored watermark for the table, or nil when there is none.
+	Load(ctx context.Context, database, table string) (*Watermark, error)
+	// Save replaces the stored watermark for the table.
+	Save(ctx context.Context, database, table string, wm Watermark) error
+}
+
+// newWatermarkStore returns the store selected by cfg.WatermarkStore. Dry runs
+// read stored watermarks but never save them, and do not create the state table.
+func newWatermarkStore(ctx context.Context, cfg *model.Config, client *bigquery.Client, logger *zap.Logger) (WatermarkStore, error) {
+	switch cfg.WatermarkStore {
+	case model.WatermarkStoreBigQuery:
+		return newBigQueryWatermarkStore(ctx, client, cfg.BigQueryDatasetID, cfg.WatermarkStateTable, cfg.DryRun, logger)
+	default:
+		logger.Info("Using watermark state file", zap.String("path", cfg.WatermarkStateFile))
+		return &fileWatermarkStore{path: cfg.WatermarkStateFile}, nil
+	}
+}
+
+// watermarkKey identifies a table in the state file.
+func watermarkKey(database, table string) string {
+	return database + "." + table
+}
+
+// fileWatermarkStore keeps every watermark in one JSON file, keyed by
+// database.table. Table jobs run concurrently, so access is serialized, and the
+// file is replaced atomically by writeFileAtomic.
+type fileWatermarkStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+func (s *fileWatermarkStore) Load(_ context.Context, database, table string) (*Watermark, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	wm, ok := state[watermarkKey(database, table)]
+	if !ok {
+		return nil, nil
+	}
+	return &wm, nil
+}
+
+func (s *fileWatermarkStore) Save(_ context.Context, database, table string, wm Watermark) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, err := s.read()
+	if err != nil {
+		return err
+	}
+	state[watermarkKey(database, table)] = wm
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode watermark state: %w", err)
+	}
+
+	if err := writeFileAtomic(s.path, append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write watermark state file: %w", err)
+	}
+	return nil
+}
+
+// read returns the state file contents. A missing file is an empty state.
+func (s *fileWatermarkStore) read() (map[string]Watermark, error) {
+	state := make(map[string]Watermark)
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read watermark state file: %w", err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse watermark state file %s: %w", s.path, err)
+	}
+	return state, nil
+}
+
+// emptyWatermarkStore holds no watermarks and discards saves.
+type emptyWatermarkStore struct{}
+
+func (emptyWatermarkStore) Load(context.Context, string, string) (*Watermark, error) { return nil, nil }
+func (emptyWatermarkStore) Save(context.Context, string, string, Watermark) error    { return nil }
+
+// watermarkStateSchema is the schema of the BigQuery watermark state table.
+var watermarkStateSchema = bigquery.Schema{
+	{Name: "database", Type: bigquery.StringFieldType, Required: true},
+	{Name: "source_table", Type: bigquery.StringFieldType, Required: true},
+	{Name: "column", Type: bigquery.StringFieldType, Required: true},
+	{Name: "kind", Type: bigquery.StringFieldType, Required: true},
+	{Name: "value", Type: bigquery.StringFieldType, Required: true},
+	{Name: "updated_at", Type: bigquery.TimestampFieldType, Required: true},
+}
+
+// bigQueryWatermarkStore keeps watermarks in a table in the target dataset.
+// Saves append a row and loads read the newest one, so concurrent table jobs
+// never contend for DML on the same table.
+type bigQueryWatermarkStore struct {
+	client  *bigquery.Client
+	table   *bigquery.Table
+	dataset string
+}
+
+// newBigQueryWatermarkStore returns a store backed by datasetID.tableID,
+// creating the table if it does not exist. In a dry run a missing table is left
+// alone and the store reports no stored watermarks.
+func newBigQueryWatermarkStore(ctx context.Context, client *bigquery.Client, datasetID, tableID string, dryRun bool, logger *zap.Logger) (WatermarkStore, error) {
+	table, exists, err := ensureStateTable(ctx, client, datasetID, tableID, watermarkStateSchema, dryRun, logger)
+	if err != nil {
+		return nil, fmt.Errorf("watermark state table: %w", err)
+	}
+	if !exists {
+		return emptyWatermarkStore{}, nil
+	}
+
+	logger.Info("Using watermark state table",
+		zap.String("dataset", datasetID),
+		zap.String("table", tableID))
+	return &bigQueryWatermarkStore{client: client, table: table, dataset: datasetID}, nil
+}
+
+func (s *bigQueryWatermarkStore) Load(ctx context.Context, database, table string) (*Watermark, error) {
+	q := s.client.Query(fmt.Sprintf(
+		"SELECT `column`, kind, value, updated_at FROM `%s.%s` WHERE database = @database AND source_table = @table ORDER BY updated_at DESC LIMIT 1",
+		s.dataset, s.table.TableID,
+	))
+	q.Parameters = []bigquery.QueryParameter{
+		{Name: "database", Value: database},
+		{Name: "table", Value: table},
+	}
+
+	it, err := q.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query watermark state table: %w", err)
+	}
+
+	var row struct {
+		Column    string    `bigquery:"column"`
+		Kind      string    `bigquery:"kind"`
+		Value     string    `bigquery:"value"`
+		UpdatedAt time.Time `bigquery:"updated_at"`
+	}
+	if err := it.Next(&row); err != nil {
+		if errors.Is(err, iterator.Done) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read watermark state: %w", err)
+	}
+	return &Watermark{Column: row.Column, Kind: row.Kind, Value: row.Value, UpdatedAt: row.UpdatedAt}, nil
+}
+
+func (s *bigQueryWatermarkStore) Save(ctx context.Context, database, table string, wm Watermark) error {
+	// Values follow the column order of watermarkStateSchema.
+	row := &bigquery.ValuesSaver{
+		Schema: watermarkStateSchema,
+		Row:    []bigquery.Value{database, table, wm.Column, wm.Kind, wm.Value, wm.UpdatedAt},
+	}
+	if err := s.table.Inserter().Put(ctx, row); err != nil {
+		return fmt.Errorf("failed to save watermark state: %w", err)
+	}
+	return nil
+}
+
+// incrementalWindow is the range of watermark values one incremental run loads.
+// The upper bound is fixed before extraction, so rows written while the sync
+// runs are left for the next run instead of being skipped. The window is stored
+// in checkpoints, so a resumed run loads the same range.
+type incrementalWindow struct {
+	// Lower is exclusive. It is nil when no earlier watermark applies and every
+	// row up to Upper is loaded.
+	Lower *Watermark `json:"lower,omitempty"`
+	Upper Watermark  `json:"upper"`
+}
+
+// fullLoad reports whether the window covers every row up to its upper bound.
+func (w *incrementalWindow) fullLoad() bool {
+	return w.Lower == nil
+}
+
+// apply restricts f to the rows inside the window.
+func (w *incrementalWindow) apply(f *sourceFilter) error {
+	if w.Lower != nil {
+		lower, err := w.Lower.queryArg()
+		if err != nil {
+			return err
+		}
+		f.where(w.Upper.Column, ">", lower)
+	}
+	upper, err := w.Upper.queryArg()
+	if err != nil {
+		return err
+	}
+	f.where(w.Upper.Column, "<=", upper)
+	return nil
+}
+
+// planIncrementalWindow returns the window of rows past the stored watermark of
+// an incremental table, or past WatermarkInitial on the first run. Without either
+// it plans a full load. It returns nil when there are no new rows.
+func planIncrementalWindow(ctx context.Context, db *sql.DB, store WatermarkStore, retry retryPolicy, dbConfig *model.DatabaseConfig, tableConfig *model.TableConfig, sourceQuery string, logger *zap.Logger) (*incrementalWindow, error) {
+	col := tableConfig.WatermarkColumn
+	if err := validateSQLIdentifier(col); err != nil {
+		return nil, fmt.Errorf("invalid watermark column: %w", err)
+	}
+
+	stored, err := store.Load(ctx, dbConfig.Name, tableConfig.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	var lower *Watermark
+	switch {
+	case stored != nil && stored.Column == col:
+		lower = stored
+		logger.Info("Resuming incremental sync from stored watermark",
+			zap.String("watermark_column", col),
+			zap.String("watermark", stored.Value),
+			zap.Time("watermark_updated_at", stored.UpdatedAt))
+	case stored != nil:
+		logger.Warn("Watermark column changed since the last run, running a full load",
+			zap.String("stored_column", stored.Column),
+			zap.String("watermark_column", col))
+	case tableConfig.WatermarkInitial != "":
+		lower = &Watermark{Column: col, Kind: watermarkKindString, Value: tableConfig.WatermarkInitial}
+		logger.Info("No stored watermark, starting from the initial value",
+			zap.String("watermark_column", col),
+			zap.String("watermark", tableConfig.WatermarkInitial))
+	default:
+		logger.Info("No stored watermark, running a full load",
+			zap.String("watermark_column", col))
+	}
+
+	filter := newSourceFilter(dbConfig.Type)
+	if lower != nil {
+		arg, err := lower.queryArg()
+		if err != nil {
+			return nil, err
+		}
+		filter.where(col, ">", arg)
+	}
+	maxQuery := filter.apply(fmt.Sprintf("SELECT MAX(%s) FROM (%s) AS src", col, sourceQuery))
+
+	var upper any
+	err = retry.do(ctx, logger, "upper watermark query", func(ctx context.Context) error {
+		return db.QueryRowContext(ctx, maxQuery, filter.args...).Scan(&upper)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upper watermark: %w", err)
+	}
+	if upper == nil {
+		return nil, nil
+	}
+
+	return &incrementalWindow{Lower: lower, Upper: newWatermark(col, upper)}, nil
+}
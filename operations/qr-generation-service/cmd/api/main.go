@@ -19,90 +19,508 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
+	"flag"
 	"fmt"
+	"image/color"
+	"log/slog"
+	"net"
 	"net/http"
+	"net/http/pprof"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/cache"
 	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/config"
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/fetch"
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/lifecycle"
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/limiter"
 	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/logger"
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/metrics"
 	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/qr"
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/tlscert"
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/tracing"
 	transport "github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/transport/http"
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/version"
 )
 
 func main() {
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML or JSON config file (overrides CONFIG_FILE)")
+	flag.Parse()
+
 	log := logger.InitLogger()
-	log.Debug("Starting QR generation service initialization")
+	build := version.Get()
+	log.Info("Starting QR generation service",
+		"version", build.Version,
+		"commit", build.Commit,
+		"build_date", build.BuildDate,
+		"go_version", build.GoVersion,
+	)
 
-	cfg := config.LoadConfig()
+	cfg, err := config.LoadConfig(*configFile)
+	if err != nil {
+		log.Error("Invalid configuration", "config_file", *configFile, "error", err)
+		os.Exit(1)
+	}
 	log.Debug("Configuration loaded",
+		"config_file", *configFile,
 		"port", cfg.Port,
 		"read_timeout", cfg.ReadTimeout,
 		"write_timeout", cfg.WriteTimeout,
+		"request_timeout", cfg.RequestTimeout,
 		"max_body_size", cfg.MaxBodySize,
+		"decode_max_bytes", cfg.DecodeMaxBytes,
+		"decode_max_dimensions", fmt.Sprintf("%dx%d", cfg.DecodeMaxWidth, cfg.DecodeMaxHeight),
+		"decode_max_pixels", cfg.DecodeMaxPixels,
+		"max_uri_length", cfg.MaxURILength,
+		"max_data_bytes", cfg.MaxDataBytes,
+		"caption_max_chars", cfg.CaptionMaxChars,
+		"caption_max_lines", cfg.CaptionMaxLines,
+		"compress_min_bytes", cfg.CompressMin,
+		"min_module_pixels", cfg.MinModulePixels,
+		"density_strict", cfg.StrictDensity,
+		"pdf_dpi", cfg.PDFDPI,
+		"default_sizes", cfg.DefaultSizes,
+		"size_presets", cfg.SizePresets,
+		"cache_control", cfg.CacheControl,
+		"access_log_exclude", cfg.AccessLogSkip,
 	)
+	if err := cfg.Validate(); err != nil {
+		var invalid *config.ValidationError
+		if errors.As(err, &invalid) {
+			for _, problem := range invalid.Problems {
+				log.Error("Invalid configuration", "config_file", *configFile, "problem", problem)
+			}
+		} else {
+			log.Error("Invalid configuration", "config_file", *configFile, "error", err)
+		}
+		os.Exit(1)
+	}
+	if cfg.RequestTimeout >= cfg.WriteTimeout {
+		log.Warn("REQUEST_TIMEOUT should be shorter than WRITE_TIMEOUT so timed out requests can still receive a 503",
+			"request_timeout", cfg.RequestTimeout,
+			"write_timeout", cfg.WriteTimeout,
+		)
+	}
 
-	svc := qr.NewService(log, cfg.MinSize, cfg.MaxSize)
+	for name := range cfg.Features {
+		if !config.IsKnownFeature(name) {
+			log.Warn("Ignoring unknown feature in FEATURES", "feature", name)
+		}
+	}
+	log.Info("Enabled features", "features", cfg.EnabledFeatures())
+
+	defaultColors, err := parseDefaultColors(cfg)
+	if err != nil {
+		log.Error("Invalid default colors", "error", err)
+		os.Exit(1)
+	}
+	log.Info("Default colors", "colors", defaultColors.String())
+
+	// Tracing stays a pass-through unless an OTLP endpoint is configured.
+	var traced func(route string) func(http.Handler) http.Handler
+	shutdownTracing := func(context.Context) error { return nil }
+	if cfg.OTLPEndpoint != "" {
+		shutdownTracing, err = tracing.Setup(context.Background(), log, cfg.OTLPEndpoint)
+		if err != nil {
+			log.Error("Failed to set up tracing", "endpoint", cfg.OTLPEndpoint, "error", err)
+			os.Exit(1)
+		}
+		traced = tracing.Middleware
+		log.Info("Tracing enabled", "otlp_endpoint", cfg.OTLPEndpoint)
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	m := metrics.New(registry)
+
+	svc := qr.NewService(log, cfg.MinSize, cfg.SizeCeiling(), cfg.MaxDataBytes, cfg.CaptionMaxChars, cfg.CaptionMaxLines, cfg.MinModulePixels, cfg.StrictDensity)
+	// The limit sits inside the cache so cache hits never wait for a slot.
+	if cfg.MaxGenerations > 0 {
+		svc = limiter.NewService(svc, cfg.MaxGenerations, cfg.QueueSize, cfg.QueueTimeout, log)
+		log.Info("Concurrent generation limit enabled",
+			"max_concurrent_generations", cfg.MaxGenerations,
+			"queue_size", cfg.QueueSize,
+			"queue_timeout", cfg.QueueTimeout,
+		)
+	}
+	if cfg.CacheEntries > 0 {
+		svc = cache.NewService(svc, cache.New(cfg.CacheEntries, cfg.CacheMaxBytes), log, m.ObserveCacheLookup)
+		log.Info("QR cache enabled", "max_entries", cfg.CacheEntries, "max_bytes", cfg.CacheMaxBytes)
+	}
+	svc = metrics.InstrumentService(svc, m)
+	if cfg.OTLPEndpoint != "" {
+		svc = tracing.InstrumentService(svc)
+	}
 	log.Debug("QR service initialized")
 
-	h := transport.NewHandler(svc, log, cfg.MaxBodySize, cfg.MinSize, cfg.MaxSize)
-	log.Debug("HTTP handler initialized", "max_body_size", cfg.MaxBodySize)
+	reader := qr.NewReader(log, cfg.DecodeMaxWidth, cfg.DecodeMaxHeight, cfg.DecodeMaxPixels)
+
+	// Remote sources are off unless hosts are allowlisted, since the URLs come
+	// from clients.
+	var fetcher *fetch.Fetcher
+	if len(cfg.FetchHosts) > 0 {
+		fetcher = fetch.New(log, cfg.FetchHosts, cfg.FetchTimeout, cfg.FetchMaxBytes)
+		log.Info("Remote source fetching enabled",
+			"allowed_hosts", cfg.FetchHosts,
+			"timeout", cfg.FetchTimeout,
+			"max_bytes", cfg.FetchMaxBytes,
+		)
+		if cfg.FetchTimeout >= cfg.RequestTimeout {
+			log.Warn("FETCH_TIMEOUT should be shorter than REQUEST_TIMEOUT so a slow source fails with a 502 rather than a timeout",
+				"fetch_timeout", cfg.FetchTimeout,
+				"request_timeout", cfg.RequestTimeout,
+			)
+		}
+	}
+	h := transport.NewHandler(svc, reader, log, cfg.MaxBodySize, cfg.DecodeMaxBytes, cfg.MinSize, cfg.MaxSize, cfg.TrustedMaxSize, cfg.RequireHTTPS, defaultColors, cfg.PDFDPI, cfg.SizePresets, cfg.DefaultSizes, cfg.CacheControl, fetcher, transport.BatchLimits{
+		MaxItems:    cfg.MaxBatchItems,
+		Concurrency: cfg.BatchWorkers,
+	})
+	log.Debug("HTTP handler initialized", "max_body_size", cfg.MaxBodySize, "require_https", cfg.RequireHTTPS)
+
+	drain := &transport.DrainState{}
 
-	// Apply middleware to handlers
-	generateHandler := transport.MethodMiddleware(http.MethodPost)(http.HandlerFunc(h.Generate))
-	generateHandler = transport.RequestLoggingMiddleware(log)(generateHandler)
+	// Components are started in the order they are added and stopped in
+	// reverse, so the listeners close before the rate limiter and tracing they
+	// depend on, and readiness is the first thing to go.
+	group := lifecycle.New(log)
+	group.Add("tracing", lifecycle.Hooks{OnStop: func(ctx context.Context) error {
+		if err := shutdownTracing(ctx); err != nil {
+			return fmt.Errorf("failed to flush traces: %w", err)
+		}
+		return nil
+	}})
 
-	healthHandler := transport.RequestLoggingMiddleware(log)(http.HandlerFunc(h.HealthCheck))
+	// One limiter is shared by the generate and decode endpoints, so a client's budget
+	// covers all of them together.
+	var rateLimit func(http.Handler) http.Handler
+	if cfg.RateLimitRPS > 0 {
+		rateLimiter := transport.NewRateLimiter(log, cfg.RateLimitRPS, cfg.RateLimitBurst, cfg.TrustedProxies)
+		group.Add("rate limiter", lifecycle.Hooks{OnStop: func(context.Context) error {
+			rateLimiter.Close()
+			return nil
+		}})
+		rateLimit = rateLimiter.Middleware
+		log.Info("Rate limiting enabled",
+			"rps", cfg.RateLimitRPS,
+			"burst", cfg.RateLimitBurst,
+			"trusted_proxies", cfg.TrustedProxies,
+		)
+	}
 
-	mux := http.NewServeMux()
-	mux.Handle("/generate", generateHandler)
-	mux.Handle("/health", healthHandler)
-	log.Debug("HTTP routes registered", "endpoints", []string{"/generate", "/health"})
+	handler := transport.BuildHandler(cfg, h, log, transport.RouteDeps{
+		Drain:      drain,
+		RateLimit:  rateLimit,
+		Instrument: m.Middleware,
+		Trace:      traced,
+		Metrics:    promhttp.HandlerFor(registry, promhttp.HandlerOpts{}),
+	})
 
 	// Configure HTTP server with timeouts and security settings
+	// One server serves every listener, so they share the handler and are all
+	// closed by the same graceful shutdown.
 	srv := &http.Server{
-		Addr:              fmt.Sprintf(":%s", cfg.Port),
-		Handler:           mux,
+		Handler:           handler,
 		ReadTimeout:       cfg.ReadTimeout,
 		ReadHeaderTimeout: 2 * time.Second,
 		WriteTimeout:      cfg.WriteTimeout,
 		IdleTimeout:       60 * time.Second,
 	}
 	log.Debug("HTTP server configured",
-		"addr", srv.Addr,
+		"tcp_addrs", cfg.TCPAddrs(),
 		"read_timeout", cfg.ReadTimeout,
 		"write_timeout", cfg.WriteTimeout,
 	)
 
-	serverErr := make(chan error, 1)
-	go func() {
-		log.Info("Starting server", "port", cfg.Port, "addr", srv.Addr)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			serverErr <- err
+	var certs *tlscert.Reloader
+	if cfg.TLSCertFile != "" {
+		certs, err = tlscert.NewReloader(log, cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			log.Error("Failed to load TLS certificate", "cert_file", cfg.TLSCertFile, "key_file", cfg.TLSKeyFile, "error", err)
+			os.Exit(1)
+		}
+		srv.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: certs.GetCertificate,
+		}
+	}
+
+	// Profiling gets its own server bound to localhost, so it is never reachable
+	// through the public listeners or their middleware.
+	if cfg.EnablePprof {
+		adminMux := http.NewServeMux()
+		adminMux.HandleFunc("/debug/pprof/", pprof.Index)
+		adminMux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		adminMux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		adminMux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		adminMux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+		// No write timeout: CPU profiles and traces stream for as long as the
+		// caller asks.
+		adminSrv := &http.Server{
+			Addr:              net.JoinHostPort("localhost", cfg.AdminPort),
+			Handler:           adminMux,
+			ReadHeaderTimeout: 2 * time.Second,
+			IdleTimeout:       60 * time.Second,
 		}
-	}()
+		group.Add("admin server", &server{
+			name:  "admin server",
+			srv:   adminSrv,
+			log:   log.With("pprof", true),
+			group: group,
+			listen: func() ([]listener, error) {
+				ln, err := net.Listen("tcp", adminSrv.Addr)
+				if err != nil {
+					return nil, err
+				}
+				return []listener{{ln, false}}, nil
+			},
+		})
+	}
+
+	// Closing a Unix listener removes its socket file, but make sure a partial
+	// shutdown does not leave it behind. Added before the server so it runs
+	// after the server has stopped.
+	if cfg.ListenSocket != "" {
+		group.Add("unix socket", lifecycle.Hooks{OnStop: func(context.Context) error {
+			if err := os.Remove(cfg.ListenSocket); err != nil && !errors.Is(err, os.ErrNotExist) {
+				log.Warn("Failed to remove Unix socket file", "path", cfg.ListenSocket, "error", err)
+			}
+			return nil
+		}})
+	}
+
+	group.Add("server", &server{
+		name:  "server",
+		srv:   srv,
+		log:   log,
+		group: group,
+		listen: func() ([]listener, error) {
+			return listenAll(cfg, certs != nil)
+		},
+	})
+
+	// SIGHUP reloads the certificate so it can be rotated without downtime.
+	if certs != nil {
+		hup := make(chan os.Signal, 1)
+		group.Add("certificate reload", lifecycle.Hooks{
+			OnStart: func(context.Context) error {
+				signal.Notify(hup, syscall.SIGHUP)
+				go func() {
+					for range hup {
+						log.Info("SIGHUP received, reloading TLS certificate")
+						if err := certs.Reload(); err != nil {
+							log.Error("Failed to reload TLS certificate, keeping the current one", "error", err)
+						}
+					}
+				}()
+				return nil
+			},
+			OnStop: func(context.Context) error {
+				signal.Stop(hup)
+				close(hup)
+				return nil
+			},
+		})
+	}
+
+	// Readiness is added last so it is the first thing withdrawn: it fails the
+	// probe and rejects new work with 503 while in-flight requests drain.
+	group.Add("readiness", lifecycle.Hooks{
+		OnStart: func(context.Context) error {
+			h.SetReady(true)
+			log.Debug("Service marked ready")
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			h.SetReady(false)
+			drain.StartDraining()
+			srv.SetKeepAlivesEnabled(false)
+			log.Info("Initiating graceful shutdown", "timeout", cfg.ShutdownTimeout, "in_flight", drain.InFlight())
+
+			if pending, err := drain.Wait(ctx); err != nil {
+				log.Warn("Shutdown timeout reached with requests still in flight",
+					"pending", pending,
+					"timeout", cfg.ShutdownTimeout,
+				)
+			} else {
+				log.Debug("In-flight requests drained")
+			}
+			return nil
+		},
+	})
 
 	quit := make(chan os.Signal, 2)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	sig := <-quit
 
-	log.Info("Shutdown signal received", "signal", sig.String())
-	log.Debug("Initiating graceful shutdown", "timeout", cfg.ShutdownTimeout)
+	if err := group.Start(context.Background()); err != nil {
+		log.Error("Failed to start service", "error", err)
+		os.Exit(1)
+	}
+
+	failed := false
+	select {
+	case sig := <-quit:
+		log.Info("Shutdown signal received", "signal", sig.String())
+	case err := <-group.Failed():
+		log.Error("Server failed", "error", err)
+		failed = true
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 	defer cancel()
 
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Error("Server forced to shutdown", "error", err, "timeout", cfg.ShutdownTimeout)
-		if errors.Is(err, context.DeadlineExceeded) {
-			log.Warn("Shutdown timeout exceeded, closing connections")
-			srv.Close()
-		}
+	if err := group.Stop(ctx); err != nil {
+		log.Error("Shutdown failed", "error", err, "timeout", cfg.ShutdownTimeout)
+		os.Exit(1)
+	}
+	if failed {
 		os.Exit(1)
 	}
 
 	log.Info("Server exited gracefully")
 }
+
+// listener is a net.Listener and whether it serves TLS.
+type listener struct {
+	net.Listener
+	tls bool
+}
+
+// listenAll opens the TCP listeners and the Unix socket configured in cfg. TLS
+// applies to the TCP listeners only; the Unix socket is meant for local
+// sidecars and stays plain HTTP. If any of them fails, the ones already open
+// are closed.
+func listenAll(cfg *config.Config, useTLS bool) ([]listener, error) {
+	var listeners []listener
+	closeAll := func() {
+		for _, ln := range listeners {
+			ln.Close()
+		}
+	}
+	if !cfg.DisableTCP {
+		for _, addr := range cfg.TCPAddrs() {
+			ln, err := net.Listen("tcp", addr)
+			if err != nil {
+				closeAll()
+				return nil, err
+			}
+			listeners = append(listeners, listener{ln, useTLS})
+		}
+	}
+	if cfg.ListenSocket != "" {
+		ln, err := listenUnix(cfg.ListenSocket, cfg.SocketMode)
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("unix socket %s: %w", cfg.ListenSocket, err)
+		}
+		listeners = append(listeners, listener{ln, false})
+	}
+	return listeners, nil
+}
+
+// server is the lifecycle of an http.Server. Start opens its listeners and
+// serves each in its own goroutine, reporting a serve error to the group; Stop
+// shuts it down gracefully and closes any connections left when ctx expires.
+type server struct {
+	name   string
+	srv    *http.Server
+	log    *slog.Logger
+	group  *lifecycle.Group
+	listen func() ([]listener, error)
+}
+
+func (s *server) Start(context.Context) error {
+	listeners, err := s.listen()
+	if err != nil {
+		return err
+	}
+	for _, ln := range listeners {
+		go func(ln listener) {
+			s.log.Info("Starting "+s.name, "network", ln.Addr().Network(), "addr", ln.Addr().String(), "tls", ln.tls)
+			var err error
+			if ln.tls {
+				// The certificate comes from srv.TLSConfig.GetCertificate.
+				err = s.srv.ServeTLS(ln, "", "")
+			} else {
+				err = s.srv.Serve(ln)
+			}
+			if err != nil && err != http.ErrServerClosed {
+				s.group.Fail(s.name, err)
+			}
+		}(ln)
+	}
+	return nil
+}
+
+func (s *server) Stop(ctx context.Context) error {
+	err := s.srv.Shutdown(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		s.log.Warn("Shutdown timeout exceeded, closing connections", "component", s.name)
+		s.srv.Close()
+	}
+	return err
+}
+
+// parseDefaultColors builds the deployment default colours from cfg and checks
+// that they contrast enough to scan.
+func parseDefaultColors(cfg *config.Config) (qr.Colors, error) {
+	var colors qr.Colors
+	for _, c := range []struct {
+		env    string
+		value  string
+		target *color.Color
+	}{
+		{"DEFAULT_FG_COLOR", cfg.DefaultFG, &colors.Foreground},
+		{"DEFAULT_BG_COLOR", cfg.DefaultBG, &colors.Background},
+		{"DEFAULT_EYE_COLOR", cfg.DefaultEye, &colors.Eye},
+	} {
+		if c.value == "" {
+			continue
+		}
+		parsed, err := qr.ParseHexColor(c.value)
+		if err != nil {
+			return qr.Colors{}, fmt.Errorf("%s: %w", c.env, err)
+		}
+		*c.target = parsed
+	}
+	if err := colors.CheckContrast(); err != nil {
+		return qr.Colors{}, err
+	}
+	return colors, nil
+}
+
+// listenUnix listens on a Unix domain socket at path and applies mode to the socket file.
+// A stale socket left by a previous run is removed, but a live socket or any other
+// kind of file at path is treated as a conflict.
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s is already in use by another process", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+	}
+	return ln, nil
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package cache provides an in-memory LRU cache of generated QR codes.
+package cache
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/qr"
+)
+
+// LRU is a least-recently-used cache of generation results bounded by both entry
+// count and the total size of the cached images. It is safe for concurrent use.
+type LRU struct {
+	maxEntries int
+	maxBytes   int64
+
+	mu    sync.Mutex
+	bytes int64
+	order *list.List // front is most recently used
+	items map[string]*list.Element
+}
+
+type entry struct {
+	key    string
+	result qr.Result
+}
+
+// New creates an LRU holding at most maxEntries results and maxBytes of image data.
+func New(maxEntries int, maxBytes int64) *LRU {
+	return &LRU{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		order:      list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+// Get returns a copy of the result cached under key and marks it recently used.
+// The image bytes are shared and must not be modified.
+func (c *LRU) Get(key string) (*qr.Result, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	result := el.Value.(*entry).result
+	return &result, true
+}
+
+// Add caches result under key, evicting the least recently used entries until
+// both limits are met. A result larger than the byte budget is not cached.
+func (c *LRU) Add(key string, result *qr.Result) {
+	size := int64(len(result.Image))
+	if size > c.maxBytes || c.maxEntries <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.bytes -= int64(len(el.Value.(*entry).result.Image))
+		el.Value.(*entry).result = *result
+		c.bytes += size
+		c.order.MoveToFront(el)
+	} else {
+		c.items[key] = c.order.PushFront(&entry{key: key, result: *result})
+		c.bytes += size
+	}
+
+	for c.order.Len() > c.maxEntries || c.bytes > c.maxBytes {
+		oldest := c.order.Back()
+		e := oldest.Value.(*entry)
+		c.order.Remove(oldest)
+		delete(c.items, e.key)
+		c.bytes -= int64(len(e.result.Image))
+	}
+}
+
+// Len returns the number of cached results.
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Bytes returns the total size of the cached images.
+func (c *LRU) Bytes() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.bytes
+}
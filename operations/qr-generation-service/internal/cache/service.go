@@ -0,0 +1,65 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package cache
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/qr"
+)
+
+// Observer is told the outcome of every cache lookup, for example to export metrics.
+type Observer func(hit bool)
+
+// cachedService serves repeated requests from an LRU in front of a qr.Service.
+type cachedService struct {
+	next    qr.Service
+	cache   *LRU
+	logger  *slog.Logger
+	observe Observer
+}
+
+// NewService wraps svc so successful results are cached in c, keyed by
+// qr.Options.CacheKey. Errors are never cached. observe may be nil.
+func NewService(svc qr.Service, c *LRU, logger *slog.Logger, observe Observer) qr.Service {
+	return &cachedService{next: svc, cache: c, logger: logger, observe: observe}
+}
+
+func (s *cachedService) Generate(ctx context.Context, data []byte, opts qr.Options) (*qr.Result, error) {
+	key := opts.CacheKey(data)
+	if result, ok := s.cache.Get(key); ok {
+		s.logger.DebugContext(ctx, "QR cache hit", "key", key[:16], "image_size", len(result.Image))
+		s.record(true)
+		return result, nil
+	}
+	s.logger.DebugContext(ctx, "QR cache miss", "key", key[:16])
+	s.record(false)
+
+	result, err := s.next.Generate(ctx, data, opts)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Add(key, result)
+	return result, nil
+}
+
+func (s *cachedService) record(hit bool) {
+	if s.observe != nil {
+		s.observe(hit)
+	}
+}
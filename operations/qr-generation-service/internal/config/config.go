@@ -15,49 +15,392 @@
 // under the License.
 
 // Package config provides configuration management for the QR generation service.
-// It loads configuration from environment variables with sensible defaults.
+// It loads configuration from an optional YAML or JSON file and environment
+// variables, with sensible defaults.
 package config
 
 import (
+	"fmt"
+	"net"
+	"net/netip"
 	"os"
+	"runtime"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
 
-// Config holds application configuration loaded from environment variables.
+// Config holds application configuration loaded from a config file and environment variables.
 type Config struct {
 	Port            string
+	ListenAddrs     []string
+	ListenSocket    string
+	SocketMode      os.FileMode
+	DisableTCP      bool
+	TLSCertFile     string
+	TLSKeyFile      string
 	ReadTimeout     time.Duration
 	WriteTimeout    time.Duration
+	RequestTimeout  time.Duration
 	ShutdownTimeout time.Duration
+	RetryAfter      time.Duration
 	MaxBodySize     int64
+	DecodeMaxBytes  int64
+	DecodeMaxWidth  int
+	DecodeMaxHeight int
+	DecodeMaxPixels int64
+	MaxURILength    int
+	MaxDataBytes    int
+	CaptionMaxChars int
+	CaptionMaxLines int
+	CompressMin     int
+	RequireHTTPS    bool
 	MinSize         int
 	MaxSize         int
+	TrustedMaxSize  int
 	DefaultSize     int
+	DefaultSizes    map[string]int
+	SizePresets     map[string]int
+	DefaultFG       string
+	DefaultBG       string
+	DefaultEye      string
+	MinModulePixels float64
+	StrictDensity   bool
+	PDFDPI          int
+	MaxBatchItems   int
+	BatchWorkers    int
+	MaxGenerations  int
+	QueueSize       int
+	QueueTimeout    time.Duration
+	CacheEntries    int
+	CacheMaxBytes   int64
+	CacheControl    string
+	FetchHosts      []string
+	FetchTimeout    time.Duration
+	FetchMaxBytes   int64
+	APIKeys         []string
+	TrustedKeys     []string
+	DebugHeader     bool
+	CORSOrigins     []string
+	OTLPEndpoint    string
+	EnablePprof     bool
+	AdminPort       string
+	RateLimitRPS    float64
+	RateLimitBurst  int
+	TrustedProxies  int
+	AccessLogSkip   []string
+	AccessLogSample int
+	Features        map[string]bool
 }
 
 const DefaultSize = 256
 
+// Feature names accepted in the FEATURES environment variable.
+const (
+	FeatureGenerate = "generate"
+	FeatureUPI      = "upi"
+	FeatureBatch    = "batch"
+	FeatureWiFi     = "wifi"
+	FeatureVCard    = "vcard"
+	FeatureSMS      = "sms"
+	FeatureEmail    = "email"
+	FeatureEvent    = "event"
+	FeatureGeo      = "geo"
+	FeatureDecode   = "decode"
+)
+
+// AllFeatures lists every optional feature in the order they are reported at startup.
+var AllFeatures = []string{
+	FeatureGenerate,
+	FeatureUPI,
+	FeatureBatch,
+	FeatureWiFi,
+	FeatureVCard,
+	FeatureSMS,
+	FeatureEmail,
+	FeatureEvent,
+	FeatureGeo,
+	FeatureDecode,
+}
+
 var (
 	envCache     sync.Map
 	intCache     sync.Map
 	durationCache sync.Map
 	int64Cache   sync.Map
+	boolCache    sync.Map
+	floatCache   sync.Map
 )
 
-// LoadConfig reads configuration from environment variables and returns a Config instance.
-func LoadConfig() *Config {
+// LoadConfig returns the service configuration. Values set in the optional
+// config file at path override the built-in defaults, and environment variables
+// override both. An empty path skips the file.
+func LoadConfig(path string) (*Config, error) {
+	base := defaultConfig()
+	if path != "" {
+		if err := loadFile(path, base); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg := &Config{
+		Port:            getEnv("PORT", base.Port),
+		ListenAddrs:     base.ListenAddrs,
+		ListenSocket:    getEnv("LISTEN_SOCKET", base.ListenSocket),
+		SocketMode:      getEnvFileMode("LISTEN_SOCKET_MODE", base.SocketMode),
+		DisableTCP:      getEnvBool("DISABLE_TCP", base.DisableTCP),
+		TLSCertFile:     getEnv("TLS_CERT_FILE", base.TLSCertFile),
+		TLSKeyFile:      getEnv("TLS_KEY_FILE", base.TLSKeyFile),
+		ReadTimeout:     getEnvDuration("READ_TIMEOUT", base.ReadTimeout),
+		WriteTimeout:    getEnvDuration("WRITE_TIMEOUT", base.WriteTimeout),
+		RequestTimeout:  getEnvDuration("REQUEST_TIMEOUT", base.RequestTimeout),
+		ShutdownTimeout: getEnvDuration("SHUTDOWN_TIMEOUT", base.ShutdownTimeout),
+		RetryAfter:      getEnvDuration("SHUTDOWN_RETRY_AFTER", base.RetryAfter),
+		MaxBodySize:     getEnvInt64("MAX_BODY_SIZE", base.MaxBodySize),
+		DecodeMaxBytes:  getEnvInt64("DECODE_MAX_BYTES", base.DecodeMaxBytes),
+		DecodeMaxWidth:  getEnvInt("DECODE_MAX_WIDTH", base.DecodeMaxWidth),
+		DecodeMaxHeight: getEnvInt("DECODE_MAX_HEIGHT", base.DecodeMaxHeight),
+		DecodeMaxPixels: getEnvInt64("DECODE_MAX_PIXELS", base.DecodeMaxPixels),
+		MaxURILength:    getEnvInt("MAX_URI_LENGTH", base.MaxURILength),
+		MaxDataBytes:    getEnvInt("MAX_DATA_BYTES", base.MaxDataBytes),
+		CaptionMaxChars: getEnvInt("CAPTION_MAX_CHARS", base.CaptionMaxChars),
+		CaptionMaxLines: getEnvInt("CAPTION_MAX_LINES", base.CaptionMaxLines),
+		CompressMin:     getEnvInt("COMPRESS_MIN_BYTES", base.CompressMin),
+		RequireHTTPS:    getEnvBool("REQUIRE_HTTPS", base.RequireHTTPS),
+		MinSize:         getEnvInt("MIN_SIZE", base.MinSize),
+		MaxSize:         getEnvInt("MAX_SIZE", base.MaxSize),
+		TrustedMaxSize:  getEnvInt("TRUSTED_MAX_SIZE", base.TrustedMaxSize),
+		DefaultSize:     DefaultSize,
+		DefaultSizes:    base.DefaultSizes,
+		SizePresets:     base.SizePresets,
+		DefaultFG:       getEnv("DEFAULT_FG_COLOR", base.DefaultFG),
+		DefaultBG:       getEnv("DEFAULT_BG_COLOR", base.DefaultBG),
+		DefaultEye:      getEnv("DEFAULT_EYE_COLOR", base.DefaultEye),
+		MinModulePixels: getEnvFloat("MIN_MODULE_PIXELS", base.MinModulePixels),
+		StrictDensity:   getEnvBool("DENSITY_STRICT", base.StrictDensity),
+		PDFDPI:          getEnvInt("PDF_DPI", base.PDFDPI),
+		MaxBatchItems:   getEnvInt("MAX_BATCH_ITEMS", base.MaxBatchItems),
+		BatchWorkers:    getEnvInt("BATCH_CONCURRENCY", base.BatchWorkers),
+		MaxGenerations:  getEnvInt("MAX_CONCURRENT_GENERATIONS", base.MaxGenerations),
+		QueueSize:       getEnvInt("GENERATION_QUEUE_SIZE", base.QueueSize),
+		QueueTimeout:    getEnvDuration("GENERATION_QUEUE_TIMEOUT", base.QueueTimeout),
+		CacheEntries:    getEnvInt("CACHE_MAX_ENTRIES", base.CacheEntries),
+		CacheMaxBytes:   getEnvInt64("CACHE_MAX_BYTES", base.CacheMaxBytes),
+		CacheControl:    getEnv("CACHE_CONTROL", base.CacheControl),
+		FetchHosts:      base.FetchHosts,
+		FetchTimeout:    getEnvDuration("FETCH_TIMEOUT", base.FetchTimeout),
+		FetchMaxBytes:   getEnvInt64("FETCH_MAX_BYTES", base.FetchMaxBytes),
+		APIKeys:         base.APIKeys,
+		TrustedKeys:     base.TrustedKeys,
+		DebugHeader:     getEnvBool("DEBUG_HEADER", base.DebugHeader),
+		CORSOrigins:     base.CORSOrigins,
+		OTLPEndpoint:    getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", base.OTLPEndpoint),
+		EnablePprof:     getEnvBool("ENABLE_PPROF", base.EnablePprof),
+		AdminPort:       getEnv("ADMIN_PORT", base.AdminPort),
+		RateLimitRPS:    getEnvFloat("RATE_LIMIT_RPS", base.RateLimitRPS),
+		RateLimitBurst:  getEnvInt("RATE_LIMIT_BURST", base.RateLimitBurst),
+		TrustedProxies:  getEnvInt("TRUSTED_PROXIES", base.TrustedProxies),
+		AccessLogSkip:   base.AccessLogSkip,
+		AccessLogSample: getEnvInt("ACCESS_LOG_SAMPLE_EXCLUDED", base.AccessLogSample),
+		Features:        base.Features,
+	}
+	if addrs := getEnv("LISTEN_ADDRS", ""); addrs != "" {
+		cfg.ListenAddrs = parseList(addrs)
+	}
+	if hosts := getEnv("FETCH_ALLOWED_HOSTS", ""); hosts != "" {
+		cfg.FetchHosts = parseList(hosts)
+	}
+	if keys := getEnv("API_KEYS", ""); keys != "" {
+		cfg.APIKeys = parseList(keys)
+	}
+	if keys := getEnv("TRUSTED_API_KEYS", ""); keys != "" {
+		cfg.TrustedKeys = parseList(keys)
+	}
+	if origins := getEnv("CORS_ALLOWED_ORIGINS", ""); origins != "" {
+		cfg.CORSOrigins = parseList(origins)
+	}
+	if paths := getEnv("ACCESS_LOG_EXCLUDE", ""); paths != "" {
+		cfg.AccessLogSkip = parseList(paths)
+	}
+	if features := getEnv("FEATURES", ""); features != "" {
+		cfg.Features = parseFeatures(features)
+	}
+	if presets := getEnv("SIZE_PRESETS", ""); presets != "" {
+		parsed, err := parseSizes(presets)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SIZE_PRESETS: %w", err)
+		}
+		cfg.SizePresets = parsed
+	}
+	if sizes := getEnv("DEFAULT_SIZES", ""); sizes != "" {
+		parsed, err := parseSizes(sizes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DEFAULT_SIZES: %w", err)
+		}
+		cfg.DefaultSizes = parsed
+	}
+	return cfg, nil
+}
+
+// defaultConfig returns the configuration used when neither a config file nor
+// an environment variable sets a value.
+func defaultConfig() *Config {
 	return &Config{
-		Port:            getEnv("PORT", "8080"),
-		ReadTimeout:     getEnvDuration("READ_TIMEOUT", 5*time.Second),
-		WriteTimeout:    getEnvDuration("WRITE_TIMEOUT", 10*time.Second),
-		ShutdownTimeout: getEnvDuration("SHUTDOWN_TIMEOUT", 5*time.Second),
-		MaxBodySize:     getEnvInt64("MAX_BODY_SIZE", 524288),
-		MinSize:         getEnvInt("MIN_SIZE", 64),
-		MaxSize:         getEnvInt("MAX_SIZE", 2048),
+		Port:            "8080",
+		SocketMode:      0o660,
+		ReadTimeout:     5 * time.Second,
+		WriteTimeout:    10 * time.Second,
+		RequestTimeout:  5 * time.Second,
+		ShutdownTimeout: 5 * time.Second,
+		RetryAfter:      5 * time.Second,
+		MaxBodySize:     524288,
+		DecodeMaxBytes:  524288,
+		DecodeMaxWidth:  4096,
+		DecodeMaxHeight: 4096,
+		DecodeMaxPixels: 16777216,
+		MaxURILength:    8192,
+		CaptionMaxChars: 128,
+		CaptionMaxLines: 2,
+		CompressMin:     1024,
+		MinSize:         64,
+		MaxSize:         2048,
 		DefaultSize:     DefaultSize,
+		SizePresets:     map[string]int{"sm": 128, "md": 256, "lg": 512, "xl": 1024},
+		MinModulePixels: 3,
+		PDFDPI:          300,
+		MaxBatchItems:   100,
+		BatchWorkers:    runtime.NumCPU(),
+		QueueTimeout:    time.Second,
+		CacheMaxBytes:   67108864,
+		FetchTimeout:    3 * time.Second,
+		FetchMaxBytes:   4096,
+		AdminPort:       "6060",
+		RateLimitBurst:  20,
+		AccessLogSkip:   []string{"/health", "/healthz", "/readyz", "/metrics"},
+		Features:        parseFeatures(""),
+	}
+}
+
+// SizeCeiling returns the largest size any caller may request: TRUSTED_MAX_SIZE
+// when it is above MAX_SIZE, otherwise MAX_SIZE. The QR service enforces it,
+// and the handler holds callers without a trusted key to MAX_SIZE.
+func (c *Config) SizeCeiling() int {
+	return max(c.MaxSize, c.TrustedMaxSize)
+}
+
+// TCPAddrs returns the TCP addresses to listen on: every LISTEN_ADDRS entry,
+// with PORT filled in for entries that name only a host, or ":PORT" on all
+// interfaces when LISTEN_ADDRS is unset. Entries that Validate rejects are
+// skipped.
+func (c *Config) TCPAddrs() []string {
+	if len(c.ListenAddrs) == 0 {
+		return []string{net.JoinHostPort("", c.Port)}
+	}
+	addrs := make([]string, 0, len(c.ListenAddrs))
+	for _, entry := range c.ListenAddrs {
+		if addr, err := listenAddr(entry, c.Port); err == nil {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+// listenAddr normalizes a LISTEN_ADDRS entry to host:port. The host must be an
+// IP address, IPv6 ones in brackets when a port follows, or empty for every
+// interface; port is used when the entry has none.
+func listenAddr(entry, port string) (string, error) {
+	host, p, err := net.SplitHostPort(entry)
+	if err != nil {
+		// Without a port, an entry is only a host, possibly a bracketed IPv6
+		// address.
+		host, p = strings.TrimSuffix(strings.TrimPrefix(entry, "["), "]"), port
+	}
+	if host != "" {
+		if _, err := netip.ParseAddr(host); err != nil {
+			return "", fmt.Errorf("%q must be an IP address with an optional port, such as \"10.0.0.5:8080\" or \"[::1]:8080\"", entry)
+		}
+	}
+	if !validPort(p) {
+		return "", fmt.Errorf("%q must have a port between 1 and 65535", entry)
+	}
+	return net.JoinHostPort(host, p), nil
+}
+
+// FeatureEnabled reports whether the named feature is enabled for this deployment.
+func (c *Config) FeatureEnabled(name string) bool {
+	return c.Features[name]
+}
+
+// EnabledFeatures returns the known features that are enabled, in AllFeatures order.
+func (c *Config) EnabledFeatures() []string {
+	var enabled []string
+	for _, name := range AllFeatures {
+		if c.Features[name] {
+			enabled = append(enabled, name)
+		}
+	}
+	return enabled
+}
+
+// IsKnownFeature reports whether name is one of AllFeatures.
+func IsKnownFeature(name string) bool {
+	for _, known := range AllFeatures {
+		if known == name {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFeatures parses a comma-separated feature list. An empty list enables every feature.
+func parseFeatures(value string) map[string]bool {
+	features := make(map[string]bool)
+	if strings.TrimSpace(value) == "" {
+		for _, name := range AllFeatures {
+			features[name] = true
+		}
+		return features
+	}
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			features[name] = true
+		}
+	}
+	return features
+}
+
+// parseSizes parses a comma-separated list of name=pixels pairs such as
+// "sm=128,md=256". Names are case-insensitive and stored in lower case.
+func parseSizes(value string) (map[string]int, error) {
+	sizes := make(map[string]int)
+	for _, item := range parseList(value) {
+		name, pixels, ok := strings.Cut(item, "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !ok || name == "" {
+			return nil, fmt.Errorf("%q must be a name=pixels pair", item)
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(pixels))
+		if err != nil || size < 1 {
+			return nil, fmt.Errorf("%q must be a positive number of pixels", name)
+		}
+		sizes[name] = size
+	}
+	if len(sizes) == 0 {
+		return nil, fmt.Errorf("at least one name=pixels pair is required")
 	}
+	return sizes, nil
+}
+
+// parseList parses a comma-separated list, dropping surrounding spaces and empty entries.
+func parseList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
 
 // getEnv retrieves a string environment variable or returns fallback if not set.
@@ -125,3 +468,47 @@ func getEnvInt64(key string, fallback int64) int64 {
 	}
 	return fallback
 }
+
+// getEnvFloat retrieves a float64 environment variable or returns fallback (only accepts positive values).
+func getEnvFloat(key string, fallback float64) float64 {
+	if cached, ok := floatCache.Load(key); ok {
+		if val, ok := cached.(float64); ok {
+			return val
+		}
+	}
+
+	if value := os.Getenv(key); value != "" {
+		if f, err := strconv.ParseFloat(value, 64); err == nil && f > 0 {
+			floatCache.Store(key, f)
+			return f
+		}
+	}
+	return fallback
+}
+
+// getEnvBool retrieves a boolean environment variable or returns fallback if not set or invalid.
+func getEnvBool(key string, fallback bool) bool {
+	if cached, ok := boolCache.Load(key); ok {
+		if val, ok := cached.(bool); ok {
+			return val
+		}
+	}
+
+	if value := os.Getenv(key); value != "" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			boolCache.Store(key, b)
+			return b
+		}
+	}
+	return fallback
+}
+
+// getEnvFileMode retrieves an octal file mode environment variable (e.g. "0660") or returns fallback.
+func getEnvFileMode(key string, fallback os.FileMode) os.FileMode {
+	if value := getEnv(key, ""); value != "" {
+		if m, err := strconv.ParseUint(value, 8, 32); err == nil && m <= 0o777 {
+			return os.FileMode(m)
+		}
+	}
+	return fallback
+}
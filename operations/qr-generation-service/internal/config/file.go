@@ -0,0 +1,359 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileConfig is the layout of a config file. Keys are the lower-case names of
+// the matching environment variables. Pointer fields distinguish a value that
+// is not set from an explicit zero.
+type fileConfig struct {
+	Port               *string        `yaml:"port"`
+	ListenAddrs        []string       `yaml:"listen_addrs"`
+	ListenSocket       *string        `yaml:"listen_socket"`
+	ListenSocketMode   *string        `yaml:"listen_socket_mode"`
+	DisableTCP         *bool          `yaml:"disable_tcp"`
+	TLSCertFile        *string        `yaml:"tls_cert_file"`
+	TLSKeyFile         *string        `yaml:"tls_key_file"`
+	ReadTimeout        *string        `yaml:"read_timeout"`
+	WriteTimeout       *string        `yaml:"write_timeout"`
+	RequestTimeout     *string        `yaml:"request_timeout"`
+	ShutdownTimeout    *string        `yaml:"shutdown_timeout"`
+	ShutdownRetryAfter *string        `yaml:"shutdown_retry_after"`
+	MaxBodySize        *int64         `yaml:"max_body_size"`
+	DecodeMaxBytes     *int64         `yaml:"decode_max_bytes"`
+	DecodeMaxWidth     *int           `yaml:"decode_max_width"`
+	DecodeMaxHeight    *int           `yaml:"decode_max_height"`
+	DecodeMaxPixels    *int64         `yaml:"decode_max_pixels"`
+	MaxURILength       *int           `yaml:"max_uri_length"`
+	MaxDataBytes       *int           `yaml:"max_data_bytes"`
+	CaptionMaxChars    *int           `yaml:"caption_max_chars"`
+	CaptionMaxLines    *int           `yaml:"caption_max_lines"`
+	CompressMinBytes   *int           `yaml:"compress_min_bytes"`
+	RequireHTTPS       *bool          `yaml:"require_https"`
+	MinSize            *int           `yaml:"min_size"`
+	MaxSize            *int           `yaml:"max_size"`
+	TrustedMaxSize     *int           `yaml:"trusted_max_size"`
+	DefaultSizes       map[string]int `yaml:"default_sizes"`
+	SizePresets        map[string]int `yaml:"size_presets"`
+	DefaultFGColor     *string        `yaml:"default_fg_color"`
+	DefaultBGColor     *string        `yaml:"default_bg_color"`
+	DefaultEyeColor    *string        `yaml:"default_eye_color"`
+	MinModulePixels    *float64       `yaml:"min_module_pixels"`
+	DensityStrict      *bool          `yaml:"density_strict"`
+	PDFDPI             *int           `yaml:"pdf_dpi"`
+	MaxBatchItems      *int           `yaml:"max_batch_items"`
+	BatchConcurrency   *int           `yaml:"batch_concurrency"`
+	MaxGenerations     *int           `yaml:"max_concurrent_generations"`
+	QueueSize          *int           `yaml:"generation_queue_size"`
+	QueueTimeout       *string        `yaml:"generation_queue_timeout"`
+	CacheMaxEntries    *int           `yaml:"cache_max_entries"`
+	CacheMaxBytes      *int64         `yaml:"cache_max_bytes"`
+	CacheControl       *string        `yaml:"cache_control"`
+	FetchAllowedHosts  []string       `yaml:"fetch_allowed_hosts"`
+	FetchTimeout       *string        `yaml:"fetch_timeout"`
+	FetchMaxBytes      *int64         `yaml:"fetch_max_bytes"`
+	APIKeys            []string       `yaml:"api_keys"`
+	TrustedAPIKeys     []string       `yaml:"trusted_api_keys"`
+	DebugHeader        *bool          `yaml:"debug_header"`
+	CORSAllowedOrigins []string       `yaml:"cors_allowed_origins"`
+	OTLPEndpoint       *string        `yaml:"otel_exporter_otlp_endpoint"`
+	EnablePprof        *bool          `yaml:"enable_pprof"`
+	AdminPort          *string        `yaml:"admin_port"`
+	RateLimitRPS       *float64       `yaml:"rate_limit_rps"`
+	RateLimitBurst     *int           `yaml:"rate_limit_burst"`
+	TrustedProxies     *int           `yaml:"trusted_proxies"`
+	AccessLogExclude   []string       `yaml:"access_log_exclude"`
+	AccessLogSample    *int           `yaml:"access_log_sample_excluded"`
+	Features           []string       `yaml:"features"`
+}
+
+// loadFile reads the YAML or JSON config file at path and applies its values
+// to cfg. Every invalid field is reported in the returned error, not just the
+// first one found.
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	// JSON is valid YAML, so one decoder handles both formats. Each key is
+	// decoded on its own so that a malformed value is reported against its
+	// field and does not hide problems elsewhere in the file.
+	var doc map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	keys := make([]string, 0, len(doc))
+	for key := range doc {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return doc[keys[i]].Line < doc[keys[j]].Line })
+
+	var file fileConfig
+	fields := fileFields(&file)
+	v := validator{}
+	for _, key := range keys {
+		node := doc[key]
+		field, ok := fields[key]
+		if !ok {
+			v.add(key, "unknown setting")
+			continue
+		}
+		if err := node.Decode(field.Addr().Interface()); err != nil {
+			field.SetZero()
+			v.add(key, fmt.Sprintf("must be %s, got %q", describeKind(field.Type()), node.Value))
+		}
+	}
+
+	setString(&cfg.Port, file.Port)
+	if file.Port != nil {
+		if p, err := strconv.Atoi(*file.Port); err != nil || p < 1 || p > 65535 {
+			v.add("port", "must be a number between 1 and 65535")
+		}
+	}
+	if file.ListenAddrs != nil {
+		cfg.ListenAddrs = parseList(strings.Join(file.ListenAddrs, ","))
+	}
+	setString(&cfg.ListenSocket, file.ListenSocket)
+	if file.ListenSocketMode != nil {
+		if m, err := strconv.ParseUint(*file.ListenSocketMode, 8, 32); err != nil || m > 0o777 {
+			v.add("listen_socket_mode", "must be an octal file mode such as \"0660\"")
+		} else {
+			cfg.SocketMode = os.FileMode(m)
+		}
+	}
+	setBool(&cfg.DisableTCP, file.DisableTCP)
+	setString(&cfg.TLSCertFile, file.TLSCertFile)
+	setString(&cfg.TLSKeyFile, file.TLSKeyFile)
+	v.duration(&cfg.ReadTimeout, "read_timeout", file.ReadTimeout)
+	v.duration(&cfg.WriteTimeout, "write_timeout", file.WriteTimeout)
+	v.duration(&cfg.RequestTimeout, "request_timeout", file.RequestTimeout)
+	v.duration(&cfg.ShutdownTimeout, "shutdown_timeout", file.ShutdownTimeout)
+	v.duration(&cfg.RetryAfter, "shutdown_retry_after", file.ShutdownRetryAfter)
+	v.int64(&cfg.MaxBodySize, "max_body_size", file.MaxBodySize, 1)
+	v.int64(&cfg.DecodeMaxBytes, "decode_max_bytes", file.DecodeMaxBytes, 1)
+	v.int(&cfg.DecodeMaxWidth, "decode_max_width", file.DecodeMaxWidth, 1)
+	v.int(&cfg.DecodeMaxHeight, "decode_max_height", file.DecodeMaxHeight, 1)
+	v.int64(&cfg.DecodeMaxPixels, "decode_max_pixels", file.DecodeMaxPixels, 1)
+	v.int(&cfg.MaxURILength, "max_uri_length", file.MaxURILength, 0)
+	v.int(&cfg.MaxDataBytes, "max_data_bytes", file.MaxDataBytes, 0)
+	v.int(&cfg.CaptionMaxChars, "caption_max_chars", file.CaptionMaxChars, 1)
+	v.int(&cfg.CaptionMaxLines, "caption_max_lines", file.CaptionMaxLines, 1)
+	v.int(&cfg.CompressMin, "compress_min_bytes", file.CompressMinBytes, 0)
+	setBool(&cfg.RequireHTTPS, file.RequireHTTPS)
+	v.int(&cfg.MinSize, "min_size", file.MinSize, 1)
+	v.int(&cfg.MaxSize, "max_size", file.MaxSize, 1)
+	v.int(&cfg.TrustedMaxSize, "trusted_max_size", file.TrustedMaxSize, 0)
+	if cfg.MinSize > cfg.MaxSize {
+		v.add("max_size", "must not be less than min_size")
+	}
+	v.sizes(&cfg.DefaultSizes, "default_sizes", file.DefaultSizes)
+	v.sizes(&cfg.SizePresets, "size_presets", file.SizePresets)
+	setString(&cfg.DefaultFG, file.DefaultFGColor)
+	setString(&cfg.DefaultBG, file.DefaultBGColor)
+	setString(&cfg.DefaultEye, file.DefaultEyeColor)
+	v.float(&cfg.MinModulePixels, "min_module_pixels", file.MinModulePixels, false)
+	setBool(&cfg.StrictDensity, file.DensityStrict)
+	v.int(&cfg.PDFDPI, "pdf_dpi", file.PDFDPI, 72)
+	v.int(&cfg.MaxBatchItems, "max_batch_items", file.MaxBatchItems, 1)
+	v.int(&cfg.BatchWorkers, "batch_concurrency", file.BatchConcurrency, 1)
+	v.int(&cfg.MaxGenerations, "max_concurrent_generations", file.MaxGenerations, 0)
+	v.int(&cfg.QueueSize, "generation_queue_size", file.QueueSize, 0)
+	v.duration(&cfg.QueueTimeout, "generation_queue_timeout", file.QueueTimeout)
+	v.int(&cfg.CacheEntries, "cache_max_entries", file.CacheMaxEntries, 0)
+	v.int64(&cfg.CacheMaxBytes, "cache_max_bytes", file.CacheMaxBytes, 1)
+	setString(&cfg.CacheControl, file.CacheControl)
+	if file.FetchAllowedHosts != nil {
+		cfg.FetchHosts = parseList(strings.Join(file.FetchAllowedHosts, ","))
+	}
+	v.duration(&cfg.FetchTimeout, "fetch_timeout", file.FetchTimeout)
+	v.int64(&cfg.FetchMaxBytes, "fetch_max_bytes", file.FetchMaxBytes, 1)
+	if file.APIKeys != nil {
+		cfg.APIKeys = parseList(strings.Join(file.APIKeys, ","))
+	}
+	if file.TrustedAPIKeys != nil {
+		cfg.TrustedKeys = parseList(strings.Join(file.TrustedAPIKeys, ","))
+	}
+	setBool(&cfg.DebugHeader, file.DebugHeader)
+	for _, origin := range file.CORSAllowedOrigins {
+		if origin = strings.TrimSpace(origin); origin != "*" && !strings.Contains(origin, "://") {
+			v.add("cors_allowed_origins", fmt.Sprintf("%q must be an origin such as \"https://app.example.com\" or \"*\"", origin))
+		}
+	}
+	if file.CORSAllowedOrigins != nil {
+		cfg.CORSOrigins = parseList(strings.Join(file.CORSAllowedOrigins, ","))
+	}
+	setString(&cfg.OTLPEndpoint, file.OTLPEndpoint)
+	setBool(&cfg.EnablePprof, file.EnablePprof)
+	setString(&cfg.AdminPort, file.AdminPort)
+	if file.AdminPort != nil {
+		if p, err := strconv.Atoi(*file.AdminPort); err != nil || p < 1 || p > 65535 {
+			v.add("admin_port", "must be a number between 1 and 65535")
+		}
+	}
+	v.float(&cfg.RateLimitRPS, "rate_limit_rps", file.RateLimitRPS, true)
+	v.int(&cfg.RateLimitBurst, "rate_limit_burst", file.RateLimitBurst, 1)
+	v.int(&cfg.TrustedProxies, "trusted_proxies", file.TrustedProxies, 0)
+	// An empty list is kept as set, so a file can log every path.
+	if file.AccessLogExclude != nil {
+		cfg.AccessLogSkip = parseList(strings.Join(file.AccessLogExclude, ","))
+	}
+	v.int(&cfg.AccessLogSample, "access_log_sample_excluded", file.AccessLogSample, 0)
+	if file.Features != nil {
+		for _, name := range file.Features {
+			if name = strings.ToLower(strings.TrimSpace(name)); !IsKnownFeature(name) {
+				v.add("features", fmt.Sprintf("unknown feature %q (available: %s)", name, strings.Join(AllFeatures, ", ")))
+			}
+		}
+		cfg.Features = parseFeatures(strings.Join(file.Features, ","))
+	}
+
+	if len(v.problems) > 0 {
+		return fmt.Errorf("invalid config file %s: %s", path, strings.Join(v.problems, "; "))
+	}
+	return nil
+}
+
+// fileFields maps each yaml key of fileConfig to its field in file.
+func fileFields(file *fileConfig) map[string]reflect.Value {
+	fields := make(map[string]reflect.Value)
+	v := reflect.ValueOf(file).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		fields[v.Type().Field(i).Tag.Get("yaml")] = v.Field(i)
+	}
+	return fields
+}
+
+// describeKind names the kind of value a fileConfig field expects, for error
+// messages.
+func describeKind(t reflect.Type) string {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Int, reflect.Int64:
+		return "a whole number"
+	case reflect.Float64:
+		return "a number"
+	case reflect.Slice:
+		return "a list of strings"
+	case reflect.Map:
+		return "a map of names to whole numbers"
+	default:
+		return "a string"
+	}
+}
+
+// validator collects the problems found while applying a config file.
+type validator struct {
+	problems []string
+}
+
+func (v *validator) add(field, msg string) {
+	v.problems = append(v.problems, fmt.Sprintf("%s: %s", field, msg))
+}
+
+// duration parses a positive Go duration such as "5s" into dst.
+func (v *validator) duration(dst *time.Duration, field string, value *string) {
+	if value == nil {
+		return
+	}
+	d, err := time.ParseDuration(*value)
+	if err != nil || d <= 0 {
+		v.add(field, fmt.Sprintf("must be a positive duration such as \"5s\", got %q", *value))
+		return
+	}
+	*dst = d
+}
+
+func (v *validator) int(dst *int, field string, value *int, minimum int) {
+	if value == nil {
+		return
+	}
+	if *value < minimum {
+		v.add(field, fmt.Sprintf("must be at least %d, got %d", minimum, *value))
+		return
+	}
+	*dst = *value
+}
+
+func (v *validator) int64(dst *int64, field string, value *int64, minimum int64) {
+	if value == nil {
+		return
+	}
+	if *value < minimum {
+		v.add(field, fmt.Sprintf("must be at least %d, got %d", minimum, *value))
+		return
+	}
+	*dst = *value
+}
+
+// float stores a positive value, or a zero one too when allowZero is set.
+func (v *validator) float(dst *float64, field string, value *float64, allowZero bool) {
+	if value == nil {
+		return
+	}
+	if *value < 0 || (*value == 0 && !allowZero) {
+		v.add(field, fmt.Sprintf("must be a positive number, got %g", *value))
+		return
+	}
+	*dst = *value
+}
+
+// sizes validates a map of names to pixel sizes and, when it is set, replaces
+// dst with it. Names are stored in lower case.
+func (v *validator) sizes(dst *map[string]int, field string, value map[string]int) {
+	if value == nil {
+		return
+	}
+	sizes := make(map[string]int, len(value))
+	for name, size := range value {
+		if size < 1 {
+			v.add(field, fmt.Sprintf("%q must be a positive number of pixels", name))
+		}
+		sizes[strings.ToLower(strings.TrimSpace(name))] = size
+	}
+	if len(sizes) == 0 {
+		v.add(field, "at least one name is required")
+	}
+	*dst = sizes
+}
+
+func setString(dst *string, value *string) {
+	if value != nil {
+		*dst = *value
+	}
+}
+
+func setBool(dst *bool, value *bool) {
+	if value != nil {
+		*dst = *value
+	}
+}
@@ -0,0 +1,248 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/qr"
+)
+
+// ValidationError lists every problem Validate found in a configuration.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration: " + strings.Join(e.Problems, "; ")
+}
+
+// Validate checks value ranges and combinations of settings that each look
+// fine alone but would leave the server subtly broken. It returns a
+// *ValidationError naming every problem, not just the first one found, or nil
+// when the configuration is usable.
+func (c *Config) Validate() error {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if !c.DisableTCP && !validPort(c.Port) {
+		add("PORT must be a number between 1 and 65535, got %q", c.Port)
+	}
+	seen := make(map[string]bool, len(c.ListenAddrs))
+	for _, entry := range c.ListenAddrs {
+		addr, err := listenAddr(entry, c.Port)
+		switch {
+		case err != nil:
+			add("LISTEN_ADDRS %s", err)
+		case seen[addr]:
+			add("LISTEN_ADDRS lists %s more than once", addr)
+		}
+		seen[addr] = true
+	}
+	if c.DisableTCP && len(c.ListenAddrs) > 0 {
+		add("LISTEN_ADDRS cannot be used with DISABLE_TCP")
+	}
+	if c.DisableTCP && c.ListenSocket == "" {
+		add("DISABLE_TCP requires LISTEN_SOCKET to be set")
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		add("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	if c.EnablePprof && !validPort(c.AdminPort) {
+		add("ADMIN_PORT must be a number between 1 and 65535, got %q", c.AdminPort)
+	}
+	if c.EnablePprof && !c.DisableTCP {
+		for _, addr := range c.TCPAddrs() {
+			if _, port, _ := net.SplitHostPort(addr); port == c.AdminPort {
+				add("ADMIN_PORT must differ from the port of %s", addr)
+			}
+		}
+	}
+
+	for _, d := range []struct {
+		name  string
+		value fmt.Stringer
+		ok    bool
+	}{
+		{"READ_TIMEOUT", c.ReadTimeout, c.ReadTimeout > 0},
+		{"WRITE_TIMEOUT", c.WriteTimeout, c.WriteTimeout > 0},
+		{"REQUEST_TIMEOUT", c.RequestTimeout, c.RequestTimeout > 0},
+		{"SHUTDOWN_TIMEOUT", c.ShutdownTimeout, c.ShutdownTimeout > 0},
+	} {
+		if !d.ok {
+			add("%s must be positive, got %s", d.name, d.value)
+		}
+	}
+	// The write deadline is set once the request headers are read, so a
+	// shorter one than the read deadline cuts off responses to slow uploads.
+	if c.WriteTimeout < c.ReadTimeout {
+		add("WRITE_TIMEOUT (%s) must not be shorter than READ_TIMEOUT (%s)", c.WriteTimeout, c.ReadTimeout)
+	}
+
+	if c.MaxBodySize < 1 {
+		add("MAX_BODY_SIZE must be at least 1, got %d", c.MaxBodySize)
+	}
+	if c.DecodeMaxBytes < 1 {
+		add("DECODE_MAX_BYTES must be at least 1, got %d", c.DecodeMaxBytes)
+	}
+	if c.DecodeMaxWidth < 1 {
+		add("DECODE_MAX_WIDTH must be at least 1, got %d", c.DecodeMaxWidth)
+	}
+	if c.DecodeMaxHeight < 1 {
+		add("DECODE_MAX_HEIGHT must be at least 1, got %d", c.DecodeMaxHeight)
+	}
+	if c.DecodeMaxPixels < 1 {
+		add("DECODE_MAX_PIXELS must be at least 1, got %d", c.DecodeMaxPixels)
+	}
+	if c.MinSize < 1 {
+		add("MIN_SIZE must be at least 1, got %d", c.MinSize)
+	}
+	if c.MaxSize < c.MinSize {
+		add("MAX_SIZE (%d) must not be less than MIN_SIZE (%d)", c.MaxSize, c.MinSize)
+	}
+	if c.TrustedMaxSize != 0 && c.TrustedMaxSize < c.MaxSize {
+		add("TRUSTED_MAX_SIZE (%d) must not be less than MAX_SIZE (%d)", c.TrustedMaxSize, c.MaxSize)
+	}
+	if c.TrustedMaxSize != 0 && len(c.TrustedKeys) == 0 {
+		add("TRUSTED_MAX_SIZE has no effect unless TRUSTED_API_KEYS is set")
+	}
+	if c.DebugHeader && len(c.APIKeys) == 0 && len(c.TrustedKeys) == 0 {
+		add("DEBUG_HEADER has no effect unless API_KEYS or TRUSTED_API_KEYS is set")
+	}
+	if c.DefaultSize < c.MinSize || c.DefaultSize > c.MaxSize {
+		for _, format := range qr.Formats {
+			if _, ok := c.DefaultSizes[format]; !ok && format != qr.FormatPDF {
+				add("the default size %d is outside MIN_SIZE (%d) and MAX_SIZE (%d), so DEFAULT_SIZES must list %s", c.DefaultSize, c.MinSize, c.MaxSize, format)
+			}
+		}
+	}
+	for _, format := range sortedKeys(c.DefaultSizes) {
+		size := c.DefaultSizes[format]
+		switch {
+		case !qr.IsSupportedFormat(format) || format == qr.FormatPDF:
+			add("DEFAULT_SIZES format %q must be one of %s, except pdf", format, strings.Join(qr.Formats, ", "))
+		case size < c.MinSize || size > c.MaxSize:
+			add("DEFAULT_SIZES %s=%d must be within MIN_SIZE (%d) and MAX_SIZE (%d)", format, size, c.MinSize, c.MaxSize)
+		}
+	}
+	if len(c.SizePresets) == 0 {
+		add("SIZE_PRESETS must define at least one preset")
+	}
+	for _, name := range sortedKeys(c.SizePresets) {
+		if size := c.SizePresets[name]; size < c.MinSize || size > c.MaxSize {
+			add("SIZE_PRESETS %s=%d must be within MIN_SIZE (%d) and MAX_SIZE (%d)", name, size, c.MinSize, c.MaxSize)
+		}
+	}
+
+	if c.MinModulePixels <= 0 {
+		add("MIN_MODULE_PIXELS must be positive, got %g", c.MinModulePixels)
+	}
+	if c.PDFDPI < qr.MinPDFDPI || c.PDFDPI > qr.MaxPDFDPI {
+		add("PDF_DPI must be between %d and %d, got %d", qr.MinPDFDPI, qr.MaxPDFDPI, c.PDFDPI)
+	}
+	if c.CaptionMaxChars < 1 || c.CaptionMaxChars > qr.MaxCaptionChars {
+		add("CAPTION_MAX_CHARS must be between 1 and %d, got %d", qr.MaxCaptionChars, c.CaptionMaxChars)
+	}
+	if c.CaptionMaxLines < 1 || c.CaptionMaxLines > qr.MaxCaptionLines {
+		add("CAPTION_MAX_LINES must be between 1 and %d, got %d", qr.MaxCaptionLines, c.CaptionMaxLines)
+	}
+	if c.MaxBatchItems < 1 {
+		add("MAX_BATCH_ITEMS must be at least 1, got %d", c.MaxBatchItems)
+	}
+	if c.BatchWorkers < 1 {
+		add("BATCH_CONCURRENCY must be at least 1, got %d", c.BatchWorkers)
+	}
+	if c.MaxGenerations < 0 {
+		add("MAX_CONCURRENT_GENERATIONS must not be negative, got %d", c.MaxGenerations)
+	}
+	if c.QueueSize < 0 {
+		add("GENERATION_QUEUE_SIZE must not be negative, got %d", c.QueueSize)
+	}
+	if c.MaxGenerations > 0 && c.QueueTimeout <= 0 {
+		add("GENERATION_QUEUE_TIMEOUT must be positive when MAX_CONCURRENT_GENERATIONS is set, got %s", c.QueueTimeout)
+	}
+	if c.CacheEntries > 0 && c.CacheMaxBytes < 1 {
+		add("CACHE_MAX_BYTES must be at least 1 when CACHE_MAX_ENTRIES is set, got %d", c.CacheMaxBytes)
+	}
+	for _, host := range c.FetchHosts {
+		if !validFetchHost(host) {
+			add("FETCH_ALLOWED_HOSTS entry %q must be a host name, optionally with a port or a leading \"*.\", such as docs.example.com or *.example.com:8443", host)
+		}
+	}
+	if len(c.FetchHosts) > 0 && c.FetchTimeout <= 0 {
+		add("FETCH_TIMEOUT must be positive when FETCH_ALLOWED_HOSTS is set, got %s", c.FetchTimeout)
+	}
+	if len(c.FetchHosts) > 0 && c.FetchMaxBytes < 1 {
+		add("FETCH_MAX_BYTES must be at least 1 when FETCH_ALLOWED_HOSTS is set, got %d", c.FetchMaxBytes)
+	}
+	if c.RateLimitRPS < 0 {
+		add("RATE_LIMIT_RPS must not be negative, got %g", c.RateLimitRPS)
+	}
+	if c.RateLimitRPS > 0 && c.RateLimitBurst < 1 {
+		add("RATE_LIMIT_BURST must be at least 1 when RATE_LIMIT_RPS is set, got %d", c.RateLimitBurst)
+	}
+	for _, prefix := range c.AccessLogSkip {
+		if !strings.HasPrefix(prefix, "/") {
+			add("ACCESS_LOG_EXCLUDE entry %q must be a path starting with /", prefix)
+		}
+	}
+	if c.AccessLogSample < 0 {
+		add("ACCESS_LOG_SAMPLE_EXCLUDED must not be negative, got %d", c.AccessLogSample)
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// validPort reports whether port is a TCP port number.
+func validPort(port string) bool {
+	p, err := strconv.Atoi(port)
+	return err == nil && p >= 1 && p <= 65535
+}
+
+// sortedKeys returns the keys of m in order, so problems are reported the same
+// way on every start.
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// validFetchHost reports whether entry is a FETCH_ALLOWED_HOSTS entry: a host
+// name or IP address, optionally prefixed with "*." and followed by a port.
+func validFetchHost(entry string) bool {
+	host := entry
+	if h, port, err := net.SplitHostPort(entry); err == nil {
+		if !validPort(port) {
+			return false
+		}
+		host = h
+	}
+	host = strings.TrimPrefix(host, "*.")
+	return host != "" && !strings.ContainsAny(host, "/:@*?# ")
+}
@@ -0,0 +1,220 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package fetch downloads short remote documents for encoding, with the
+// safeguards needed when the URL comes from a client: only HTTPS URLs on an
+// allowlist of hosts are fetched, redirects are held to the same rules,
+// loopback and link-local addresses are never dialed, and the body is capped
+// in size and time.
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// maxRedirects is the number of redirects followed before a fetch fails.
+const maxRedirects = 3
+
+// ErrInvalidURL is returned, wrapped, for a URL that cannot be parsed or has
+// no host.
+var ErrInvalidURL = errors.New("invalid URL")
+
+// ErrNotAllowed matches, through errors.Is, the error for a URL that may not be
+// fetched: one that is not HTTPS, carries credentials, names a host missing
+// from the allowlist, or resolves to a blocked address.
+var ErrNotAllowed = errors.New("not allowed")
+
+// notAllowedError explains why a URL may not be fetched. Its message is safe to
+// show to the client: it never includes resolved addresses.
+type notAllowedError struct {
+	reason string
+}
+
+func (e *notAllowedError) Error() string { return e.reason }
+
+func (e *notAllowedError) Is(target error) bool { return target == ErrNotAllowed }
+
+func notAllowed(format string, args ...any) error {
+	return &notAllowedError{reason: fmt.Sprintf(format, args...)}
+}
+
+// ErrTooLarge is returned, wrapped, when the remote body exceeds the size cap.
+var ErrTooLarge = errors.New("too large")
+
+// Fetcher fetches remote documents from an allowlist of hosts.
+type Fetcher struct {
+	client   *http.Client
+	allowed  []string
+	maxBytes int64
+	logger   *slog.Logger
+}
+
+// New returns a Fetcher for the hosts in allowed. An entry is a host name,
+// optionally with a port, or "*.example.com" to allow every subdomain of
+// example.com but not example.com itself. An entry without a port only
+// matches the default HTTPS port. Each fetch, redirects included, must finish
+// within timeout and return at most maxBytes of body.
+func New(logger *slog.Logger, allowed []string, timeout time.Duration, maxBytes int64) *Fetcher {
+	f := &Fetcher{allowed: allowed, maxBytes: maxBytes, logger: logger}
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		// The address is checked after resolution, so a host on the
+		// allowlist cannot be pointed at a blocked address through DNS.
+		Control: func(_, address string, _ syscall.RawConn) error {
+			return checkAddress(address)
+		},
+	}
+	f.client = &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			// A proxy would be dialed instead of the target, bypassing the
+			// address check, so the environment's proxy settings are ignored.
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   timeout,
+			ResponseHeaderTimeout: timeout,
+			MaxIdleConns:          10,
+			IdleConnTimeout:       90 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return f.checkURL(req.URL)
+		},
+	}
+	return f
+}
+
+// Fetch returns the body of the document at rawURL. The URL is checked against
+// the allowlist first; a response other than 200 OK is an error.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	if err := f.checkURL(u); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		// Redirect and dial checks surface here wrapped in a *url.Error that
+		// names the request and the dialed address; only the reason is kept.
+		var blocked *notAllowedError
+		if errors.As(err, &blocked) {
+			return nil, blocked
+		}
+		// The URL may carry tokens in its query, so it is left out.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return nil, fmt.Errorf("request to %s failed: %w", u.Host, urlErr.Err)
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", u.Host, resp.Status)
+	}
+	if resp.ContentLength > f.maxBytes {
+		return nil, fmt.Errorf("%w: %d bytes, the limit is %d", ErrTooLarge, resp.ContentLength, f.maxBytes)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+	if int64(len(body)) > f.maxBytes {
+		return nil, fmt.Errorf("%w: more than the limit of %d bytes", ErrTooLarge, f.maxBytes)
+	}
+
+	f.logger.DebugContext(ctx, "Remote document fetched", "host", u.Host, "bytes", len(body))
+	return body, nil
+}
+
+// checkURL reports whether u may be fetched.
+func (f *Fetcher) checkURL(u *url.URL) error {
+	if u.Scheme != "https" {
+		return notAllowed("scheme %q is not allowed: only https URLs are fetched", u.Scheme)
+	}
+	if u.User != nil {
+		return notAllowed("credentials in the URL are not allowed")
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return fmt.Errorf("%w: no host", ErrInvalidURL)
+	}
+	port := u.Port()
+	if port == "" {
+		port = "443"
+	}
+	for _, entry := range f.allowed {
+		if matchHost(strings.ToLower(entry), host, port) {
+			return nil
+		}
+	}
+	return notAllowed("host %s is not on the allowlist", u.Host)
+}
+
+// matchHost reports whether an allowlist entry matches host and port.
+func matchHost(entry, host, port string) bool {
+	entryHost, entryPort := entry, "443"
+	if h, p, err := net.SplitHostPort(entry); err == nil {
+		entryHost, entryPort = h, p
+	}
+	if entryPort != port {
+		return false
+	}
+	if suffix, ok := strings.CutPrefix(entryHost, "*."); ok {
+		return strings.HasSuffix(host, "."+suffix)
+	}
+	return host == entryHost
+}
+
+// checkAddress rejects a dial to a loopback, link-local (which includes cloud
+// metadata services), multicast or unspecified address. Private networks are
+// allowed, since an allowlisted internal host is a legitimate source.
+func checkAddress(address string) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	ip = ip.Unmap()
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return notAllowed("host resolves to a blocked address")
+	}
+	return nil
+}
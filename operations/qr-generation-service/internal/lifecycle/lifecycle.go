@@ -0,0 +1,122 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package lifecycle starts the long-running parts of the service in a fixed
+// order and stops them in reverse, so each part can rely on the ones added
+// before it for as long as it runs.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// Component is a part of the service with a start and a stop step. Start must
+// not block: a component that keeps running, such as a server, does so in its
+// own goroutine and reports a later failure through Group.Fail.
+type Component interface {
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+// Hooks adapts a pair of functions to Component. Either may be nil.
+type Hooks struct {
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+func (h Hooks) Start(ctx context.Context) error {
+	if h.OnStart == nil {
+		return nil
+	}
+	return h.OnStart(ctx)
+}
+
+func (h Hooks) Stop(ctx context.Context) error {
+	if h.OnStop == nil {
+		return nil
+	}
+	return h.OnStop(ctx)
+}
+
+type entry struct {
+	name      string
+	component Component
+}
+
+// Group runs components in the order they were added.
+type Group struct {
+	logger     *slog.Logger
+	components []entry
+	started    int
+	failed     chan error
+}
+
+// New returns an empty Group.
+func New(logger *slog.Logger) *Group {
+	return &Group{logger: logger, failed: make(chan error, 1)}
+}
+
+// Add appends c under name, which is used in logs and errors. Components are
+// started in the order they are added and stopped in reverse.
+func (g *Group) Add(name string, c Component) {
+	g.components = append(g.components, entry{name: name, component: c})
+}
+
+// Start starts every component in order. If one fails, the ones already
+// started are stopped again, in reverse, and the start error is returned along
+// with any error from stopping them.
+func (g *Group) Start(ctx context.Context) error {
+	for _, e := range g.components {
+		g.logger.Debug("Starting component", "component", e.name)
+		if err := e.component.Start(ctx); err != nil {
+			err = fmt.Errorf("start %s: %w", e.name, err)
+			return errors.Join(err, g.Stop(ctx))
+		}
+		g.started++
+	}
+	return nil
+}
+
+// Stop stops the started components in reverse order. Every component is
+// stopped even if an earlier one fails; the errors are joined.
+func (g *Group) Stop(ctx context.Context) error {
+	var errs []error
+	for ; g.started > 0; g.started-- {
+		e := g.components[g.started-1]
+		g.logger.Debug("Stopping component", "component", e.name)
+		if err := e.component.Stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("stop %s: %w", e.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Fail reports that a running component stopped working. Only the first
+// failure is kept, since the group is shut down as soon as it is seen.
+func (g *Group) Fail(name string, err error) {
+	select {
+	case g.failed <- fmt.Errorf("%s: %w", name, err):
+	default:
+	}
+}
+
+// Failed returns a channel that receives the first failure passed to Fail.
+func (g *Group) Failed() <-chan error {
+	return g.failed
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package limiter caps how many QR codes are generated at once, so bursts of
+// traffic queue or are turned away instead of overloading the CPU.
+package limiter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/qr"
+)
+
+// BusyError is returned when every generation slot is taken and the request
+// could not queue, or waited longer than the queue timeout.
+type BusyError struct {
+	// RetryAfter is how long a client should wait before trying again.
+	RetryAfter time.Duration
+}
+
+func (e *BusyError) Error() string {
+	return fmt.Sprintf("too many concurrent generations, retry after %s", e.RetryAfter)
+}
+
+// limitedService lets at most cap(slots) generations run on the wrapped
+// qr.Service at a time, with up to cap(queue) more waiting for a slot.
+type limitedService struct {
+	next    qr.Service
+	slots   chan struct{}
+	queue   chan struct{}
+	timeout time.Duration
+	logger  *slog.Logger
+}
+
+// NewService wraps svc so that at most limit generations run at once. Up to
+// queueSize further calls wait, each for at most queueTimeout, before failing
+// with a *BusyError; with a queueSize of zero they fail straight away. A call
+// that svc abandons keeps its slot until the abandoned work stops.
+func NewService(svc qr.Service, limit, queueSize int, queueTimeout time.Duration, logger *slog.Logger) qr.Service {
+	return &limitedService{
+		next:    svc,
+		slots:   make(chan struct{}, limit),
+		queue:   make(chan struct{}, queueSize),
+		timeout: queueTimeout,
+		logger:  logger,
+	}
+}
+
+func (s *limitedService) Generate(ctx context.Context, data []byte, opts qr.Options) (*qr.Result, error) {
+	if err := s.acquire(ctx); err != nil {
+		return nil, err
+	}
+	result, err := s.next.Generate(ctx, data, opts)
+	// An abandoned generation keeps encoding in the background, so its slot is
+	// only freed once that work stops; otherwise timed-out requests could run
+	// any number of encodes beyond the limit.
+	var abandoned *qr.AbandonedError
+	if errors.As(err, &abandoned) {
+		go func() {
+			<-abandoned.Done
+			<-s.slots
+		}()
+		return result, err
+	}
+	<-s.slots
+	return result, err
+}
+
+// acquire takes a generation slot, queueing for one if none is free.
+func (s *limitedService) acquire(ctx context.Context) error {
+	select {
+	case s.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	select {
+	case s.queue <- struct{}{}:
+	default:
+		s.logger.WarnContext(ctx, "Generation rejected: all slots busy and queue full",
+			"limit", cap(s.slots),
+			"queue_size", cap(s.queue),
+		)
+		return s.busy()
+	}
+	defer func() { <-s.queue }()
+
+	start := time.Now()
+	timer := time.NewTimer(s.timeout)
+	defer timer.Stop()
+	select {
+	case s.slots <- struct{}{}:
+		s.logger.DebugContext(ctx, "Generation slot acquired after queueing", "waited", time.Since(start))
+		return nil
+	case <-timer.C:
+		s.logger.WarnContext(ctx, "Generation rejected: timed out waiting for a slot",
+			"limit", cap(s.slots),
+			"queue_timeout", s.timeout,
+		)
+		return s.busy()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// busy returns the error for a rejected call, suggesting a retry once a queued
+// call would have given up, rounded up to whole seconds.
+func (s *limitedService) busy() error {
+	retry := (s.timeout + time.Second - 1) / time.Second * time.Second
+	return &BusyError{RetryAfter: max(time.Second, retry)}
+}
@@ -18,10 +18,15 @@
 package logger
 
 import (
+	"context"
 	"log/slog"
 	"os"
 	"strings"
 	"sync"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/requestid"
 )
 
 var (
@@ -55,7 +60,7 @@ func InitLogger() *slog.Logger {
 			})
 		}
 
-		logger = slog.New(handler)
+		logger = slog.New(contextHandler{handler})
 		logger.Info(
 			"Logger initialized",
 			"LOG_ENV", logEnv,
@@ -65,6 +70,53 @@ func InitLogger() *slog.Logger {
 	return logger
 }
 
+// debugKey is the context key marking a request whose log calls are emitted at
+// debug level whatever LOG_LEVEL is set to.
+type debugKey struct{}
+
+// WithDebug returns a copy of ctx whose *Context log calls are emitted at debug
+// level even when LOG_LEVEL is higher, so one request can be traced in detail
+// without turning the whole service to debug.
+func WithDebug(ctx context.Context) context.Context {
+	return context.WithValue(ctx, debugKey{}, true)
+}
+
+// IsDebug reports whether ctx was marked by WithDebug.
+func IsDebug(ctx context.Context) bool {
+	debug, _ := ctx.Value(debugKey{}).(bool)
+	return debug
+}
+
+// contextHandler adds the request ID, and the trace and span IDs when tracing is
+// enabled, from the record's context to every log line, so calls made with the
+// *Context logging methods are correlated automatically. It also enables every
+// level for contexts marked by WithDebug.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return IsDebug(ctx) || h.Handler.Enabled(ctx, level)
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := requestid.FromContext(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
 // getLogLevelFromEnv parses LOG_LEVEL env var (debug/info/warn/error), defaults to info.
 func getLogLevelFromEnv() slog.Level {
 	levelStr := strings.ToLower(os.Getenv("LOG_LEVEL"))
@@ -0,0 +1,47 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestWithDebugEnablesDebugLogs(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(contextHandler{slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn})})
+
+	log.DebugContext(context.Background(), "plain debug")
+	log.InfoContext(context.Background(), "plain info")
+	log.DebugContext(WithDebug(context.Background()), "marked debug")
+	log.With("component", "test").InfoContext(WithDebug(context.Background()), "marked info")
+
+	out := buf.String()
+	for _, msg := range []string{"plain debug", "plain info"} {
+		if strings.Contains(out, msg) {
+			t.Errorf("log output contains %q below LOG_LEVEL without WithDebug:\n%s", msg, out)
+		}
+	}
+	for _, msg := range []string{"marked debug", "marked info"} {
+		if !strings.Contains(out, msg) {
+			t.Errorf("log output lacks %q logged with WithDebug:\n%s", msg, out)
+		}
+	}
+}
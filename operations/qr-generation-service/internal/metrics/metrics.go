@@ -0,0 +1,172 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package metrics exposes Prometheus metrics for the QR generation service.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/qr"
+)
+
+// Failure reasons reported by the generation failure counter.
+const (
+	ReasonTimeout  = "timeout"
+	ReasonCanceled = "canceled"
+	ReasonDensity  = "too_dense"
+	ReasonLogo     = "invalid_logo"
+	ReasonOther    = "other"
+)
+
+// Metrics holds the service's Prometheus collectors.
+type Metrics struct {
+	GenerateDuration   prometheus.Histogram
+	Requests           *prometheus.CounterVec
+	GenerationFailures *prometheus.CounterVec
+	InFlight           prometheus.Gauge
+	CacheLookups       *prometheus.CounterVec
+}
+
+// New creates the service metrics and registers them with reg. Passing a fresh
+// prometheus.NewRegistry() keeps metrics isolated, for example in tests.
+func New(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		GenerateDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "qr_generate_duration_seconds",
+			Help:    "Time taken by QR code generation, including failed attempts.",
+			Buckets: prometheus.ExponentialBuckets(0.001, 2, 14),
+		}),
+		Requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "qr_http_requests_total",
+			Help: "HTTP requests handled, by route and status code.",
+		}, []string{"route", "code"}),
+		GenerationFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "qr_generation_failures_total",
+			Help: "QR code generation failures, by reason.",
+		}, []string{"reason"}),
+		InFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "qr_http_in_flight_requests",
+			Help: "HTTP requests currently being handled.",
+		}),
+		CacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "qr_cache_lookups_total",
+			Help: "QR cache lookups, by result (hit or miss).",
+		}, []string{"result"}),
+	}
+	reg.MustRegister(m.GenerateDuration, m.Requests, m.GenerationFailures, m.InFlight, m.CacheLookups)
+	return m
+}
+
+// instrumentedService records generation latency and failures around a qr.Service.
+type instrumentedService struct {
+	next    qr.Service
+	metrics *Metrics
+}
+
+// InstrumentService wraps svc so every Generate call is timed and failures are
+// counted by reason.
+func InstrumentService(svc qr.Service, m *Metrics) qr.Service {
+	return &instrumentedService{next: svc, metrics: m}
+}
+
+func (s *instrumentedService) Generate(ctx context.Context, data []byte, opts qr.Options) (*qr.Result, error) {
+	start := time.Now()
+	result, err := s.next.Generate(ctx, data, opts)
+	s.metrics.GenerateDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		s.metrics.GenerationFailures.WithLabelValues(FailureReason(err)).Inc()
+	}
+	return result, err
+}
+
+// ObserveCacheLookup counts a cache hit or miss. It matches cache.Observer.
+func (m *Metrics) ObserveCacheLookup(hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.CacheLookups.WithLabelValues(result).Inc()
+}
+
+// FailureReason classifies a Generate error into one of the Reason constants.
+func FailureReason(err error) string {
+	var densityErr *qr.DensityError
+	var logoErr *qr.LogoError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ReasonTimeout
+	case errors.Is(err, context.Canceled):
+		return ReasonCanceled
+	case errors.As(err, &densityErr):
+		return ReasonDensity
+	case errors.As(err, &logoErr):
+		return ReasonLogo
+	}
+	return ReasonOther
+}
+
+// Middleware counts requests to route by status code and tracks requests in flight.
+func (m *Metrics) Middleware(route string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			m.InFlight.Inc()
+			defer m.InFlight.Dec()
+
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(sw, r)
+			m.Requests.WithLabelValues(route, strconv.Itoa(sw.status)).Inc()
+		})
+	}
+}
+
+// statusWriter records the status code written through it.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush forwards to the underlying writer so streaming handlers keep working.
+func (w *statusWriter) Flush() {
+	if fl, ok := w.ResponseWriter.(http.Flusher); ok {
+		fl.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
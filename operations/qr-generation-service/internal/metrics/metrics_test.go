@@ -0,0 +1,158 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/qr"
+)
+
+// stubService returns err from every Generate call.
+type stubService struct {
+	err error
+}
+
+func (s stubService) Generate(context.Context, []byte, qr.Options) (*qr.Result, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &qr.Result{}, nil
+}
+
+func TestNewRegistersOnFreshRegistries(t *testing.T) {
+	// Each registry gets its own collectors, so neither sees the other's counts.
+	a, b := New(prometheus.NewRegistry()), New(prometheus.NewRegistry())
+	a.ObserveCacheLookup(true)
+
+	if got := testutil.ToFloat64(a.CacheLookups.WithLabelValues("hit")); got != 1 {
+		t.Errorf("first registry hits = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(b.CacheLookups.WithLabelValues("hit")); got != 0 {
+		t.Errorf("second registry hits = %v, want 0", got)
+	}
+}
+
+func TestMiddlewareCountsRequests(t *testing.T) {
+	m := New(prometheus.NewRegistry())
+	handler := func(status int) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if got := testutil.ToFloat64(m.InFlight); got != 1 {
+				t.Errorf("in flight during request = %v, want 1", got)
+			}
+			if status != 0 {
+				w.WriteHeader(status)
+			}
+			_, _ = w.Write([]byte("ok"))
+		})
+	}
+
+	requests := []struct {
+		route  string
+		status int
+	}{
+		{"/generate", 0},
+		{"/generate", http.StatusOK},
+		{"/generate", http.StatusBadRequest},
+		{"/decode", http.StatusServiceUnavailable},
+	}
+	for _, req := range requests {
+		m.Middleware(req.route)(handler(req.status)).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, req.route, nil))
+	}
+
+	want := map[[2]string]float64{
+		{"/generate", "200"}: 2,
+		{"/generate", "400"}: 1,
+		{"/decode", "503"}:   1,
+		{"/decode", "200"}:   0,
+	}
+	for labels, count := range want {
+		if got := testutil.ToFloat64(m.Requests.WithLabelValues(labels[0], labels[1])); got != count {
+			t.Errorf("requests{route=%q, code=%q} = %v, want %v", labels[0], labels[1], got, count)
+		}
+	}
+	if got := testutil.ToFloat64(m.InFlight); got != 0 {
+		t.Errorf("in flight after requests = %v, want 0", got)
+	}
+}
+
+func TestInstrumentServiceCountsFailures(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := New(reg)
+
+	errs := []error{
+		nil,
+		context.DeadlineExceeded,
+		&qr.AbandonedError{Err: context.DeadlineExceeded},
+		fmt.Errorf("render: %w", context.Canceled),
+		&qr.DensityError{},
+		&qr.LogoError{Reason: "not a PNG"},
+		errors.New("boom"),
+	}
+	for _, err := range errs {
+		_, _ = InstrumentService(stubService{err: err}, m).Generate(context.Background(), []byte("x"), qr.Options{})
+	}
+
+	want := map[string]float64{
+		ReasonTimeout:  2,
+		ReasonCanceled: 1,
+		ReasonDensity:  1,
+		ReasonLogo:     1,
+		ReasonOther:    1,
+	}
+	for reason, count := range want {
+		if got := testutil.ToFloat64(m.GenerationFailures.WithLabelValues(reason)); got != count {
+			t.Errorf("failures{reason=%q} = %v, want %v", reason, got, count)
+		}
+	}
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != "qr_generate_duration_seconds" {
+			continue
+		}
+		if got := family.GetMetric()[0].GetHistogram().GetSampleCount(); got != uint64(len(errs)) {
+			t.Errorf("duration samples = %d, want %d, one per call", got, len(errs))
+		}
+		return
+	}
+	t.Error("qr_generate_duration_seconds not registered")
+}
+
+func TestObserveCacheLookup(t *testing.T) {
+	m := New(prometheus.NewRegistry())
+	for _, hit := range []bool{true, false, false, true, true} {
+		m.ObserveCacheLookup(hit)
+	}
+	if got := testutil.ToFloat64(m.CacheLookups.WithLabelValues("hit")); got != 3 {
+		t.Errorf("hits = %v, want 3", got)
+	}
+	if got := testutil.ToFloat64(m.CacheLookups.WithLabelValues("miss")); got != 2 {
+		t.Errorf("misses = %v, want 2", got)
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package qr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"strconv"
+
+	"github.com/skip2/go-qrcode"
+
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/timing"
+)
+
+const (
+	// MaxGIFFrames is the most frames, and so chunks, in one animated GIF.
+	MaxGIFFrames = 32
+	// GIFFrameDelay is how long each GIF frame is shown, in hundredths of a
+	// second. Phone cameras need a few hundred milliseconds to lock on.
+	GIFFrameDelay = 50
+
+	// chunkPrefix starts the header of every chunk produced by Chunk.
+	chunkPrefix = "CHUNK:"
+)
+
+// ChunkHeader returns the header that starts chunk index (1-based) of total,
+// for example "CHUNK:2/5:". A reader strips the header from each scanned code
+// and joins the payloads in index order once it has seen all total chunks.
+func ChunkHeader(index, total int) string {
+	return chunkPrefix + strconv.Itoa(index) + "/" + strconv.Itoa(total) + ":"
+}
+
+// Chunk splits data into payloads of at most capacity bytes, header included,
+// each prefixed with its ChunkHeader. Chunks are split at byte boundaries, so
+// multi-byte characters may span two chunks; readers must join the raw bytes
+// before decoding text.
+func Chunk(data []byte, capacity int) ([][]byte, error) {
+	// The header grows with the number of digits in the total, which in turn
+	// depends on how much room the header leaves, so search for a fixed point.
+	total := 1
+	for {
+		room := capacity - len(ChunkHeader(total, total))
+		if room <= 0 {
+			return nil, fmt.Errorf("capacity of %d bytes is too small to hold a chunk header", capacity)
+		}
+		n := max(1, (len(data)+room-1)/room)
+		if n <= total {
+			break
+		}
+		total = n
+	}
+
+	room := (len(data) + total - 1) / total
+	chunks := make([][]byte, 0, total)
+	for i := 0; i < total; i++ {
+		part := data[min(i*room, len(data)):min((i+1)*room, len(data))]
+		chunk := append([]byte(ChunkHeader(i+1, total)), part...)
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
+
+// maxChunkedLength returns the most data bytes Chunk can fit in MaxGIFFrames
+// chunks of capacity bytes each.
+func maxChunkedLength(capacity int) int {
+	return MaxGIFFrames * max(0, capacity-len(ChunkHeader(MaxGIFFrames, MaxGIFFrames)))
+}
+
+// generateGIF encodes data as an animated GIF. Data that fits in a single code
+// gives a one-frame GIF of the plain data; longer data is split with Chunk and
+// every chunk becomes a frame. All frames share one QR version, so they have the
+// same dimensions and module size.
+func (s *service) generateGIF(ctx context.Context, data []byte, opts Options, level string) (*Result, error) {
+	capacity := DataCapacity(level)
+	if s.maxDataBytes > 0 {
+		capacity = min(capacity, s.maxDataBytes)
+	}
+
+	payloads := [][]byte{data}
+	if len(data) > capacity {
+		if limit := maxChunkedLength(capacity); len(data) > limit {
+			s.logger.WarnContext(ctx, "QR code generation failed: data too long for an animated GIF",
+				"data_length", len(data),
+				"max_length", limit,
+				"max_frames", MaxGIFFrames,
+			)
+			return nil, &DataTooLongError{Length: len(data), Limit: limit}
+		}
+		var err error
+		payloads, err = Chunk(data, capacity)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split data: %w", err)
+		}
+	}
+
+	s.logger.DebugContext(ctx, "Encoding animated QR code",
+		"recovery_level", level,
+		"data_length", len(data),
+		"frames", len(payloads),
+		"chunk_capacity", capacity,
+	)
+
+	if err := s.checkDeadline(ctx, "encode"); err != nil {
+		return nil, err
+	}
+	done := timing.Start(ctx, "encode")
+	codes := make([]*qrcode.QRCode, len(payloads))
+	version := 0
+	for i, payload := range payloads {
+		q, err := qrcode.New(string(payload), recoveryLevels[level])
+		if err != nil {
+			done()
+			s.logger.ErrorContext(ctx, "Failed to encode QR code frame", "error", err, "frame", i+1)
+			return nil, fmt.Errorf("failed to encode QR code: %w", err)
+		}
+		codes[i] = q
+		version = max(version, q.VersionNumber)
+	}
+	// Re-encode shorter chunks at the largest version so every frame matches.
+	for i, q := range codes {
+		if q.VersionNumber == version {
+			continue
+		}
+		forced, err := qrcode.NewWithForcedVersion(string(payloads[i]), version, recoveryLevels[level])
+		if err != nil {
+			done()
+			s.logger.ErrorContext(ctx, "Failed to encode QR code frame", "error", err, "frame", i+1, "version", version)
+			return nil, fmt.Errorf("failed to encode QR code: %w", err)
+		}
+		codes[i] = forced
+	}
+	done()
+
+	border := opts.border()
+	modules := moduleCount(version, border)
+	dense, err := s.checkDensity(ctx, opts.Size, modules)
+	if err != nil {
+		return nil, err
+	}
+
+	anim := &gif.GIF{LoopCount: 0}
+	for i, q := range codes {
+		if err := s.checkDeadline(ctx, "render"); err != nil {
+			return nil, err
+		}
+		if border != QuietZone {
+			q.DisableBorder = true
+		}
+		img, err := s.rasterize(ctx, symbol{q: q, version: version}, border, modules, opts)
+		if err != nil {
+			return nil, err
+		}
+		done = timing.Start(ctx, "encode_image")
+		anim.Image = append(anim.Image, toPaletted(flattenOnWhite(img)))
+		anim.Delay = append(anim.Delay, GIFFrameDelay)
+		done()
+		s.logger.DebugContext(ctx, "Rendered animated QR code frame", "frame", i+1, "frames", len(codes))
+	}
+
+	if err := s.checkDeadline(ctx, "encode_image"); err != nil {
+		return nil, err
+	}
+	done = timing.Start(ctx, "encode_image")
+	var buf bytes.Buffer
+	err = gif.EncodeAll(&buf, anim)
+	done()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to encode image",
+			"error", err,
+			"format", FormatGIF,
+			"data_length", len(data),
+			"size", opts.Size,
+		)
+		return nil, fmt.Errorf("failed to encode QR code: %w", err)
+	}
+
+	bounds := anim.Image[0].Bounds()
+	s.logger.DebugContext(ctx, "QR code generated successfully",
+		"output_size_bytes", buf.Len(),
+		"format", FormatGIF,
+		"frames", len(anim.Image),
+		"image_dimensions", fmt.Sprintf("%dx%d", bounds.Dx(), bounds.Dy()),
+	)
+	return &Result{
+		Image:       buf.Bytes(),
+		ContentType: contentTypes[FormatGIF],
+		Width:       bounds.Dx(),
+		Height:      bounds.Dy(),
+		Modules:     modules,
+		Version:     version,
+		Dense:       dense,
+		Frames:      len(anim.Image),
+	}, nil
+}
+
+// toPaletted converts img to a paletted image for GIF encoding. Codes normally
+// use only a few colours, which are kept exactly; images with more than 256,
+// such as those with a photographic logo, are mapped to the nearest Plan 9
+// palette colour without dithering, which would add noise to the modules.
+func toPaletted(img image.Image) *image.Paletted {
+	pal, ok := exactPalette(img)
+	if !ok {
+		pal = palette.Plan9
+	}
+	// Frames are placed at the origin, as cropped images may not start there.
+	b := img.Bounds()
+	out := image.NewPaletted(image.Rect(0, 0, b.Dx(), b.Dy()), pal)
+	draw.Draw(out, out.Rect, img, b.Min, draw.Src)
+	return out
+}
+
+// exactPalette returns the distinct colours of img, or false if there are more
+// than a GIF palette holds.
+func exactPalette(img image.Image) (color.Palette, bool) {
+	b := img.Bounds()
+	seen := make(map[color.Color]bool)
+	var pal color.Palette
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y))
+			if seen[c] {
+				continue
+			}
+			if len(pal) == 256 {
+				return nil, false
+			}
+			seen[c] = true
+			pal = append(pal, c)
+		}
+	}
+	return pal, true
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package qr
+
+import (
+	"bytes"
+	"image/png"
+	"io"
+	"sync"
+)
+
+// maxPooledBuffer is the largest buffer capacity returned to bufferPool. Rare
+// huge outputs are left to the garbage collector so the pool does not pin
+// their memory.
+const maxPooledBuffer = 4 << 20
+
+// bufferPool holds the byte buffers images are encoded into, so concurrent
+// Generate calls reuse grown buffers instead of each growing a new one.
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// pngBuffers lets png.Encoder reuse its compressor and row buffers.
+type pngBuffers struct {
+	pool sync.Pool
+}
+
+func (p *pngBuffers) Get() *png.EncoderBuffer {
+	buf, _ := p.pool.Get().(*png.EncoderBuffer)
+	return buf
+}
+
+func (p *pngBuffers) Put(buf *png.EncoderBuffer) {
+	p.pool.Put(buf)
+}
+
+// pngEncoder encodes PNG images using the same compression level as go-qrcode.
+var pngEncoder = png.Encoder{CompressionLevel: png.BestCompression, BufferPool: &pngBuffers{}}
+
+// encodePooled runs encode against a buffer from bufferPool and returns a copy
+// of what it wrote. The copy is the only memory handed to the caller, so no
+// request can see bytes a pooled buffer held for another, and the buffer goes
+// back to the pool whether or not encode fails.
+func encodePooled(encode func(w io.Writer) error) ([]byte, error) {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer func() {
+		if buf.Cap() <= maxPooledBuffer {
+			buf.Reset()
+			bufferPool.Put(buf)
+		}
+	}()
+
+	if err := encode(buf); err != nil {
+		return nil, err
+	}
+	return bytes.Clone(buf.Bytes()), nil
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package qr
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"testing"
+)
+
+// benchmarkImage returns a two-colour image with QR-like 16 pixel modules.
+func benchmarkImage(size int) image.Image {
+	img := image.NewPaletted(image.Rect(0, 0, size, size), color.Palette{color.White, color.Black})
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if (x/16*7+y/16*13)%3 == 0 {
+				img.SetColorIndex(x, y, 1)
+			}
+		}
+	}
+	return img
+}
+
+func BenchmarkGenerate(b *testing.B) {
+	svc := NewService(testLogger, 64, 2048, 0, DefaultCaptionMaxChars, DefaultCaptionMaxLines, 0, false)
+	data := []byte("https://wso2.com/library/articles/qr-generation-benchmark")
+	for _, format := range []string{FormatPNG, FormatJPEG, FormatTIFF} {
+		b.Run(format, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := svc.Generate(context.Background(), data, Options{Size: 512, Format: format}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkEncodePNG compares the pooled PNG encoder with a fresh buffer and
+// encoder state per image, as every call used before pooling.
+func BenchmarkEncodePNG(b *testing.B) {
+	img := benchmarkImage(512)
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := encodePNG(img); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("unpooled", func(b *testing.B) {
+		b.ReportAllocs()
+		enc := png.Encoder{CompressionLevel: png.BestCompression}
+		for b.Loop() {
+			var buf bytes.Buffer
+			if err := enc.Encode(&buf, img); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestEncodePooledDoesNotShareMemory(t *testing.T) {
+	first, err := encodePooled(func(w io.Writer) error {
+		_, err := io.WriteString(w, "first request payload")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := encodePooled(func(w io.Writer) error {
+		if _, err := io.WriteString(w, "second"); err != nil {
+			return err
+		}
+		return io.ErrUnexpectedEOF
+	}); err != io.ErrUnexpectedEOF {
+		t.Fatalf("encodePooled() error = %v, want the encode error", err)
+	}
+	second, err := encodePooled(func(w io.Writer) error {
+		_, err := io.WriteString(w, "third")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(first) != "first request payload" {
+		t.Errorf("first result changed to %q after later encodes", first)
+	}
+	if string(second) != "third" {
+		t.Errorf("result = %q, want only the bytes written by its own encode", second)
+	}
+}
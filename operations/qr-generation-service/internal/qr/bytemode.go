@@ -0,0 +1,196 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package qr
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/common/reedsolomon"
+	"github.com/makiuchi-d/gozxing/qrcode/decoder"
+	"github.com/makiuchi-d/gozxing/qrcode/encoder"
+)
+
+// Data encoding modes accepted in Options.Mode.
+const (
+	// ModeAuto lets go-qrcode split the data into numeric, alphanumeric and
+	// byte segments, whichever gives the smallest code.
+	ModeAuto = "auto"
+	// ModeByte stores the data as a single byte-mode segment, exactly as given
+	// and with no text encoding assumed, for binary payloads such as tokens.
+	// Readers that report byte segments return the original bytes.
+	ModeByte = "byte"
+)
+
+// Modes lists the data encoding modes Generate can apply.
+var Modes = []string{ModeAuto, ModeByte}
+
+// IsSupportedMode reports whether mode is one of Modes.
+func IsSupportedMode(mode string) bool {
+	return mode == ModeAuto || mode == ModeByte
+}
+
+// byteModeLevels maps each recovery level name to its gozxing constant.
+var byteModeLevels = map[string]decoder.ErrorCorrectionLevel{
+	RecoveryLow:     decoder.ErrorCorrectionLevel_L,
+	RecoveryMedium:  decoder.ErrorCorrectionLevel_M,
+	RecoveryHigh:    decoder.ErrorCorrectionLevel_Q,
+	RecoveryHighest: decoder.ErrorCorrectionLevel_H,
+}
+
+// byteModePadding are the pad codewords that fill unused data capacity.
+var byteModePadding = [2]int{0xec, 0x11}
+
+// encodeByteMode encodes data as a single byte-mode segment at the smallest
+// version that holds it at level. go-qrcode always chooses its own segments, so
+// the symbol is built from the gozxing encoder's parts instead: Reed-Solomon
+// error correction, function patterns and masking. It returns the version and
+// the module matrix without a quiet zone.
+func encodeByteMode(data []byte, level string) (int, [][]bool, error) {
+	ecLevel, ok := byteModeLevels[level]
+	if !ok {
+		return 0, nil, fmt.Errorf("unsupported recovery level %q", level)
+	}
+	if len(data) == 0 {
+		return 0, nil, fmt.Errorf("no data to encode")
+	}
+
+	var version *decoder.Version
+	var dataCodewords int
+	for n := 1; n <= 40; n++ {
+		v, err := decoder.Version_GetVersionForNumber(n)
+		if err != nil {
+			return 0, nil, err
+		}
+		ec := v.GetECBlocksForLevel(ecLevel)
+		capacity := v.GetTotalCodewords() - ec.GetTotalECCodewords()
+		// Mode indicator, character count and data.
+		if 4+byteCountBits(n)+8*len(data) <= 8*capacity {
+			version, dataCodewords = v, capacity
+			break
+		}
+	}
+	if version == nil {
+		return 0, nil, fmt.Errorf("data too long: %d bytes do not fit in one code at recovery level %s", len(data), level)
+	}
+
+	bits := gozxing.NewEmptyBitArray()
+	_ = bits.AppendBits(0b0100, 4)
+	_ = bits.AppendBits(len(data), byteCountBits(version.GetVersionNumber()))
+	for _, b := range data {
+		_ = bits.AppendBits(int(b), 8)
+	}
+	// Up to four terminator bits, then zeros to a codeword boundary and
+	// alternating pad codewords to fill the capacity.
+	for i := 0; i < 4 && bits.GetSize() < 8*dataCodewords; i++ {
+		bits.AppendBit(false)
+	}
+	for bits.GetSize()%8 != 0 {
+		bits.AppendBit(false)
+	}
+	for i := 0; bits.GetSizeInBytes() < dataCodewords; i++ {
+		_ = bits.AppendBits(byteModePadding[i%2], 8)
+	}
+
+	final, err := interleaveBlocks(bits, version, ecLevel)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	dimension := version.GetDimensionForVersion()
+	matrix := encoder.NewByteMatrix(dimension, dimension)
+	best, bestPenalty := -1, math.MaxInt
+	for mask := 0; mask < 8; mask++ {
+		if err := encoder.MatrixUtil_buildMatrix(final, ecLevel, version, mask, matrix); err != nil {
+			return 0, nil, err
+		}
+		penalty := encoder.MaskUtil_applyMaskPenaltyRule1(matrix) +
+			encoder.MaskUtil_applyMaskPenaltyRule2(matrix) +
+			encoder.MaskUtil_applyMaskPenaltyRule3(matrix) +
+			encoder.MaskUtil_applyMaskPenaltyRule4(matrix)
+		if penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+	}
+	if err := encoder.MatrixUtil_buildMatrix(final, ecLevel, version, best, matrix); err != nil {
+		return 0, nil, err
+	}
+
+	modules := make([][]bool, dimension)
+	for y := range modules {
+		modules[y] = make([]bool, dimension)
+		for x := range modules[y] {
+			modules[y][x] = matrix.Get(x, y) == 1
+		}
+	}
+	return version.GetVersionNumber(), modules, nil
+}
+
+// byteCountBits returns the width of the byte-mode character count field.
+func byteCountBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+// interleaveBlocks splits the data codewords in bits into the error correction
+// blocks of version, adds each block's Reed-Solomon codewords, and interleaves
+// the data and then the error correction codewords across the blocks.
+func interleaveBlocks(bits *gozxing.BitArray, version *decoder.Version, ecLevel decoder.ErrorCorrectionLevel) (*gozxing.BitArray, error) {
+	ec := version.GetECBlocksForLevel(ecLevel)
+	ecPerBlock := ec.GetECCodewordsPerBlock()
+	rs := reedsolomon.NewReedSolomonEncoder(reedsolomon.GenericGF_QR_CODE_FIELD_256)
+
+	data := make([]byte, bits.GetSizeInBytes())
+	bits.ToBytes(0, data, 0, len(data))
+
+	var dataBlocks, ecBlocks [][]int
+	offset := 0
+	for _, group := range ec.GetECBlocks() {
+		for i := 0; i < group.GetCount(); i++ {
+			n := group.GetDataCodewords()
+			block := make([]int, n+ecPerBlock)
+			for j := 0; j < n; j++ {
+				block[j] = int(data[offset+j])
+			}
+			offset += n
+			if err := rs.Encode(block, ecPerBlock); err != nil {
+				return nil, fmt.Errorf("failed to compute error correction: %w", err)
+			}
+			dataBlocks = append(dataBlocks, block[:n])
+			ecBlocks = append(ecBlocks, block[n:])
+		}
+	}
+
+	result := gozxing.NewEmptyBitArray()
+	for _, blocks := range [][][]int{dataBlocks, ecBlocks} {
+		longest := 0
+		for _, block := range blocks {
+			longest = max(longest, len(block))
+		}
+		for i := 0; i < longest; i++ {
+			for _, block := range blocks {
+				if i < len(block) {
+					_ = result.AppendBits(block[i], 8)
+				}
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package qr
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/makiuchi-d/gozxing"
+)
+
+// testLogger discards everything logged during tests.
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// decodeBytes decodes the QR code in img with the service's Reader and returns
+// the raw bytes of its byte-mode segments.
+func decodeBytes(t *testing.T, img []byte) []byte {
+	t.Helper()
+	result, err := NewReader(testLogger, DefaultDecodeMaxDimension, DefaultDecodeMaxDimension, DefaultDecodeMaxPixels).(*reader).decode(context.Background(), img)
+	if err != nil {
+		t.Fatalf("decode() error = %v", err)
+	}
+	segments, ok := result.GetResultMetadata()[gozxing.ResultMetadataType_BYTE_SEGMENTS].([][]byte)
+	if !ok {
+		t.Fatal("decoded code has no byte-mode segments")
+	}
+	return bytes.Join(segments, nil)
+}
+
+func TestByteModeRoundTrip(t *testing.T) {
+	every := make([]byte, 256)
+	for i := range every {
+		every[i] = byte(i)
+	}
+	payloads := map[string][]byte{
+		"every byte value":    every,
+		"invalid UTF-8":       {0xc3, 0x28, 0xa0, 0xa1, 0xe2, 0x28, 0xa1, 0xf0, 0x28, 0x8c, 0xbc, 0xff, 0xfe},
+		"NUL bytes":           {0x00, 0x00, 'a', 0x00},
+		"Shift JIS lookalike": {0x82, 0xa0, 0x82, 0xa2, 0x93, 0xfa},
+		"single high byte":    {0x80},
+	}
+
+	svc := NewService(testLogger, 64, 2048, 0, DefaultCaptionMaxChars, DefaultCaptionMaxLines, 0, false)
+	for name, data := range payloads {
+		for _, level := range RecoveryLevels {
+			t.Run(name+"/"+level, func(t *testing.T) {
+				result, err := svc.Generate(context.Background(), data, Options{Size: 1024, Mode: ModeByte, RecoveryLevel: level})
+				if err != nil {
+					t.Fatalf("Generate() error = %v", err)
+				}
+				if got := decodeBytes(t, result.Image); !bytes.Equal(got, data) {
+					t.Errorf("decoded % x, want % x", got, data)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,221 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package qr
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gomedium"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	// DefaultCaptionMaxChars is the longest caption, in characters after
+	// SanitizeCaption, that Generate accepts unless configured otherwise.
+	DefaultCaptionMaxChars = 128
+	// DefaultCaptionMaxLines is the number of lines a caption may wrap onto
+	// unless configured otherwise.
+	DefaultCaptionMaxLines = 2
+	// MaxCaptionChars and MaxCaptionLines bound the configurable limits, so a
+	// caption can never grow the image height without limit.
+	MaxCaptionChars = 1024
+	MaxCaptionLines = 8
+
+	// captionFontScale is the caption font size as a fraction of the image width.
+	captionFontScale = 0.08
+	// minCaptionFontSize is the smallest caption font size in pixels, so
+	// captions on small codes stay legible.
+	minCaptionFontSize = 10
+	// captionLineHeight is the height of each caption line as a multiple of the
+	// font size.
+	captionLineHeight = 1.5
+)
+
+// CaptionError reports why a caption could not be drawn.
+type CaptionError struct {
+	Reason string
+}
+
+func (e *CaptionError) Error() string {
+	return "invalid caption: " + e.Reason
+}
+
+var (
+	captionFontOnce sync.Once
+	captionFont     *opentype.Font
+	captionFontErr  error
+)
+
+// parseCaptionFont parses the bundled Go Medium font once.
+func parseCaptionFont() (*opentype.Font, error) {
+	captionFontOnce.Do(func() {
+		captionFont, captionFontErr = opentype.Parse(gomedium.TTF)
+	})
+	return captionFont, captionFontErr
+}
+
+// SanitizeCaption returns caption with control and format characters removed
+// and every run of whitespace, line breaks and tabs included, collapsed to a
+// single space and trimmed from both ends. Invalid UTF-8 is returned unchanged
+// for ValidateCaption to reject.
+func SanitizeCaption(caption string) string {
+	if !utf8.ValidString(caption) {
+		return caption
+	}
+	var b strings.Builder
+	space := false
+	for _, r := range caption {
+		switch {
+		case unicode.IsSpace(r):
+			space = b.Len() > 0
+		case unicode.IsControl(r) || unicode.Is(unicode.Cf, r):
+		default:
+			if space {
+				b.WriteByte(' ')
+				space = false
+			}
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// ValidateCaption returns a *CaptionError unless caption, as returned by
+// SanitizeCaption, is valid UTF-8 of at most maxChars characters.
+func ValidateCaption(caption string, maxChars int) error {
+	if !utf8.ValidString(caption) {
+		return &CaptionError{Reason: "must be valid UTF-8"}
+	}
+	if n := utf8.RuneCountInString(caption); n > maxChars {
+		return &CaptionError{Reason: fmt.Sprintf("must be at most %d characters, got %d", maxChars, n)}
+	}
+	return nil
+}
+
+// drawCaption returns img extended downwards with caption centred beneath it in
+// fg on a band filled with bg, wrapped at spaces onto as many lines as the image
+// width needs. code is the region of img holding the dark modules and
+// symbolModules the symbol width in modules, quiet zone excluded. Light space is
+// added between the symbol and the caption where needed so the symbol keeps a
+// full QuietZone beneath it. A caption needing more than maxLines lines is
+// rejected with a *CaptionError.
+func drawCaption(img image.Image, caption string, maxLines int, code image.Rectangle, symbolModules int, fg, bg color.Color) (*image.NRGBA, error) {
+	f, err := parseCaptionFont()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse caption font: %w", err)
+	}
+
+	b := img.Bounds()
+	size := math.Max(minCaptionFontSize, math.Round(float64(b.Dx())*captionFontScale))
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load caption font: %w", err)
+	}
+	defer face.Close()
+
+	// Keep the text half the font size clear of each side edge.
+	lines := wrapCaption(face, caption, fixed.I(b.Dx()-int(size)))
+	if len(lines) > maxLines {
+		return nil, &CaptionError{Reason: fmt.Sprintf("needs %d lines at an image width of %d pixels, more than the limit of %d; shorten it or request a larger size", len(lines), b.Dx(), maxLines)}
+	}
+
+	gap := 0
+	if !code.Empty() && symbolModules > 0 {
+		modulePixels := float64(code.Dx()) / float64(symbolModules)
+		below := b.Max.Y - code.Max.Y
+		gap = max(0, int(math.Ceil(QuietZone*modulePixels))-below)
+	}
+	lineHeight := int(math.Ceil(size * captionLineHeight))
+
+	canvas := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()+gap+lineHeight*len(lines)))
+	draw.Draw(canvas, canvas.Rect, image.NewUniform(bg), image.Point{}, draw.Src)
+	draw.Draw(canvas, image.Rect(0, 0, b.Dx(), b.Dy()), img, b.Min, draw.Src)
+
+	metrics := face.Metrics()
+	textHeight := metrics.Ascent + metrics.Descent
+	drawer := font.Drawer{Dst: canvas, Src: image.NewUniform(fg), Face: face}
+	for i, line := range lines {
+		top := b.Dy() + gap + i*lineHeight
+		drawer.Dot = fixed.Point26_6{
+			X: (fixed.I(b.Dx()) - drawer.MeasureString(line)) / 2,
+			Y: fixed.I(top) + (fixed.I(lineHeight)-textHeight)/2 + metrics.Ascent,
+		}
+		drawer.DrawString(line)
+	}
+	return canvas, nil
+}
+
+// wrapCaption splits caption into lines no wider than width, breaking at
+// spaces. A word too wide for a line of its own is broken between characters.
+func wrapCaption(face font.Face, caption string, width fixed.Int26_6) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(caption) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if font.MeasureString(face, candidate) <= width {
+			line = candidate
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		for font.MeasureString(face, word) > width {
+			n := fitPrefix(face, word, width)
+			lines = append(lines, word[:n])
+			word = word[n:]
+		}
+		line = word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// fitPrefix returns the length in bytes of the longest prefix of s that fits in
+// width, and at least its first character so wrapping always progresses.
+func fitPrefix(face font.Face, s string, width fixed.Int26_6) int {
+	var advance fixed.Int26_6
+	prev := rune(-1)
+	n := 0
+	for i, r := range s {
+		if prev >= 0 {
+			advance += face.Kern(prev, r)
+		}
+		a, _ := face.GlyphAdvance(r)
+		advance += a
+		if n > 0 && advance > width {
+			break
+		}
+		n = i + utf8.RuneLen(r)
+		prev = r
+	}
+	return n
+}
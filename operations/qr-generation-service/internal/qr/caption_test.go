@@ -0,0 +1,96 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package qr
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeCaption(t *testing.T) {
+	tests := []struct {
+		name    string
+		caption string
+		want    string
+	}{
+		{name: "plain", caption: "SKU-10442", want: "SKU-10442"},
+		{name: "line breaks and tabs", caption: "Batch\r\n42\tshelf  B", want: "Batch 42 shelf B"},
+		{name: "leading and trailing whitespace", caption: " \t SKU \n", want: "SKU"},
+		{name: "control characters", caption: "SKU\x00-\x1b[31m10442\x7f", want: "SKU-[31m10442"},
+		{name: "format characters", caption: "left‮right​", want: "leftright"},
+		{name: "only whitespace", caption: "\n\t ", want: ""},
+		{name: "invalid UTF-8 left for validation", caption: "a\xffb", want: "a\xffb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeCaption(tt.caption); got != tt.want {
+				t.Errorf("SanitizeCaption(%q) = %q, want %q", tt.caption, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateCaptionLimits(t *testing.T) {
+	svc := NewService(testLogger, 64, 2048, 0, 40, 2, 0, false)
+	generate := func(t *testing.T, caption string) (*Result, error) {
+		t.Helper()
+		return svc.Generate(context.Background(), []byte("SKU-10442"), Options{Size: 256, Caption: caption})
+	}
+
+	oneLine, err := generate(t, "SKU-10442")
+	if err != nil {
+		t.Fatalf("Generate() with a short caption error = %v", err)
+	}
+	twoLines, err := generate(t, "Aisle 14 shelf B bin 7 \n\t lot 2026")
+	if err != nil {
+		t.Fatalf("Generate() with a wrapping caption error = %v", err)
+	}
+	if oneLine.Width != twoLines.Width {
+		t.Errorf("width with a wrapped caption = %d, want %d", twoLines.Width, oneLine.Width)
+	}
+	// Each extra line adds the same height: 1.5 times the font size, which is 8%
+	// of the image width.
+	lineHeight := int(math.Ceil(math.Round(256*captionFontScale) * captionLineHeight))
+	if twoLines.Height != oneLine.Height+lineHeight {
+		t.Errorf("height with a caption wrapped onto two lines = %d, want %d", twoLines.Height, oneLine.Height+lineHeight)
+	}
+
+	for _, tt := range []struct {
+		name    string
+		caption string
+		want    string
+	}{
+		{name: "too many characters", caption: strings.Repeat("x", 41), want: "at most 40 characters"},
+		{name: "too many lines", caption: "WWWW WWWW WWWW WWWW WWWW WWWW WWWW WWWW", want: "more than the limit of 2"},
+		{name: "unbreakable word across too many lines", caption: strings.Repeat("W", 40), want: "more than the limit of 2"},
+		{name: "invalid UTF-8", caption: "a\xffb", want: "valid UTF-8"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := generate(t, tt.caption)
+			var captionErr *CaptionError
+			if !errors.As(err, &captionErr) {
+				t.Fatalf("Generate() error = %v, want a *CaptionError", err)
+			}
+			if !strings.Contains(captionErr.Reason, tt.want) {
+				t.Errorf("Generate() reason = %q, want it to contain %q", captionErr.Reason, tt.want)
+			}
+		})
+	}
+}
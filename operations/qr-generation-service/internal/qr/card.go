@@ -0,0 +1,138 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package qr
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+const (
+	// DefaultCardRadius is the default card corner radius in pixels.
+	DefaultCardRadius = 16
+	// DefaultCardPadding is the default space in pixels between the card edge and the QR image.
+	DefaultCardPadding = 24
+	// DefaultCardShadow is the default drop shadow extent in pixels.
+	DefaultCardShadow = 12
+	// MaxCardDimension bounds each card style value in pixels.
+	MaxCardDimension = 256
+
+	// cardShadowAlpha is the opacity of the shadow directly beneath the card.
+	cardShadowAlpha = 0.25
+)
+
+// CardStyle describes the rounded card a QR code is placed on.
+type CardStyle struct {
+	// Radius is the corner radius in pixels. It is reduced if needed so the
+	// corners never clip the QR image.
+	Radius int
+	// Padding is the space in pixels between the card edge and the QR image.
+	Padding int
+	// Shadow is the drop shadow extent in pixels. Zero disables the shadow.
+	Shadow int
+}
+
+// DefaultCardStyle returns the card style used when no values are overridden.
+func DefaultCardStyle() CardStyle {
+	return CardStyle{Radius: DefaultCardRadius, Padding: DefaultCardPadding, Shadow: DefaultCardShadow}
+}
+
+// composeCard places qrImg, quiet zone included, on a white rounded card with an
+// optional soft drop shadow. Pixels outside the card and shadow are transparent.
+func composeCard(qrImg image.Image, style CardStyle) *image.NRGBA {
+	qb := qrImg.Bounds()
+	cardW := qb.Dx() + 2*style.Padding
+	cardH := qb.Dy() + 2*style.Padding
+	margin := style.Shadow
+
+	// A corner arc of radius r stays clear of a point inset p on both axes
+	// while r <= p / (1 - 1/sqrt(2)).
+	radius := float64(style.Radius)
+	if maxRadius := float64(style.Padding) / (1 - 1/math.Sqrt2); radius > maxRadius {
+		radius = maxRadius
+	}
+	radius = math.Min(radius, float64(min(cardW, cardH))/2)
+
+	canvas := image.NewNRGBA(image.Rect(0, 0, cardW+2*margin, cardH+2*margin))
+	card := image.Rect(margin, margin, margin+cardW, margin+cardH)
+
+	if style.Shadow > 0 {
+		// The shadow sits half its extent below the card and fades out over
+		// the other half, so it always fits inside the margin.
+		blur := float64(style.Shadow) / 2
+		shadow := card.Add(image.Pt(0, style.Shadow/2))
+		for y := 0; y < canvas.Rect.Dy(); y++ {
+			for x := 0; x < canvas.Rect.Dx(); x++ {
+				d := roundedRectDistance(float64(x)+0.5, float64(y)+0.5, shadow, radius)
+				coverage := clamp01(1 - d/blur)
+				if coverage > 0 {
+					canvas.SetNRGBA(x, y, color.NRGBA{A: uint8(math.Round(255 * cardShadowAlpha * coverage))})
+				}
+			}
+		}
+	}
+
+	for y := card.Min.Y; y < card.Max.Y; y++ {
+		for x := card.Min.X; x < card.Max.X; x++ {
+			// One pixel of coverage smoothing keeps the card outline clean.
+			d := roundedRectDistance(float64(x)+0.5, float64(y)+0.5, card, radius)
+			coverage := clamp01(0.5 - d)
+			if coverage == 0 {
+				continue
+			}
+			canvas.Set(x, y, blendOver(canvas.NRGBAAt(x, y), coverage))
+		}
+	}
+
+	origin := image.Pt(card.Min.X+style.Padding, card.Min.Y+style.Padding)
+	draw.Draw(canvas, image.Rectangle{Min: origin, Max: origin.Add(qb.Size())}, qrImg, qb.Min, draw.Src)
+
+	return canvas
+}
+
+// roundedRectDistance returns the signed distance from (x, y) to the edge of r
+// with corners rounded by radius. It is negative inside the shape.
+func roundedRectDistance(x, y float64, r image.Rectangle, radius float64) float64 {
+	cx := float64(r.Min.X+r.Max.X) / 2
+	cy := float64(r.Min.Y+r.Max.Y) / 2
+	qx := math.Abs(x-cx) - (float64(r.Dx())/2 - radius)
+	qy := math.Abs(y-cy) - (float64(r.Dy())/2 - radius)
+	outside := math.Hypot(math.Max(qx, 0), math.Max(qy, 0))
+	inside := math.Min(math.Max(qx, qy), 0)
+	return outside + inside - radius
+}
+
+// blendOver composites opaque white with the given coverage over dst.
+func blendOver(dst color.NRGBA, coverage float64) color.NRGBA {
+	srcA := coverage
+	dstA := float64(dst.A) / 255
+	outA := srcA + dstA*(1-srcA)
+	if outA == 0 {
+		return color.NRGBA{}
+	}
+	channel := func(d uint8) uint8 {
+		return uint8(math.Round((255*srcA + float64(d)*dstA*(1-srcA)) / outA))
+	}
+	return color.NRGBA{R: channel(dst.R), G: channel(dst.G), B: channel(dst.B), A: uint8(math.Round(255 * outA))}
+}
+
+// clamp01 limits v to the range [0, 1].
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
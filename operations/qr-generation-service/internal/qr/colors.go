@@ -0,0 +1,177 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package qr
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MinContrastRatio is the lowest WCAG contrast ratio accepted between the
+// background and the foreground or eye colour. Lower ratios scan unreliably.
+const MinContrastRatio = 3.0
+
+// finderSize is the width in modules of a finder pattern ("eye").
+const finderSize = 7
+
+// Colors sets the colours a QR code is drawn with. Nil fields fall back to black
+// foreground, white background, and an eye colour matching the foreground.
+type Colors struct {
+	Foreground color.Color
+	Background color.Color
+	// Eye colours the three finder patterns in the corners of the code.
+	Eye color.Color
+}
+
+// Merge returns c with any nil field taken from base.
+func (c Colors) Merge(base Colors) Colors {
+	if c.Foreground == nil {
+		c.Foreground = base.Foreground
+	}
+	if c.Background == nil {
+		c.Background = base.Background
+	}
+	if c.Eye == nil {
+		c.Eye = base.Eye
+	}
+	return c
+}
+
+// resolve returns the effective foreground, background and eye colours.
+func (c Colors) resolve() (fg, bg, eye color.Color) {
+	fg, bg, eye = c.Foreground, c.Background, c.Eye
+	if fg == nil {
+		fg = color.Black
+	}
+	if bg == nil {
+		bg = color.White
+	}
+	if eye == nil {
+		eye = fg
+	}
+	return fg, bg, eye
+}
+
+// customEye reports whether the finder patterns need a colour of their own.
+func (c Colors) customEye() bool {
+	return c.Eye != nil && c.Eye != c.Foreground
+}
+
+// CheckContrast returns an error if the foreground or eye colour does not contrast
+// with the background by at least MinContrastRatio.
+func (c Colors) CheckContrast() error {
+	fg, bg, eye := c.resolve()
+	if ratio := ContrastRatio(fg, bg); ratio < MinContrastRatio {
+		return fmt.Errorf("foreground/background contrast ratio %.2f is below the minimum of %.1f", ratio, MinContrastRatio)
+	}
+	if ratio := ContrastRatio(eye, bg); ratio < MinContrastRatio {
+		return fmt.Errorf("eye/background contrast ratio %.2f is below the minimum of %.1f", ratio, MinContrastRatio)
+	}
+	return nil
+}
+
+// String formats the effective colours for logging.
+func (c Colors) String() string {
+	fg, bg, eye := c.resolve()
+	return fmt.Sprintf("fg=%s bg=%s eye=%s", FormatHexColor(fg), FormatHexColor(bg), FormatHexColor(eye))
+}
+
+// ParseHexColor parses an opaque colour written as RRGGBB, with or without a leading "#".
+func ParseHexColor(s string) (color.Color, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(hex) != 6 {
+		return nil, fmt.Errorf("color %q must be 6 hex digits (RRGGBB)", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("color %q must be 6 hex digits (RRGGBB)", s)
+	}
+	return color.NRGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}, nil
+}
+
+// FormatHexColor formats c as #RRGGBB, ignoring alpha.
+func FormatHexColor(c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return fmt.Sprintf("#%02x%02x%02x", n.R, n.G, n.B)
+}
+
+// ContrastRatio returns the WCAG 2 contrast ratio between a and b, from 1 to 21.
+func ContrastRatio(a, b color.Color) float64 {
+	la, lb := relativeLuminance(a), relativeLuminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// relativeLuminance returns the WCAG 2 relative luminance of c.
+func relativeLuminance(c color.Color) float64 {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	channel := func(v uint8) float64 {
+		s := float64(v) / 255
+		if s <= 0.03928 {
+			return s / 12.92
+		}
+		return math.Pow((s+0.055)/1.055, 2.4)
+	}
+	return 0.2126*channel(n.R) + 0.7152*channel(n.G) + 0.0722*channel(n.B)
+}
+
+// isFinderModule reports whether the module at row, col of a bitmap modules wide,
+// including a quiet zone border modules wide, belongs to one of the three finder
+// patterns.
+func isFinderModule(row, col, modules, border int) bool {
+	inRange := func(v, start int) bool { return v >= start && v < start+finderSize }
+	near, far := border, modules-border-finderSize
+	return (inRange(row, near) && inRange(col, near)) ||
+		(inRange(row, near) && inRange(col, far)) ||
+		(inRange(row, far) && inRange(col, near))
+}
+
+// applyColors recolours a two- or three-colour QR image drawn by go-qrcode or
+// renderModules. Palette index 0 is the background, 1 the foreground and 2, when
+// present, the finder patterns.
+func applyColors(img image.Image, colors Colors) image.Image {
+	p, ok := img.(*image.Paletted)
+	if !ok {
+		return img
+	}
+	fg, bg, eye := colors.resolve()
+	palette := color.Palette{bg, fg}
+	if len(p.Palette) > 2 {
+		palette = append(palette, eye)
+	}
+	recoloured := *p
+	recoloured.Palette = palette
+	return &recoloured
+}
+
+// clearBackground makes the background of an image recoloured by applyColors
+// fully transparent, leaving the foreground and finder patterns opaque.
+func clearBackground(img image.Image) image.Image {
+	p, ok := img.(*image.Paletted)
+	if !ok {
+		return img
+	}
+	cleared := *p
+	cleared.Palette = append(color.Palette{color.Transparent}, p.Palette[1:]...)
+	return &cleared
+}
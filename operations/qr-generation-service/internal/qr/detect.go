@@ -0,0 +1,115 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package qr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PayloadType classifies text submitted for encoding by the kind of payload
+// phone cameras will recognise it as.
+type PayloadType string
+
+// Payload types returned by DetectPayload.
+const (
+	PayloadWiFi  PayloadType = "wifi"
+	PayloadVCard PayloadType = "vcard"
+	PayloadEmail PayloadType = "email"
+	PayloadPhone PayloadType = "phone"
+	PayloadURL   PayloadType = "url"
+	PayloadText  PayloadType = "text"
+)
+
+// DetectPayload classifies data as a WiFi join string, vCard, mailto: or tel:
+// link, or http(s) URL, ignoring surrounding whitespace and the case of the
+// prefix. Anything else, including prefixes with nothing after them, is
+// PayloadText. Only the shape of data is checked; a payload that is detected
+// is not otherwise validated.
+func DetectPayload(data []byte) PayloadType {
+	text := string(bytes.TrimSpace(data))
+	singleLine := !strings.ContainsAny(text, " \t\r\n")
+
+	switch {
+	case hasPrefixFold(text, "WIFI:") && strings.Contains(strings.ToUpper(text), "S:"):
+		return PayloadWiFi
+	case hasPrefixFold(text, "BEGIN:VCARD") && strings.Contains(strings.ToUpper(text), "END:VCARD"):
+		return PayloadVCard
+	case hasPrefixFold(text, "mailto:") && singleLine && strings.Contains(text, "@"):
+		return PayloadEmail
+	case hasPrefixFold(text, "tel:") && singleLine && len(text) > len("tel:"):
+		return PayloadPhone
+	case singleLine && isWebURL(text):
+		return PayloadURL
+	}
+	return PayloadText
+}
+
+// hasPrefixFold reports whether s begins with prefix, ignoring ASCII case.
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+// isWebURL reports whether s is an absolute http:// or https:// URL with a host.
+func isWebURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	return strings.EqualFold(u.Scheme, "http") || strings.EqualFold(u.Scheme, "https")
+}
+
+// ValidateURL checks that data, ignoring surrounding whitespace, is a
+// well-formed absolute http:// or https:// URL with a host, and returns an error
+// describing the first problem otherwise. It is stricter than DetectPayload,
+// which treats anything that does not parse as plain text.
+func ValidateURL(data []byte) error {
+	text := string(bytes.TrimSpace(data))
+	if text == "" {
+		return fmt.Errorf("data is empty")
+	}
+	if strings.ContainsAny(text, " \t\r\n") {
+		return fmt.Errorf("data contains whitespace")
+	}
+	u, err := url.Parse(text)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("data does not parse as a URL: %v", err)
+	}
+	if u.Scheme == "" {
+		return fmt.Errorf("scheme is missing, the URL must start with http:// or https://")
+	}
+	if !strings.EqualFold(u.Scheme, "http") && !strings.EqualFold(u.Scheme, "https") {
+		return fmt.Errorf("scheme %q must be http or https", u.Scheme)
+	}
+	if u.Opaque != "" || u.Hostname() == "" {
+		return fmt.Errorf("host is required, as in %s://example.com", strings.ToLower(u.Scheme))
+	}
+	if port := u.Port(); port != "" {
+		if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+			return fmt.Errorf("port %s must be between 1 and 65535", port)
+		}
+	}
+	return nil
+}
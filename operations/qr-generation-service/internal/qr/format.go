@@ -0,0 +1,156 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package qr
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"io"
+
+	"golang.org/x/image/tiff"
+)
+
+// Output formats accepted in Options.Format.
+const (
+	FormatPNG  = "png"
+	FormatTIFF = "tiff"
+	// FormatJPEG is lossy. Compression artifacts blur the hard module edges,
+	// so low qualities can make codes harder to scan.
+	FormatJPEG = "jpeg"
+	// FormatSVG is vector output, drawn from the QR matrix by renderSVG rather
+	// than encoded from a raster image.
+	FormatSVG = "svg"
+	// FormatGIF is an animated GIF. Data too long for one code is split into
+	// chunks shown as successive frames; see Chunk for the reassembly format.
+	FormatGIF = "gif"
+	// FormatPDF is a printable single-page PDF holding the raster code at a
+	// physical size; see Options.WidthMM.
+	FormatPDF = "pdf"
+)
+
+// contentTypes maps each output format to its MIME type.
+var contentTypes = map[string]string{
+	FormatPNG:  "image/png",
+	FormatTIFF: "image/tiff",
+	FormatJPEG: "image/jpeg",
+	FormatSVG:  "image/svg+xml",
+	FormatGIF:  "image/gif",
+	FormatPDF:  "application/pdf",
+}
+
+// fileExtensions maps each output format to the file name extension used for
+// downloads.
+var fileExtensions = map[string]string{
+	FormatPNG:  ".png",
+	FormatTIFF: ".tiff",
+	FormatJPEG: ".jpg",
+	FormatSVG:  ".svg",
+	FormatGIF:  ".gif",
+	FormatPDF:  ".pdf",
+}
+
+// FileExtension returns the file name extension, dot included, for format. An
+// empty format means FormatPNG.
+func FileExtension(format string) string {
+	if format == "" {
+		format = FormatPNG
+	}
+	return fileExtensions[format]
+}
+
+// JPEG quality bounds for Options.Quality.
+const (
+	MinJPEGQuality     = 1
+	MaxJPEGQuality     = 100
+	DefaultJPEGQuality = 90
+	// ScannableJPEGQuality is the quality below which artifacts around module
+	// edges may stop codes scanning reliably.
+	ScannableJPEGQuality = 50
+)
+
+// IsSupportedFormat reports whether format is one of Formats.
+func IsSupportedFormat(format string) bool {
+	_, ok := contentTypes[format]
+	return ok
+}
+
+// encodeImage encodes img in the raster format of opts and returns the bytes and
+// MIME type.
+func encodeImage(img image.Image, opts Options) ([]byte, string, error) {
+	var (
+		data []byte
+		err  error
+	)
+	format := opts.format()
+	switch format {
+	case FormatPNG:
+		data, err = encodePNG(img)
+	case FormatTIFF:
+		data, err = encodeTIFF(img)
+	case FormatJPEG:
+		data, err = encodeJPEG(img, opts.quality())
+	case FormatPDF:
+		data, err = encodePDF(img, opts.widthMM())
+	default:
+		return nil, "", fmt.Errorf("unsupported format %q", format)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return data, contentTypes[format], nil
+}
+
+// encodePNG encodes img using the same compression level as go-qrcode.
+func encodePNG(img image.Image) ([]byte, error) {
+	return encodePooled(func(w io.Writer) error {
+		return pngEncoder.Encode(w, img)
+	})
+}
+
+// encodeTIFF encodes img as a Deflate-compressed TIFF. golang.org/x/image/tiff
+// cannot write CCITT Group 4 or LZW, or 1-bit images, so the two-colour QR image
+// is stored as 8-bit palette data. Without PNG's row filters this is typically
+// 10-25x larger than the PNG of the same code.
+func encodeTIFF(img image.Image) ([]byte, error) {
+	return encodePooled(func(w io.Writer) error {
+		return tiff.Encode(w, img, &tiff.Options{Compression: tiff.Deflate})
+	})
+}
+
+// encodeJPEG encodes img as a baseline JPEG at the given quality. JPEG has no
+// alpha channel, so transparent pixels, such as those around a card, are
+// flattened onto white first rather than turning black.
+func encodeJPEG(img image.Image, quality int) ([]byte, error) {
+	return encodePooled(func(w io.Writer) error {
+		return jpeg.Encode(w, flattenOnWhite(img), &jpeg.Options{Quality: quality})
+	})
+}
+
+// flattenOnWhite returns img composited over opaque white, or img itself if it
+// is already opaque.
+func flattenOnWhite(img image.Image) image.Image {
+	if o, ok := img.(interface{ Opaque() bool }); ok && o.Opaque() {
+		return img
+	}
+	b := img.Bounds()
+	flat := image.NewRGBA(b)
+	draw.Draw(flat, b, image.White, image.Point{}, draw.Src)
+	draw.Draw(flat, b, img, b.Min, draw.Over)
+	return flat
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+const (
+	// DefaultLogoScale is the default fraction of the code area covered by a logo.
+	DefaultLogoScale = 0.2
+	// MaxLogoScale is the largest fraction of the code area a logo may cover.
+	MaxLogoScale = 0.3
+	// MaxLogoDimension bounds the width and height in pixels of an uploaded logo.
+	MaxLogoDimension = 4096
+)
+
+// LogoError reports why a logo could not be used.
+type LogoError struct {
+	Reason string
+}
+
+func (e *LogoError) Error() string {
+	return "invalid logo: " + e.Reason
+}
+
+// OverlayLogo decodes a PNG logo from r and draws it over the centre of code, the
+// region of base holding the QR symbol. The logo keeps its aspect ratio and fits a
+// square plate covering scale of the code area. The plate is filled with plate, or
+// left transparent when plate is nil, so modules never touch the logo's edges.
+func OverlayLogo(base image.Image, r io.Reader, code image.Rectangle, scale float64, plate color.Color) (*image.NRGBA, error) {
+	// NaN fails every comparison, so the range is checked in negated form.
+	if !(scale > 0 && scale <= MaxLogoScale) {
+		return nil, &LogoError{Reason: fmt.Sprintf("scale must be greater than 0 and at most %.1f", MaxLogoScale)}
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, &LogoError{Reason: err.Error()}
+	}
+	cfg, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, &LogoError{Reason: "must be a PNG image"}
+	}
+	if cfg.Width == 0 || cfg.Height == 0 || cfg.Width > MaxLogoDimension || cfg.Height > MaxLogoDimension {
+		return nil, &LogoError{Reason: fmt.Sprintf("dimensions must be between 1 and %d pixels", MaxLogoDimension)}
+	}
+	logo, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, &LogoError{Reason: err.Error()}
+	}
+
+	bounds := base.Bounds()
+	out := image.NewNRGBA(bounds)
+	draw.Draw(out, bounds, base, bounds.Min, draw.Src)
+
+	side := int(math.Sqrt(scale * float64(code.Dx()*code.Dy())))
+	centre := code.Min.Add(code.Size().Div(2))
+	box := image.Rect(0, 0, side, side).Add(centre.Sub(image.Pt(side/2, side/2)))
+	if plate != nil {
+		draw.Draw(out, box, image.NewUniform(plate), image.Point{}, draw.Src)
+	}
+
+	// Leave a tenth of the plate as margin and fit the logo inside the rest.
+	inner := box.Inset(side / 10)
+	lb := logo.Bounds()
+	w, h := inner.Dx(), inner.Dy()
+	if lb.Dx()*h > lb.Dy()*w {
+		h = max(1, lb.Dy()*w/lb.Dx())
+	} else {
+		w = max(1, lb.Dx()*h/lb.Dy())
+	}
+	target := image.Rect(0, 0, w, h).Add(inner.Min.Add(image.Pt((inner.Dx()-w)/2, (inner.Dy()-h)/2)))
+	xdraw.CatmullRom.Scale(out, target, logo, lb, draw.Over, nil)
+
+	return out, nil
+}
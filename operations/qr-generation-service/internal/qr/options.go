@@ -0,0 +1,296 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package qr
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	// QuietZone is the width in modules of the border go-qrcode draws around a
+	// code, and the default border width.
+	QuietZone = 4
+	// MaxBorder is the widest border in modules accepted by Generate.
+	MaxBorder = 16
+
+	// MinModuleScale is the smallest module fill fraction accepted by Generate.
+	MinModuleScale = 0.5
+	// ScannableModuleScale is the fill fraction below which codes may become hard to scan.
+	ScannableModuleScale = 0.6
+)
+
+// Symbologies lists the barcode symbologies the service can produce.
+var Symbologies = []string{"qr"}
+
+// Formats lists the output formats Generate can produce.
+var Formats = []string{FormatPNG, FormatTIFF, FormatJPEG, FormatSVG, FormatGIF, FormatPDF}
+
+// Module styles accepted in Options.Style.
+const (
+	StyleSquare  = "square"  // Sharp square modules
+	StyleRounded = "rounded" // Rounded modules that merge into smooth runs
+)
+
+// Styles lists the module styles Generate can draw.
+var Styles = []string{StyleSquare, StyleRounded}
+
+// IsSupportedStyle reports whether style is one of Styles.
+func IsSupportedStyle(style string) bool {
+	return style == StyleSquare || style == StyleRounded
+}
+
+// Error recovery levels accepted in Options.RecoveryLevel, from least to most
+// redundant. Higher levels survive more damage but need a denser code.
+const (
+	RecoveryLow     = "low"     // 7% of codewords can be restored
+	RecoveryMedium  = "medium"  // 15%
+	RecoveryHigh    = "high"    // 25%
+	RecoveryHighest = "highest" // 30%
+)
+
+// RecoveryLevels lists the error recovery levels Generate can apply.
+var RecoveryLevels = []string{RecoveryLow, RecoveryMedium, RecoveryHigh, RecoveryHighest}
+
+// DefaultRecoveryLevel is the error recovery level used when none is requested.
+const DefaultRecoveryLevel = RecoveryMedium
+
+// recoveryLevels maps each recovery level name to its go-qrcode constant.
+var recoveryLevels = map[string]qrcode.RecoveryLevel{
+	RecoveryLow:     qrcode.Low,
+	RecoveryMedium:  qrcode.Medium,
+	RecoveryHigh:    qrcode.High,
+	RecoveryHighest: qrcode.Highest,
+}
+
+// dataCapacity is the most bytes a version 40 symbol holds in byte mode at each
+// recovery level. Numeric and alphanumeric text packs denser, but arbitrary
+// payloads are only guaranteed to fit within these limits.
+var dataCapacity = map[string]int{
+	RecoveryLow:     2953,
+	RecoveryMedium:  2331,
+	RecoveryHigh:    1663,
+	RecoveryHighest: 1273,
+}
+
+// DataCapacity returns the most bytes of data that are guaranteed to fit in a QR
+// code at the given recovery level, or 0 for an unknown level.
+func DataCapacity(level string) int {
+	return dataCapacity[level]
+}
+
+// IsSupportedRecoveryLevel reports whether level is one of RecoveryLevels.
+func IsSupportedRecoveryLevel(level string) bool {
+	_, ok := recoveryLevels[level]
+	return ok
+}
+
+// Options controls how a QR code is rendered.
+type Options struct {
+	// Size is the width and height of the output image in pixels.
+	Size int
+	// ModuleScale is the fraction of each module cell painted for dark modules.
+	// Values below 1 leave a visible gap between modules. Zero means 1 (no gap).
+	ModuleScale float64
+	// Sharp snaps every module to the same whole number of pixels so module
+	// boundaries stay crisp when the image is later resized by other tools.
+	Sharp bool
+	// Style is the module shape, one of Styles. Empty means StyleSquare.
+	// StyleRounded is only drawn for raster formats.
+	Style string
+	// Crop trims the image to the bounding box of its dark modules.
+	Crop bool
+	// CropPadding is the number of quiet-zone modules kept around the content
+	// when Crop is set, from 0 (tightest) up to QuietZone. It is capped at the
+	// border width.
+	CropPadding int
+	// Border, when set, is the quiet zone width in modules, up to MaxBorder. Nil
+	// means QuietZone, the width required by the QR specification; narrower
+	// borders save space but may stop some readers finding the code.
+	Border *int
+	// Caption, when set, is drawn centred beneath the code, below a full quiet
+	// zone, growing the image height to fit. It is cleaned with SanitizeCaption
+	// and wrapped at spaces onto as many lines as the image width needs, within
+	// the service's character and line limits; raster formats only.
+	Caption string
+	// Card, when set, places the QR code on a rounded card background.
+	Card *CardStyle
+	// Format is the output image format, one of Formats. Empty means FormatPNG.
+	Format string
+	// Quality is the JPEG quality from MinJPEGQuality to MaxJPEGQuality, only
+	// valid with FormatJPEG. Zero means DefaultJPEGQuality.
+	Quality int
+	// WidthMM is the printed width of the image in millimetres, from
+	// MinPDFWidthMM to MaxPDFWidthMM, only valid with FormatPDF. Zero means
+	// DefaultPDFWidthMM. Size sets the resolution it is printed at.
+	WidthMM float64
+	// Colors sets the foreground, background and eye colours.
+	Colors Colors
+	// Transparent leaves light modules and the quiet zone fully transparent
+	// instead of filling them with the background colour, which is then only
+	// used for the contrast check. Only valid with FormatPNG and without Card.
+	Transparent bool
+	// RecoveryLevel is the error recovery level, one of RecoveryLevels. Empty
+	// means DefaultRecoveryLevel. It is raised to at least RecoveryHigh when a
+	// logo is set.
+	RecoveryLevel string
+	// Mode is the data encoding mode, one of Modes. Empty means ModeAuto.
+	// ModeByte is not supported with FormatGIF.
+	Mode string
+	// Minimal asks for the smallest QR version that fits the data at
+	// RecoveryLevel. The encoder always picks that version, so Minimal does not
+	// change the output; it rules out a logo, which would raise the recovery
+	// level and with it the version.
+	Minimal bool
+	// Logo, when set, holds a PNG image drawn over the centre of the code.
+	Logo []byte
+	// LogoScale is the fraction of the code area the logo covers, up to
+	// MaxLogoScale. Zero means DefaultLogoScale.
+	LogoScale float64
+}
+
+// recoveryLevel returns the effective recovery level, defaulting to DefaultRecoveryLevel.
+func (o Options) recoveryLevel() string {
+	if o.RecoveryLevel == "" {
+		return DefaultRecoveryLevel
+	}
+	return o.RecoveryLevel
+}
+
+// mode returns the effective data encoding mode, defaulting to ModeAuto.
+func (o Options) mode() string {
+	if o.Mode == "" {
+		return ModeAuto
+	}
+	return o.Mode
+}
+
+// logoScale returns the effective logo scale, defaulting to DefaultLogoScale.
+func (o Options) logoScale() float64 {
+	if o.LogoScale == 0 {
+		return DefaultLogoScale
+	}
+	return o.LogoScale
+}
+
+// atLeastRecovery returns level, raised to min if it is less redundant.
+func atLeastRecovery(level, min string) string {
+	if recoveryLevels[level] < recoveryLevels[min] {
+		return min
+	}
+	return level
+}
+
+// border returns the effective border width in modules, defaulting to QuietZone.
+func (o Options) border() int {
+	if o.Border == nil {
+		return QuietZone
+	}
+	return *o.Border
+}
+
+// style returns the effective module style, defaulting to StyleSquare.
+func (o Options) style() string {
+	if o.Style == "" {
+		return StyleSquare
+	}
+	return o.Style
+}
+
+// format returns the effective output format, defaulting to FormatPNG.
+func (o Options) format() string {
+	if o.Format == "" {
+		return FormatPNG
+	}
+	return o.Format
+}
+
+// quality returns the effective JPEG quality, defaulting to DefaultJPEGQuality.
+func (o Options) quality() int {
+	if o.Quality == 0 {
+		return DefaultJPEGQuality
+	}
+	return o.Quality
+}
+
+// widthMM returns the effective printed width, defaulting to DefaultPDFWidthMM.
+func (o Options) widthMM() float64 {
+	if o.WidthMM == 0 {
+		return DefaultPDFWidthMM
+	}
+	return o.WidthMM
+}
+
+// moduleScale returns the effective module fill fraction, defaulting to 1.
+func (o Options) moduleScale() float64 {
+	if o.ModuleScale == 0 {
+		return 1
+	}
+	return o.ModuleScale
+}
+
+// CacheKey returns a digest of data and every option that affects the generated
+// output, so requests that would produce the same image share a key. Effective
+// values are used, so an unset option and its explicit default match.
+func (o Options) CacheKey(data []byte) string {
+	h := sha256.New()
+	writeBytes(h, data)
+	fmt.Fprintf(h, "size=%d scale=%g sharp=%t crop=%t crop_padding=%d border=%d format=%s ec=%s colors=%s custom_eye=%t",
+		o.Size, o.moduleScale(), o.Sharp, o.Crop, o.CropPadding, o.border(), o.format(), o.recoveryLevel(),
+		o.Colors.String(), o.Colors.customEye())
+	if o.format() == FormatJPEG {
+		fmt.Fprintf(h, " quality=%d", o.quality())
+	}
+	if o.format() == FormatPDF {
+		fmt.Fprintf(h, " width_mm=%g", o.widthMM())
+	}
+	if o.Transparent {
+		fmt.Fprint(h, " transparent")
+	}
+	if o.mode() != ModeAuto {
+		fmt.Fprintf(h, " mode=%s", o.mode())
+	}
+	if o.style() != StyleSquare {
+		fmt.Fprintf(h, " style=%s", o.style())
+	}
+	if o.Caption != "" {
+		fmt.Fprint(h, " caption=")
+		writeBytes(h, []byte(o.Caption))
+	}
+	if o.Card != nil {
+		fmt.Fprintf(h, " card=%d,%d,%d", o.Card.Radius, o.Card.Padding, o.Card.Shadow)
+	}
+	if o.Logo != nil {
+		fmt.Fprintf(h, " logo_scale=%g logo=", o.logoScale())
+		writeBytes(h, o.Logo)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeBytes writes b to h with a length prefix, so adjacent fields cannot run
+// into each other.
+func writeBytes(h hash.Hash, b []byte) {
+	var n [8]byte
+	binary.BigEndian.PutUint64(n[:], uint64(len(b)))
+	h.Write(n[:])
+	h.Write(b)
+}
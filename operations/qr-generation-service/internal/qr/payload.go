@@ -0,0 +1,480 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package qr
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Builders in this file turn structured fields into the text payloads that phone
+// cameras and apps recognise. They validate their input and return an error
+// suitable for showing to the caller when a field is missing or malformed.
+
+var (
+	// upiVPARegex matches a UPI virtual payment address such as "name@bank".
+	upiVPARegex = regexp.MustCompile(`^[a-zA-Z0-9._-]{2,256}@[a-zA-Z][a-zA-Z0-9]{1,63}$`)
+	// upiAmountRegex matches a positive rupee amount with at most two decimals.
+	upiAmountRegex = regexp.MustCompile(`^[0-9]{1,7}(\.[0-9]{1,2})?$`)
+	// smsNumberRegex matches a phone number of digits with an optional leading
+	// "+", once spaces, dashes, dots and parentheses are removed.
+	smsNumberRegex = regexp.MustCompile(`^\+?[0-9]{3,15}$`)
+	// emailAddressRegex matches an address that needs no escaping in a mailto:
+	// URI. It only checks the overall shape, not the rules of RFC 5322.
+	emailAddressRegex = regexp.MustCompile(`^[A-Za-z0-9._%+'-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}$`)
+
+	// wifiEscaper backslash-escapes the characters that are special in a WIFI:
+	// payload.
+	wifiEscaper = strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, `:`, `\:`, `"`, `\"`)
+	// phoneSeparators strips the characters commonly used to group the digits
+	// of a phone number.
+	phoneSeparators = strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "")
+	// vcardEscaper escapes the characters that are special in vCard 3.0 text
+	// values. Line breaks become a literal \n.
+	vcardEscaper = strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+)
+
+// icalTimeLayout is the iCalendar UTC date-time form (RFC 5545 section 3.3.5).
+const icalTimeLayout = "20060102T150405Z"
+
+// vcardLineLength is the longest vCard content line in octets before folding,
+// excluding the CRLF, as recommended by RFC 2425.
+const vcardLineLength = 75
+
+// WiFi encryption types accepted by WiFiPayload.
+const (
+	WiFiWPA    = "WPA"
+	WiFiWEP    = "WEP"
+	WiFiNoPass = "nopass"
+)
+
+// maxSSIDLength is the longest SSID allowed by IEEE 802.11, in bytes.
+const maxSSIDLength = 32
+
+// UPIPayment holds the fields of a UPI payment request.
+type UPIPayment struct {
+	VPA    string `json:"vpa"`
+	Name   string `json:"name"`
+	Amount string `json:"amount,omitempty"`
+	Note   string `json:"note,omitempty"`
+}
+
+// UPIPayload builds a upi://pay URI from p. The payee VPA and name are required;
+// amount is optional so that the payer can enter it, and is always sent in INR.
+func UPIPayload(p UPIPayment) (string, error) {
+	vpa := strings.TrimSpace(p.VPA)
+	name := strings.TrimSpace(p.Name)
+	amount := strings.TrimSpace(p.Amount)
+	note := strings.TrimSpace(p.Note)
+
+	if vpa == "" {
+		return "", fmt.Errorf("vpa is required")
+	}
+	if !upiVPARegex.MatchString(vpa) {
+		return "", fmt.Errorf("vpa %q is not a valid UPI address (expected name@bank)", vpa)
+	}
+	if name == "" {
+		return "", fmt.Errorf("name is required")
+	}
+	if amount != "" {
+		v, err := strconv.ParseFloat(amount, 64)
+		if !upiAmountRegex.MatchString(amount) || err != nil || v <= 0 {
+			return "", fmt.Errorf("amount %q must be a positive number with at most two decimal places", amount)
+		}
+	}
+
+	var b strings.Builder
+	// The VPA is restricted to query-safe characters by upiVPARegex, and apps
+	// expect a literal "@", so it is written as-is.
+	b.WriteString("upi://pay?pa=")
+	b.WriteString(vpa)
+	b.WriteString("&pn=")
+	b.WriteString(upiEscape(name))
+	if amount != "" {
+		b.WriteString("&am=")
+		b.WriteString(amount)
+	}
+	b.WriteString("&cu=INR")
+	if note != "" {
+		b.WriteString("&tn=")
+		b.WriteString(upiEscape(note))
+	}
+	return b.String(), nil
+}
+
+// upiEscape percent-encodes a UPI query value. UPI apps expect %20 for spaces
+// rather than the "+" produced by form encoding.
+func upiEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// WiFiNetwork holds the fields of a WiFi network join request.
+type WiFiNetwork struct {
+	SSID       string `json:"ssid"`
+	Password   string `json:"password,omitempty"`
+	Encryption string `json:"encryption"`
+	Hidden     bool   `json:"hidden,omitempty"`
+}
+
+// WiFiPayload builds a WIFI: string from n that phone cameras offer to join. The
+// SSID and password are used verbatim, since spaces are significant in both.
+// Encryption is WPA (which also covers WPA2 and WPA3), WEP or nopass; a password
+// is required for WPA and WEP and must be omitted for nopass.
+func WiFiPayload(n WiFiNetwork) (string, error) {
+	if n.SSID == "" {
+		return "", fmt.Errorf("ssid is required")
+	}
+	if len(n.SSID) > maxSSIDLength {
+		return "", fmt.Errorf("ssid must be at most %d bytes", maxSSIDLength)
+	}
+
+	var encryption string
+	switch {
+	case strings.EqualFold(n.Encryption, WiFiWPA):
+		encryption = WiFiWPA
+	case strings.EqualFold(n.Encryption, WiFiWEP):
+		encryption = WiFiWEP
+	case strings.EqualFold(n.Encryption, WiFiNoPass):
+		encryption = WiFiNoPass
+	case n.Encryption == "":
+		return "", fmt.Errorf("encryption is required (one of %s, %s, %s)", WiFiWPA, WiFiWEP, WiFiNoPass)
+	default:
+		return "", fmt.Errorf("encryption %q must be one of %s, %s, %s", n.Encryption, WiFiWPA, WiFiWEP, WiFiNoPass)
+	}
+
+	switch {
+	case encryption == WiFiNoPass && n.Password != "":
+		return "", fmt.Errorf("password must be omitted when encryption is %s", WiFiNoPass)
+	case encryption != WiFiNoPass && n.Password == "":
+		return "", fmt.Errorf("password is required when encryption is %s", encryption)
+	case encryption == WiFiWPA && (len(n.Password) < 8 || len(n.Password) > 63):
+		return "", fmt.Errorf("password must be 8-63 characters for %s", WiFiWPA)
+	}
+
+	var b strings.Builder
+	b.WriteString("WIFI:T:")
+	b.WriteString(encryption)
+	b.WriteString(";S:")
+	b.WriteString(wifiEscaper.Replace(n.SSID))
+	if n.Password != "" {
+		b.WriteString(";P:")
+		b.WriteString(wifiEscaper.Replace(n.Password))
+	}
+	if n.Hidden {
+		b.WriteString(";H:true")
+	}
+	b.WriteString(";;")
+	return b.String(), nil
+}
+
+// VCardContact holds the fields of a contact card.
+type VCardContact struct {
+	Name    string `json:"name"`
+	Org     string `json:"org,omitempty"`
+	Title   string `json:"title,omitempty"`
+	Phone   string `json:"phone,omitempty"`
+	Email   string `json:"email,omitempty"`
+	URL     string `json:"url,omitempty"`
+	Address string `json:"address,omitempty"`
+}
+
+// VCardPayload builds a vCard 3.0 (RFC 2426) card from c. The name is required,
+// as is at least one of phone or email. Text values are escaped, line breaks in
+// the address are kept as escaped newlines, and long lines are folded.
+func VCardPayload(c VCardContact) (string, error) {
+	name := strings.TrimSpace(c.Name)
+	org := strings.TrimSpace(c.Org)
+	title := strings.TrimSpace(c.Title)
+	phone := strings.TrimSpace(c.Phone)
+	email := strings.TrimSpace(c.Email)
+	uri := strings.TrimSpace(c.URL)
+	address := strings.TrimSpace(c.Address)
+
+	if name == "" {
+		return "", fmt.Errorf("name is required")
+	}
+	if phone == "" && email == "" {
+		return "", fmt.Errorf("phone or email is required")
+	}
+	for _, field := range []struct{ name, value string }{
+		{"name", name}, {"org", org}, {"title", title}, {"phone", phone}, {"email", email}, {"url", uri},
+	} {
+		if strings.ContainsAny(field.value, "\r\n") {
+			return "", fmt.Errorf("%s must be a single line", field.name)
+		}
+	}
+	if email != "" && !strings.Contains(email, "@") {
+		return "", fmt.Errorf("email %q is not a valid address", email)
+	}
+
+	// The structured name is split on the last space, which suits the common
+	// "Given Family" form; FN carries the name exactly as given.
+	given, family := "", name
+	if i := strings.LastIndexByte(name, ' '); i > 0 {
+		given, family = strings.TrimSpace(name[:i]), name[i+1:]
+	}
+
+	lines := []string{
+		"BEGIN:VCARD",
+		"VERSION:3.0",
+		"N:" + vcardEscaper.Replace(family) + ";" + vcardEscaper.Replace(given) + ";;;",
+		"FN:" + vcardEscaper.Replace(name),
+	}
+	if org != "" {
+		lines = append(lines, "ORG:"+vcardEscaper.Replace(org))
+	}
+	if title != "" {
+		lines = append(lines, "TITLE:"+vcardEscaper.Replace(title))
+	}
+	if phone != "" {
+		lines = append(lines, "TEL;TYPE=WORK,VOICE:"+vcardEscaper.Replace(phone))
+	}
+	if email != "" {
+		lines = append(lines, "EMAIL;TYPE=INTERNET:"+vcardEscaper.Replace(email))
+	}
+	if uri != "" {
+		// URL is a URI value, not text, so commas and semicolons are kept.
+		lines = append(lines, "URL:"+uri)
+	}
+	if address != "" {
+		// The whole address goes in the street component, which readers show
+		// as-is, rather than guessing at locality and postal code.
+		lines = append(lines, "ADR;TYPE=WORK:;;"+vcardEscaper.Replace(address)+";;;;")
+	}
+	lines = append(lines, "END:VCARD")
+
+	var b strings.Builder
+	for _, line := range lines {
+		writeFolded(&b, line)
+	}
+	return b.String(), nil
+}
+
+// writeFolded writes a vCard or iCalendar content line to b, folding it into CRLF-terminated
+// chunks of at most vcardLineLength octets. Continuation lines start with a
+// space, and multi-byte UTF-8 characters are never split.
+func writeFolded(b *strings.Builder, line string) {
+	limit := vcardLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space of a continuation line counts toward its length.
+		limit = vcardLineLength - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+// SMSMessage holds the fields of a pre-filled SMS.
+type SMSMessage struct {
+	Number  string `json:"number"`
+	Message string `json:"message,omitempty"`
+}
+
+// SMSPayload builds an SMSTO: string from m that phones open as a new message to
+// the number with the text filled in. The number is required and its spaces,
+// dashes, dots and parentheses are removed; the message is optional and used
+// verbatim, since everything after the second colon is message text.
+func SMSPayload(m SMSMessage) (string, error) {
+	number := strings.TrimSpace(m.Number)
+	if number == "" {
+		return "", fmt.Errorf("number is required")
+	}
+	digits := phoneSeparators.Replace(number)
+	if !smsNumberRegex.MatchString(digits) {
+		return "", fmt.Errorf("number %q must be 3-15 digits with an optional leading +", number)
+	}
+	return "SMSTO:" + digits + ":" + m.Message, nil
+}
+
+// EmailMessage holds the fields of a pre-filled email.
+type EmailMessage struct {
+	To      string `json:"to"`
+	Subject string `json:"subject,omitempty"`
+	Body    string `json:"body,omitempty"`
+}
+
+// EmailPayload builds a mailto: URI (RFC 6068) from m. To is required and may
+// list several addresses separated by commas. Subject and body are optional and
+// percent-encoded, with line breaks in the body sent as CRLF.
+func EmailPayload(m EmailMessage) (string, error) {
+	var to []string
+	for _, addr := range strings.Split(m.To, ",") {
+		addr = strings.TrimSpace(addr)
+		if addr == "" {
+			continue
+		}
+		if !emailAddressRegex.MatchString(addr) {
+			return "", fmt.Errorf("to address %q is not a valid email address", addr)
+		}
+		to = append(to, addr)
+	}
+	if len(to) == 0 {
+		return "", fmt.Errorf("to is required")
+	}
+	subject := strings.TrimSpace(m.Subject)
+	if strings.ContainsAny(subject, "\r\n") {
+		return "", fmt.Errorf("subject must be a single line")
+	}
+
+	var b strings.Builder
+	b.WriteString("mailto:")
+	b.WriteString(strings.Join(to, ","))
+	sep := "?"
+	if subject != "" {
+		b.WriteString(sep + "subject=")
+		b.WriteString(mailtoEscape(subject))
+		sep = "&"
+	}
+	if m.Body != "" {
+		body := strings.ReplaceAll(strings.ReplaceAll(m.Body, "\r\n", "\n"), "\n", "\r\n")
+		b.WriteString(sep + "body=")
+		b.WriteString(mailtoEscape(body))
+	}
+	return b.String(), nil
+}
+
+// mailtoEscape percent-encodes a mailto: header value. RFC 6068 requires %20 for
+// spaces, as "+" would be read as a literal plus sign.
+func mailtoEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// CalendarEvent holds the fields of a calendar event. Start and end are RFC 3339
+// timestamps, such as "2026-05-01T09:00:00+05:30".
+type CalendarEvent struct {
+	Summary     string `json:"summary"`
+	Start       string `json:"start"`
+	End         string `json:"end"`
+	Location    string `json:"location,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// EventPayload builds an iCalendar VEVENT (RFC 5545) from e that phone cameras
+// offer to add to the calendar. The summary, start and end are required, and
+// start must be before end. Times are written in UTC, text values are escaped
+// with line breaks in the description kept as escaped newlines, and long lines
+// are folded.
+func EventPayload(e CalendarEvent) (string, error) {
+	summary := strings.TrimSpace(e.Summary)
+	location := strings.TrimSpace(e.Location)
+	description := strings.TrimSpace(e.Description)
+
+	if summary == "" {
+		return "", fmt.Errorf("summary is required")
+	}
+	for _, field := range []struct{ name, value string }{
+		{"summary", summary}, {"location", location},
+	} {
+		if strings.ContainsAny(field.value, "\r\n") {
+			return "", fmt.Errorf("%s must be a single line", field.name)
+		}
+	}
+	start, err := parseEventTime("start", e.Start)
+	if err != nil {
+		return "", err
+	}
+	end, err := parseEventTime("end", e.End)
+	if err != nil {
+		return "", err
+	}
+	if !start.Before(end) {
+		return "", fmt.Errorf("start must be before end")
+	}
+
+	// iCalendar escapes TEXT values the same way as vCard 3.0.
+	lines := []string{
+		"BEGIN:VEVENT",
+		"SUMMARY:" + vcardEscaper.Replace(summary),
+		"DTSTART:" + start.UTC().Format(icalTimeLayout),
+		"DTEND:" + end.UTC().Format(icalTimeLayout),
+	}
+	if location != "" {
+		lines = append(lines, "LOCATION:"+vcardEscaper.Replace(location))
+	}
+	if description != "" {
+		lines = append(lines, "DESCRIPTION:"+vcardEscaper.Replace(description))
+	}
+	lines = append(lines, "END:VEVENT")
+
+	var b strings.Builder
+	for _, line := range lines {
+		writeFolded(&b, line)
+	}
+	return b.String(), nil
+}
+
+// parseEventTime parses the RFC 3339 timestamp value of the named field.
+func parseEventTime(field, value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("%s is required", field)
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s %q must be an RFC 3339 timestamp such as 2026-05-01T09:00:00Z", field, value)
+	}
+	return t, nil
+}
+
+// GeoLocation holds the fields of a map location. Lat and Lng are pointers so
+// that a missing coordinate can be told apart from zero.
+type GeoLocation struct {
+	Lat      *float64 `json:"lat"`
+	Lng      *float64 `json:"lng"`
+	Altitude *float64 `json:"altitude,omitempty"`
+}
+
+// GeoPayload builds a geo: URI (RFC 5870) from l that phones open as a pin in
+// their maps app. Latitude must be within -90..90 and longitude within
+// -180..180 degrees; the altitude, in metres, is optional.
+func GeoPayload(l GeoLocation) (string, error) {
+	if l.Lat == nil {
+		return "", fmt.Errorf("lat is required")
+	}
+	if l.Lng == nil {
+		return "", fmt.Errorf("lng is required")
+	}
+	lat, lng := *l.Lat, *l.Lng
+	if lat < -90 || lat > 90 {
+		return "", fmt.Errorf("lat %v must be between -90 and 90", lat)
+	}
+	if lng < -180 || lng > 180 {
+		return "", fmt.Errorf("lng %v must be between -180 and 180", lng)
+	}
+
+	var b strings.Builder
+	b.WriteString("geo:")
+	b.WriteString(strconv.FormatFloat(lat, 'f', -1, 64))
+	b.WriteString(",")
+	b.WriteString(strconv.FormatFloat(lng, 'f', -1, 64))
+	if l.Altitude != nil {
+		b.WriteString(",")
+		b.WriteString(strconv.FormatFloat(*l.Altitude, 'f', -1, 64))
+	}
+	return b.String(), nil
+}
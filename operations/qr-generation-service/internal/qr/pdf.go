@@ -0,0 +1,418 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package qr
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Physical size bounds for PDF output.
+const (
+	// DefaultPDFWidthMM is the printed width of a code, quiet zone included,
+	// when none is requested.
+	DefaultPDFWidthMM = 40.0
+	MinPDFWidthMM     = 5.0
+	// MaxPDFWidthMM is the printable width of an A4 sheet inside its margins,
+	// so that any code also fits on a tiled sheet.
+	MaxPDFWidthMM = sheetWidthMM - 2*sheetMarginMM
+
+	// DefaultPDFDPI is the resolution codes are rasterized at for PDF output.
+	DefaultPDFDPI = 300
+	MinPDFDPI     = 72
+	MaxPDFDPI     = 1200
+)
+
+// Paper sizes PDFSheet lays codes out on.
+const (
+	PageA4     = "a4"
+	PageLetter = "letter"
+)
+
+// PageSizes lists the supported PageA4 and PageLetter paper sizes.
+var PageSizes = []string{PageA4, PageLetter}
+
+// pageSizesMM holds the width and height of each paper size in millimetres.
+var pageSizesMM = map[string][2]float64{
+	PageA4:     {210, 297},
+	PageLetter: {215.9, 279.4},
+}
+
+// IsSupportedPageSize reports whether page is one of PageSizes.
+func IsSupportedPageSize(page string) bool {
+	return slices.Contains(PageSizes, page)
+}
+
+// Layout of the sheets written by PDFSheet, in millimetres.
+const (
+	// sheetWidthMM is the width of the narrowest page size, A4.
+	sheetWidthMM  = 210.0
+	sheetMarginMM = 10.0
+	sheetGapMM    = 5.0
+	// sheetLabelMM is the space under each code for its label: a 1 mm gap and
+	// one line of text.
+	sheetLabelMM = 4.0
+)
+
+// Label text of PDFSheet, in points. Labels are set in Courier, whose glyphs
+// are all labelAdvance em wide, so their width is known without font metrics.
+const (
+	labelFontPt    = 7.0
+	minLabelFontPt = 4.0
+	labelAdvance   = 0.6
+)
+
+// pointsPerMM converts millimetres to PDF points, which are 1/72 inch.
+const pointsPerMM = 72 / 25.4
+
+// PDFPixels returns the image width in pixels that prints widthMM millimetres
+// wide at dpi dots per inch.
+func PDFPixels(widthMM float64, dpi int) int {
+	return int(math.Round(widthMM / 25.4 * float64(dpi)))
+}
+
+// pdfPlacement positions one image on a page. Coordinates are in points from
+// the bottom-left corner of the page, as PDF expects.
+type pdfPlacement struct {
+	image         int
+	x, y          float64
+	width, height float64
+}
+
+// pdfLabel is one line of text on a page. text is WinAnsi encoded, and x and y
+// place the start of its baseline, in points from the bottom-left corner.
+type pdfLabel struct {
+	text     []byte
+	x, y     float64
+	fontSize float64
+}
+
+// pdfPage is one page of a PDF document.
+type pdfPage struct {
+	width, height float64
+	placements    []pdfPlacement
+	labels        []pdfLabel
+}
+
+// encodePDF returns a single-page PDF holding img printed widthMM millimetres
+// wide. The page is cropped to the image, so it prints at exactly that size.
+func encodePDF(img image.Image, widthMM float64) ([]byte, error) {
+	b := img.Bounds()
+	width := widthMM * pointsPerMM
+	height := width * float64(b.Dy()) / float64(b.Dx())
+	return writePDF([]image.Image{img}, []pdfPage{{
+		width:      width,
+		height:     height,
+		placements: []pdfPlacement{{image: 0, width: width, height: height}},
+	}})
+}
+
+// SheetCell is one code of a sheet written by PDFSheet.
+type SheetCell struct {
+	// PNG holds the code image, which is expected to be square.
+	PNG []byte
+	// Label is printed centred beneath the code, shrunk and then cut short with
+	// an ellipsis if it is too wide. Characters the WinAnsi encoding lacks, which
+	// covers Latin-1 and common punctuation, print as "?".
+	Label string
+}
+
+// SheetLayout arranges the codes of a sheet written by PDFSheet.
+type SheetLayout struct {
+	// WidthMM is the printed width of every code, from MinPDFWidthMM to
+	// MaxPDFWidthMM.
+	WidthMM float64
+	// Page is the paper size, one of PageSizes. Empty means PageA4.
+	Page string
+	// Columns and Rows, when set, limit the grid on each page to fewer codes
+	// than the most that fit, as reported by SheetGrid.
+	Columns, Rows int
+}
+
+// page returns the paper size, applying the default if unset.
+func (l SheetLayout) page() string {
+	if l.Page == "" {
+		return PageA4
+	}
+	return l.Page
+}
+
+// SheetGrid returns the most columns and rows of labelled codes, each printed
+// widthMM millimetres wide, that fit inside the margins of page.
+func SheetGrid(page string, widthMM float64) (columns, rows int) {
+	size := pageSizesMM[page]
+	columns = int((size[0] - 2*sheetMarginMM + sheetGapMM) / (widthMM + sheetGapMM))
+	rows = int((size[1] - 2*sheetMarginMM + sheetGapMM) / (widthMM + sheetLabelMM + sheetGapMM))
+	return columns, rows
+}
+
+// PDFSheet tiles the codes in cells, each with its label beneath, left to right
+// and top to bottom across as many pages as needed, and returns the PDF
+// document.
+func PDFSheet(cells []SheetCell, layout SheetLayout) ([]byte, error) {
+	widthMM := layout.WidthMM
+	if !(widthMM >= MinPDFWidthMM && widthMM <= MaxPDFWidthMM) {
+		return nil, fmt.Errorf("invalid width: must be between %gmm and %gmm", MinPDFWidthMM, MaxPDFWidthMM)
+	}
+	if !IsSupportedPageSize(layout.page()) {
+		return nil, fmt.Errorf("invalid page size: must be one of %s", strings.Join(PageSizes, ", "))
+	}
+	columns, rows := SheetGrid(layout.page(), widthMM)
+	if layout.Columns < 0 || layout.Columns > columns || layout.Rows < 0 || layout.Rows > rows {
+		return nil, fmt.Errorf("invalid grid: at most %d columns and %d rows fit", columns, rows)
+	}
+	if layout.Columns > 0 {
+		columns = layout.Columns
+	}
+	if layout.Rows > 0 {
+		rows = layout.Rows
+	}
+
+	images := make([]image.Image, len(cells))
+	for i, cell := range cells {
+		img, err := png.Decode(bytes.NewReader(cell.PNG))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode image %d: %w", i, err)
+		}
+		images[i] = img
+	}
+
+	pageSize := pageSizesMM[layout.page()]
+	perPage := columns * rows
+	cell := widthMM * pointsPerMM
+
+	var pages []pdfPage
+	for i := range images {
+		if i%perPage == 0 {
+			pages = append(pages, pdfPage{width: pageSize[0] * pointsPerMM, height: pageSize[1] * pointsPerMM})
+		}
+		slot := i % perPage
+		row, col := slot/columns, slot%columns
+		x := sheetMarginMM + float64(col)*(widthMM+sheetGapMM)
+		top := sheetMarginMM + float64(row)*(widthMM+sheetLabelMM+sheetGapMM)
+		page := &pages[len(pages)-1]
+		y := (pageSize[1]-top)*pointsPerMM - cell
+		page.placements = append(page.placements, pdfPlacement{
+			image:  i,
+			x:      x * pointsPerMM,
+			y:      y,
+			width:  cell,
+			height: cell,
+		})
+		if text, fontSize := fitLabel(cells[i].Label, cell); len(text) > 0 {
+			page.labels = append(page.labels, pdfLabel{
+				text: text,
+				x:    x*pointsPerMM + (cell-float64(len(text))*labelAdvance*fontSize)/2,
+				// The baseline sits below the 1 mm gap and the cap height of
+				// the largest label font.
+				y:        y - 1*pointsPerMM - 0.8*labelFontPt,
+				fontSize: fontSize,
+			})
+		}
+	}
+	return writePDF(images, pages)
+}
+
+// winAnsiPunctuation maps the characters WinAnsi places in 0x80-0x9F, where
+// Latin-1 has control codes, to their codes.
+var winAnsiPunctuation = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, '‰': 0x89,
+	'‹': 0x8b, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96,
+	'—': 0x97, '™': 0x99, '›': 0x9b,
+}
+
+// fitLabel returns label WinAnsi encoded, and the font size that fits it in
+// width points. Labels that do not fit at minLabelFontPt are cut short with an
+// ellipsis.
+func fitLabel(label string, width float64) ([]byte, float64) {
+	var text []byte
+	for _, r := range label {
+		switch {
+		// WinAnsi matches Latin-1 for printable ASCII and from U+00A0 on.
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			text = append(text, byte(r))
+		case winAnsiPunctuation[r] != 0:
+			text = append(text, winAnsiPunctuation[r])
+		default:
+			text = append(text, '?')
+		}
+	}
+	if len(text) == 0 {
+		return nil, 0
+	}
+	fontSize := math.Min(labelFontPt, width/(float64(len(text))*labelAdvance))
+	if fontSize >= minLabelFontPt {
+		return text, fontSize
+	}
+	fit := int(width / (minLabelFontPt * labelAdvance))
+	if fit < 1 {
+		return nil, 0
+	}
+	// 0x85 is the WinAnsi ellipsis.
+	return append(text[:fit-1:fit-1], 0x85), minLabelFontPt
+}
+
+// writePDF writes a PDF document with the given pages, drawing images as
+// lossless RGB image objects. Transparent pixels are flattened onto white.
+func writePDF(images []image.Image, pages []pdfPage) ([]byte, error) {
+	var buf bytes.Buffer
+	// offsets[n] is the byte offset of object n+1, for the cross-reference table.
+	var offsets []int
+	object := func(body func()) int {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n", len(offsets))
+		body()
+		buf.WriteString("\nendobj\n")
+		return len(offsets)
+	}
+	stream := func(dict string, data []byte) int {
+		return object(func() {
+			fmt.Fprintf(&buf, "<< %s /Length %d >>\nstream\n", dict, len(data))
+			buf.Write(data)
+			buf.WriteString("\nendstream")
+		})
+	}
+
+	// The binary comment marks the file as binary for transfer tools.
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1 and 2 are the catalog and page tree, which refer to the
+	// pages by the numbers they are given below.
+	firstPage := 3 + len(images) + len(pages)
+	object(func() { buf.WriteString("<< /Type /Catalog /Pages 2 0 R >>") })
+	object(func() {
+		buf.WriteString("<< /Type /Pages /Kids [")
+		for i := range pages {
+			fmt.Fprintf(&buf, " %d 0 R", firstPage+i)
+		}
+		fmt.Fprintf(&buf, " ] /Count %d >>", len(pages))
+	})
+
+	for _, img := range images {
+		data, err := deflateRGB(img)
+		if err != nil {
+			return nil, err
+		}
+		b := img.Bounds()
+		// Interpolation would blur module edges when the viewer scales the image.
+		stream(fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Interpolate false /Filter /FlateDecode",
+			b.Dx(), b.Dy()), data)
+	}
+
+	for _, page := range pages {
+		var content bytes.Buffer
+		for _, p := range page.placements {
+			fmt.Fprintf(&content, "q %s 0 0 %s %s %s cm /Im%d Do Q\n",
+				pdfNum(p.width), pdfNum(p.height), pdfNum(p.x), pdfNum(p.y), p.image)
+		}
+		for _, l := range page.labels {
+			fmt.Fprintf(&content, "BT /F1 %s Tf %s %s Td (%s) Tj ET\n",
+				pdfNum(l.fontSize), pdfNum(l.x), pdfNum(l.y), pdfString(l.text))
+		}
+		stream("", content.Bytes())
+	}
+
+	// The label font, when any page has labels, follows the pages.
+	font := firstPage + len(pages)
+	for i, page := range pages {
+		object(func() {
+			fmt.Fprintf(&buf, "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /XObject <<",
+				pdfNum(page.width), pdfNum(page.height))
+			for _, p := range page.placements {
+				fmt.Fprintf(&buf, " /Im%d %d 0 R", p.image, 3+p.image)
+			}
+			buf.WriteString(" >>")
+			if len(page.labels) > 0 {
+				fmt.Fprintf(&buf, " /Font << /F1 %d 0 R >>", font)
+			}
+			fmt.Fprintf(&buf, " >> /Contents %d 0 R >>", 3+len(images)+i)
+		})
+	}
+	if slices.ContainsFunc(pages, func(p pdfPage) bool { return len(p.labels) > 0 }) {
+		// Courier is one of the standard fonts every PDF reader has, so it is
+		// not embedded.
+		object(func() {
+			buf.WriteString("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+		})
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.Bytes(), nil
+}
+
+// deflateRGB returns the pixels of img as zlib-compressed 8-bit RGB samples,
+// flattened onto white.
+func deflateRGB(img image.Image) ([]byte, error) {
+	img = flattenOnWhite(img)
+	b := img.Bounds()
+	var buf bytes.Buffer
+	zw, err := zlib.NewWriterLevel(&buf, zlib.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	row := make([]byte, 3*b.Dx())
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+			i := 3 * (x - b.Min.X)
+			row[i], row[i+1], row[i+2] = c.R, c.G, c.B
+		}
+		if _, err := zw.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// pdfString escapes text for a PDF literal string, writing bytes outside
+// printable ASCII as octal escapes.
+func pdfString(text []byte) string {
+	var b strings.Builder
+	for _, c := range text {
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c >= 0x7f:
+			fmt.Fprintf(&b, "\\%03o", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// pdfNum formats v for a PDF content stream with at most three decimals.
+func pdfNum(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1000)/1000, 'f', -1, 64)
+}
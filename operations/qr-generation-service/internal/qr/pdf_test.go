@@ -0,0 +1,111 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package qr
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestFitLabel(t *testing.T) {
+	tests := []struct {
+		name     string
+		label    string
+		width    float64
+		want     string
+		fontSize float64
+	}{
+		{name: "fits at full size", label: "bin-A01", width: 100, want: "bin-A01", fontSize: labelFontPt},
+		{name: "shrunk to fit", label: "0123456789abcdefghij", width: 60, want: "0123456789abcdefghij", fontSize: 5},
+		{name: "cut short below the smallest size", label: strings.Repeat("x", 30), width: 24, want: "xxxxxxxxx\x85", fontSize: minLabelFontPt},
+		{name: "latin-1 and punctuation", label: "café – ☃", width: 100, want: "caf\xe9 \x96 ?", fontSize: labelFontPt},
+		{name: "empty", label: "", width: 100, want: "", fontSize: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, fontSize := fitLabel(tt.label, tt.width)
+			if string(text) != tt.want || fontSize != tt.fontSize {
+				t.Errorf("fitLabel(%q, %g) = %q at %gpt, want %q at %gpt", tt.label, tt.width, text, fontSize, tt.want, tt.fontSize)
+			}
+		})
+	}
+}
+
+func TestPDFSheetLayout(t *testing.T) {
+	svc := NewService(testLogger, 64, 2048, 0, DefaultCaptionMaxChars, DefaultCaptionMaxLines, 0, false)
+	result, err := svc.Generate(context.Background(), []byte("BIN-A01"), Options{Size: 128})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cells := make([]SheetCell, 7)
+	for i := range cells {
+		cells[i] = SheetCell{PNG: result.Image, Label: "bin (A)"}
+	}
+
+	pages := regexp.MustCompile(`/Type /Page /Parent 2 0 R /MediaBox \[0 0 ([\d.]+) ([\d.]+)\]`)
+	tests := []struct {
+		name      string
+		layout    SheetLayout
+		pages     int
+		mediaBox  string
+		wantError bool
+	}{
+		{name: "a4 default grid", layout: SheetLayout{WidthMM: 40}, pages: 1, mediaBox: "595.276 841.89"},
+		{name: "letter", layout: SheetLayout{WidthMM: 40, Page: PageLetter}, pages: 1, mediaBox: "612 792"},
+		{name: "limited grid", layout: SheetLayout{WidthMM: 40, Columns: 2, Rows: 2}, pages: 2, mediaBox: "595.276 841.89"},
+		{name: "too many columns", layout: SheetLayout{WidthMM: 40, Columns: 5}, wantError: true},
+		{name: "unknown page", layout: SheetLayout{WidthMM: 40, Page: "a3"}, wantError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pdf, err := PDFSheet(cells, tt.layout)
+			if tt.wantError {
+				if err == nil {
+					t.Fatal("PDFSheet() error = nil, want an invalid layout error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PDFSheet() error = %v", err)
+			}
+			found := pages.FindAllSubmatch(pdf, -1)
+			if len(found) != tt.pages {
+				t.Fatalf("PDF has %d pages, want %d", len(found), tt.pages)
+			}
+			if got := string(found[0][1]) + " " + string(found[0][2]); got != tt.mediaBox {
+				t.Errorf("MediaBox = %s, want %s", got, tt.mediaBox)
+			}
+			if n := bytes.Count(pdf, []byte(`(bin \(A\)) Tj`)); n != len(cells) {
+				t.Errorf("PDF has %d escaped labels, want %d", n, len(cells))
+			}
+		})
+	}
+}
+
+func TestSheetGrid(t *testing.T) {
+	// 4 columns of 40 mm codes with 5 mm gaps fill 175 of A4's 190 mm, and rows
+	// also take the label under each code.
+	if columns, rows := SheetGrid(PageA4, 40); columns != 4 || rows != 5 {
+		t.Errorf("SheetGrid(a4, 40) = %d, %d, want 4, 5", columns, rows)
+	}
+	if columns, rows := SheetGrid(PageLetter, MaxPDFWidthMM); columns != 1 || rows != 1 {
+		t.Errorf("SheetGrid(letter, %g) = %d, %d, want 1, 1", MaxPDFWidthMM, columns, rows)
+	}
+}
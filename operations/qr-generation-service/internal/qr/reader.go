@@ -0,0 +1,172 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package qr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"log/slog"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/qrcode"
+
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/timing"
+)
+
+// Default decode limits. An image's dimensions are read from its header and
+// checked against the limits before any pixel data is decoded, so a small file
+// that declares huge dimensions is rejected without allocating its buffer.
+const (
+	DefaultDecodeMaxDimension = 4096
+	DefaultDecodeMaxPixels    = DefaultDecodeMaxDimension * DefaultDecodeMaxDimension
+)
+
+// decodeFormats are the image formats Decode accepts, as named by image.Decode.
+var decodeFormats = map[string]bool{"png": true, "jpeg": true}
+
+// ErrNoCode is returned by Reader.Decode when the image holds no readable QR code.
+var ErrNoCode = errors.New("no QR code found in image")
+
+// ImageError reports why an image could not be read.
+type ImageError struct {
+	Reason string
+}
+
+func (e *ImageError) Error() string {
+	return "invalid image: " + e.Reason
+}
+
+// Reader decodes QR codes from images.
+type Reader interface {
+	// Decode returns the text held by the QR code in img, a PNG or JPEG image.
+	// It returns an *ImageError if img cannot be read, and ErrNoCode if no code
+	// is found or the code is too damaged to read.
+	Decode(ctx context.Context, img []byte) (string, error)
+}
+
+type reader struct {
+	logger    *slog.Logger
+	maxWidth  int
+	maxHeight int
+	maxPixels int64
+}
+
+// NewReader creates a Reader backed by the gozxing QR decoder. Images wider
+// than maxWidth, taller than maxHeight or with more than maxPixels pixels in
+// all are rejected with an *ImageError before they are decoded.
+func NewReader(logger *slog.Logger, maxWidth, maxHeight int, maxPixels int64) Reader {
+	return &reader{logger: logger, maxWidth: maxWidth, maxHeight: maxHeight, maxPixels: maxPixels}
+}
+
+// Decode implements Reader.
+func (r *reader) Decode(ctx context.Context, data []byte) (string, error) {
+	result, err := r.decode(ctx, data)
+	if err != nil {
+		return "", err
+	}
+	return result.GetText(), nil
+}
+
+// decode does the work of Decode and returns the full gozxing result, whose
+// metadata holds the raw bytes of any byte-mode segments.
+func (r *reader) decode(ctx context.Context, data []byte) (*gozxing.Result, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || !decodeFormats[format] {
+		r.logger.WarnContext(ctx, "QR decode failed: unsupported image", "format", format, "image_size_bytes", len(data))
+		return nil, &ImageError{Reason: "must be a PNG or JPEG image"}
+	}
+	if reason := r.checkDimensions(cfg.Width, cfg.Height); reason != "" {
+		r.logger.WarnContext(ctx, "QR decode failed: invalid image dimensions",
+			"width", cfg.Width,
+			"height", cfg.Height,
+			"max_width", r.maxWidth,
+			"max_height", r.maxHeight,
+			"max_pixels", r.maxPixels,
+		)
+		return nil, &ImageError{Reason: reason}
+	}
+
+	done := timing.Start(ctx, "decode_image")
+	img, _, err := image.Decode(bytes.NewReader(data))
+	done()
+	if err != nil {
+		r.logger.WarnContext(ctx, "QR decode failed: corrupt image", "format", format, "error", err)
+		return nil, &ImageError{Reason: err.Error()}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	done = timing.Start(ctx, "decode")
+	defer done()
+	bmp, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		return nil, &ImageError{Reason: err.Error()}
+	}
+	hints := map[gozxing.DecodeHintType]interface{}{gozxing.DecodeHintType_TRY_HARDER: true}
+	result, err := qrcode.NewQRCodeReader().Decode(bmp, hints)
+	if err != nil {
+		// Not-found, format and checksum failures all mean there is nothing
+		// usable to return, so they are reported alike. gozxing errors carry a
+		// stack trace, so only their kind is logged.
+		r.logger.InfoContext(ctx, "No QR code decoded from image",
+			"format", format,
+			"dimensions", fmt.Sprintf("%dx%d", cfg.Width, cfg.Height),
+			"reason", decodeFailure(err),
+		)
+		return nil, fmt.Errorf("%w (%s)", ErrNoCode, decodeFailure(err))
+	}
+
+	r.logger.DebugContext(ctx, "QR code decoded successfully",
+		"format", format,
+		"dimensions", fmt.Sprintf("%dx%d", cfg.Width, cfg.Height),
+		"text_length", len(result.GetText()),
+	)
+	return result, nil
+}
+
+// checkDimensions returns why an image of width by height pixels may not be
+// decoded, or "" if it may. The pixel count is computed in 64 bits so that
+// header values near the int limits cannot overflow past the check.
+func (r *reader) checkDimensions(width, height int) string {
+	switch {
+	case width <= 0 || height <= 0:
+		return "dimensions must be at least 1x1 pixels"
+	case width > r.maxWidth || height > r.maxHeight:
+		return fmt.Sprintf("dimensions %dx%d exceed the limit of %dx%d pixels", width, height, r.maxWidth, r.maxHeight)
+	case int64(width)*int64(height) > r.maxPixels:
+		return fmt.Sprintf("%dx%d is %d pixels, more than the limit of %d", width, height, int64(width)*int64(height), r.maxPixels)
+	}
+	return ""
+}
+
+// decodeFailure names the kind of gozxing decode error.
+func decodeFailure(err error) string {
+	switch err.(type) {
+	case gozxing.ChecksumException:
+		return "checksum"
+	case gozxing.FormatException:
+		return "format"
+	case gozxing.NotFoundException:
+		return "not_found"
+	default:
+		return "unknown"
+	}
+}
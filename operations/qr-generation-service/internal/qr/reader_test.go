@@ -0,0 +1,106 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package qr
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// pngHeader returns the signature and IHDR chunk of a PNG declaring width by
+// height pixels, with no image data after them. It is all image.DecodeConfig
+// reads, and decoding it fully would fail.
+func pngHeader(width, height uint32) []byte {
+	ihdr := make([]byte, 17)
+	copy(ihdr, "IHDR")
+	binary.BigEndian.PutUint32(ihdr[4:], width)
+	binary.BigEndian.PutUint32(ihdr[8:], height)
+	ihdr[12] = 8 // bit depth
+	ihdr[13] = 0 // greyscale
+
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	_ = binary.Write(&buf, binary.BigEndian, uint32(len(ihdr)-4))
+	buf.Write(ihdr)
+	_ = binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(ihdr))
+	return buf.Bytes()
+}
+
+func TestDecodeRejectsOversizedImages(t *testing.T) {
+	var small bytes.Buffer
+	if err := png.Encode(&small, image.NewGray(image.Rect(0, 0, 40, 30))); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		img       []byte
+		maxWidth  int
+		maxHeight int
+		maxPixels int64
+		// want is a substring of the ImageError reason, or "" when the image
+		// passes the limits and only fails for holding no code.
+		want string
+	}{
+		{name: "within limits", img: small.Bytes(), maxWidth: 40, maxHeight: 30, maxPixels: 1200},
+		{name: "too wide", img: small.Bytes(), maxWidth: 39, maxHeight: 30, maxPixels: 1200, want: "exceed the limit of 39x30"},
+		{name: "too tall", img: small.Bytes(), maxWidth: 40, maxHeight: 29, maxPixels: 1200, want: "exceed the limit of 40x29"},
+		{name: "too many pixels", img: small.Bytes(), maxWidth: 40, maxHeight: 30, maxPixels: 1199, want: "more than the limit of 1199"},
+		{
+			name:      "header declaring a huge image",
+			img:       pngHeader(1<<20, 1<<20),
+			maxWidth:  DefaultDecodeMaxDimension,
+			maxHeight: DefaultDecodeMaxDimension,
+			maxPixels: DefaultDecodeMaxPixels,
+			want:      "exceed the limit",
+		},
+		{
+			name:      "header declaring too many pixels within the dimensions",
+			img:       pngHeader(1<<16, 1<<16),
+			maxWidth:  1 << 16,
+			maxHeight: 1 << 16,
+			maxPixels: DefaultDecodeMaxPixels,
+			want:      "more than the limit",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewReader(testLogger, tt.maxWidth, tt.maxHeight, tt.maxPixels)
+			_, err := r.Decode(context.Background(), tt.img)
+			var imageErr *ImageError
+			if tt.want == "" {
+				if !errors.Is(err, ErrNoCode) {
+					t.Fatalf("Decode() error = %v, want ErrNoCode", err)
+				}
+				return
+			}
+			if !errors.As(err, &imageErr) {
+				t.Fatalf("Decode() error = %v, want an *ImageError", err)
+			}
+			if !strings.Contains(imageErr.Reason, tt.want) {
+				t.Errorf("Decode() reason = %q, want it to contain %q", imageErr.Reason, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package qr
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	// roundedModuleRadius is the corner radius of a rounded module as a fraction
+	// of its painted width, so a module with no dark neighbours is a circle.
+	roundedModuleRadius = 0.5
+	// finderModuleRadius caps the corner radius of finder pattern modules, as
+	// readers locate the code by the proportions of those patterns.
+	finderModuleRadius = 0.25
+)
+
+// moduleGrid maps a pixel coordinate along one axis to the module it falls in and
+// the pixel centre's position within that module (0 <= frac < 1). ok is false for
+// pixels outside the module grid, such as centring padding.
+type moduleGrid func(p int) (index int, frac float64, ok bool)
+
+// sampledGrid maps pixels to modules by nearest-neighbour sampling, like go-qrcode.
+// Modules may differ in width by one pixel when size is not a multiple of modules.
+func sampledGrid(modules, size int) moduleGrid {
+	modulesPerPixel := float64(modules) / float64(size)
+	return func(p int) (int, float64, bool) {
+		c := (float64(p) + 0.5) * modulesPerPixel
+		index := int(c)
+		return index, c - float64(index), true
+	}
+}
+
+// snappedGrid gives every module the same whole number of pixels and centres the
+// grid, so module boundaries always fall exactly on pixel edges.
+func snappedGrid(modules, size int) moduleGrid {
+	pixelsPerModule := size / modules
+	offset := (size - pixelsPerModule*modules) / 2
+	return func(p int) (int, float64, bool) {
+		p -= offset
+		if p < 0 || p >= pixelsPerModule*modules {
+			return 0, 0, false
+		}
+		return p / pixelsPerModule, (float64(p%pixelsPerModule) + 0.5) / float64(pixelsPerModule), true
+	}
+}
+
+// renderModules rasterizes a QR bitmap into a size x size two-colour image. Only the
+// central scale fraction of each dark module is painted, which leaves a gap between
+// neighbouring modules when scale < 1. When sharp is set, modules are snapped to a
+// uniform pixel grid. When eye is set, finder pattern modules use a third palette
+// entry so they can be coloured separately; border is the bitmap's quiet zone
+// width, used to locate them. When rounded is set, module corners are rounded
+// as described by insideRounded. Pixels are never blended, so module edges stay
+// hard.
+func renderModules(bitmap [][]bool, border, size int, scale float64, sharp, eye, rounded bool) *image.Paletted {
+	modules := len(bitmap)
+	// Like go-qrcode, never draw fewer pixels than there are modules.
+	if size < modules {
+		size = modules
+	}
+
+	palette := color.Palette{color.White, color.Black}
+	if eye {
+		palette = append(palette, color.Black)
+	}
+	img := image.NewPaletted(image.Rect(0, 0, size, size), palette)
+
+	grid := sampledGrid(modules, size)
+	if sharp {
+		grid = snappedGrid(modules, size)
+	}
+
+	lo := (1 - scale) / 2
+	hi := 1 - lo
+
+	for y := 0; y < size; y++ {
+		row, fy, ok := grid(y)
+		if !ok || fy < lo || fy >= hi {
+			continue
+		}
+		for x := 0; x < size; x++ {
+			col, fx, ok := grid(x)
+			if !ok || fx < lo || fx >= hi || !bitmap[row][col] {
+				continue
+			}
+			finder := isFinderModule(row, col, modules, border)
+			if rounded {
+				radius := roundedModuleRadius
+				if finder {
+					radius = finderModuleRadius
+				}
+				if !insideRounded(bitmap, row, col, (fx-lo)/scale, (fy-lo)/scale, radius, scale == 1) {
+					continue
+				}
+			}
+			index := uint8(1)
+			if eye && finder {
+				index = 2
+			}
+			img.Pix[img.PixOffset(x, y)] = index
+		}
+	}
+
+	return img
+}
+
+// insideRounded reports whether the point u, v, each from 0 to 1 across the painted
+// part of the dark module at row, col, lies inside the module once its free corners
+// are rounded with radius r. When merge is set, a corner is only free if neither
+// module beside it on that side is dark, so runs of modules join into smooth
+// shapes; otherwise gaps separate the modules and every corner is free.
+func insideRounded(bitmap [][]bool, row, col int, u, v, r float64, merge bool) bool {
+	var dx, dy int
+	var cx, cy float64
+	switch {
+	case u < r:
+		dx, cx = -1, r
+	case u > 1-r:
+		dx, cx = 1, 1-r
+	default:
+		return true
+	}
+	switch {
+	case v < r:
+		dy, cy = -1, r
+	case v > 1-r:
+		dy, cy = 1, 1-r
+	default:
+		return true
+	}
+	if merge && (isDarkModule(bitmap, row, col+dx) || isDarkModule(bitmap, row+dy, col)) {
+		return true
+	}
+	return math.Hypot(u-cx, v-cy) <= r
+}
+
+// isDarkModule reports whether the module at row, col is dark, treating modules
+// outside the bitmap as light.
+func isDarkModule(bitmap [][]bool, row, col int) bool {
+	return row >= 0 && row < len(bitmap) && col >= 0 && col < len(bitmap[row]) && bitmap[row][col]
+}
+
+// moduleCount returns the width in modules of a symbol of the given version,
+// including a quiet zone border modules wide.
+func moduleCount(version, border int) int {
+	return 17 + 4*version + 2*border
+}
+
+// symbol is an encoded QR code. Codes from go-qrcode keep the encoder's value so
+// plain codes can be drawn by its renderer; byte-mode codes only carry modules.
+type symbol struct {
+	// q is the go-qrcode symbol, or nil for a byte-mode symbol.
+	q *qrcode.QRCode
+	// modules is the matrix of a byte-mode symbol, quiet zone excluded.
+	modules [][]bool
+	// version is the QR version, from 1 to 40.
+	version int
+}
+
+// bitmap returns the QR matrix of sym surrounded by a quiet zone border modules
+// wide. A go-qrcode symbol must have DisableBorder set unless border is QuietZone.
+func (sym symbol) bitmap(border int) [][]bool {
+	if sym.q != nil && border == QuietZone {
+		return sym.q.Bitmap()
+	}
+	matrix := sym.modules
+	if sym.q != nil {
+		matrix = sym.q.Bitmap()
+	}
+	modules := len(matrix) + 2*border
+	padded := make([][]bool, modules)
+	for i := range padded {
+		padded[i] = make([]bool, modules)
+	}
+	for row, cells := range matrix {
+		copy(padded[row+border][border:], cells)
+	}
+	return padded
+}
+
+// isDark reports whether c is closer to black than to white.
+func isDark(c color.Color) bool {
+	return color.GrayModel.Convert(c).(color.Gray).Y < 0x80
+}
+
+// contentBounds returns the bounding box of the dark pixels in img, or an empty
+// rectangle if there are none.
+func contentBounds(img image.Image) image.Rectangle {
+	b := img.Bounds()
+	bounds := image.Rectangle{Min: b.Max, Max: b.Min}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if !isDark(img.At(x, y)) {
+				continue
+			}
+			bounds.Min.X = min(bounds.Min.X, x)
+			bounds.Min.Y = min(bounds.Min.Y, y)
+			bounds.Max.X = max(bounds.Max.X, x+1)
+			bounds.Max.Y = max(bounds.Max.Y, y+1)
+		}
+	}
+	if bounds.Empty() {
+		return image.Rectangle{}
+	}
+	return bounds
+}
+
+// cropToContent trims img to the bounding box of its dark pixels, keeping padding
+// pixels of margin on each side where the image allows it.
+func cropToContent(img image.Image, padding int) image.Image {
+	bounds := contentBounds(img)
+	if bounds.Empty() {
+		return img
+	}
+
+	bounds = bounds.Inset(-padding).Intersect(img.Bounds())
+	if sub, ok := img.(interface {
+		SubImage(r image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(bounds)
+	}
+	return img
+}
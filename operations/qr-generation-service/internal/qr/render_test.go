@@ -0,0 +1,138 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package qr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// decodePNG decodes a generated PNG image.
+func decodePNG(t *testing.T, data []byte) image.Image {
+	t.Helper()
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("png.Decode() error = %v", err)
+	}
+	return img
+}
+
+// sameColor reports whether a and b are the same colour once converted to RGBA.
+func sameColor(a, b color.Color) bool {
+	ar, ag, ab, aa := a.RGBA()
+	br, bg, bb, ba := b.RGBA()
+	return ar == br && ag == bg && ab == bb && aa == ba
+}
+
+func TestSharpModuleEdges(t *testing.T) {
+	navy := color.NRGBA{R: 0x1a, G: 0x23, B: 0x7e, A: 0xff}
+	ivory := color.NRGBA{R: 0xff, G: 0xfd, B: 0xe7, A: 0xff}
+	tests := []struct {
+		name string
+		opts Options
+		fg   color.Color
+		bg   color.Color
+	}{
+		{name: "default colours", opts: Options{}, fg: color.Black, bg: color.White},
+		{name: "custom colours", opts: Options{Colors: Colors{Foreground: navy, Background: ivory}}, fg: navy, bg: ivory},
+		{name: "byte mode", opts: Options{Mode: ModeByte}, fg: color.Black, bg: color.White},
+	}
+
+	svc := NewService(testLogger, 64, 2048, 0, DefaultCaptionMaxChars, DefaultCaptionMaxLines, 0, false)
+	for _, tt := range tests {
+		// None of these sizes is a multiple of the module count, so the
+		// unsnapped renderer would give modules uneven widths.
+		for _, size := range []int{301, 500, 777} {
+			t.Run(fmt.Sprintf("%s/%d", tt.name, size), func(t *testing.T) {
+				opts := tt.opts
+				opts.Size, opts.Sharp = size, true
+				result, err := svc.Generate(context.Background(), []byte("https://wso2.com/sharp-edges"), opts)
+				if err != nil {
+					t.Fatalf("Generate() error = %v", err)
+				}
+				img := decodePNG(t, result.Image)
+
+				pixelsPerModule := size / result.Modules
+				offset := (size - pixelsPerModule*result.Modules) / 2
+				inGrid := func(p int) bool { return p >= offset && p < offset+pixelsPerModule*result.Modules }
+				for y := 0; y < size; y++ {
+					for x := 0; x < size; x++ {
+						c := img.At(x, y)
+						if !sameColor(c, tt.fg) && !sameColor(c, tt.bg) {
+							t.Fatalf("pixel (%d, %d) is %v, which is neither palette colour", x, y, c)
+						}
+						if !inGrid(x) || !inGrid(y) {
+							if !sameColor(c, tt.bg) {
+								t.Fatalf("padding pixel (%d, %d) is not background", x, y)
+							}
+							continue
+						}
+						// Every pixel of a module matches its top-left pixel,
+						// so colour only changes on module boundaries.
+						mx := offset + (x-offset)/pixelsPerModule*pixelsPerModule
+						my := offset + (y-offset)/pixelsPerModule*pixelsPerModule
+						if !sameColor(c, img.At(mx, my)) {
+							t.Fatalf("pixel (%d, %d) differs from the rest of its module at (%d, %d)", x, y, mx, my)
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestRoundedModulesScan(t *testing.T) {
+	payloads := map[string]string{
+		"short":  "https://wso2.com",
+		"medium": "WIFI:T:WPA;S:Office Guest;P:correct-horse-battery-staple;;",
+		"long":   "BEGIN:VCARD\nVERSION:3.0\nFN:Jane Perera\nORG:WSO2\nTEL:+94112145345\nEMAIL:jane.perera@example.com\nURL:https://wso2.com\nEND:VCARD",
+	}
+	variants := map[string]Options{
+		"plain": {},
+		"sharp": {Sharp: true},
+		"eye":   {Colors: Colors{Eye: color.NRGBA{R: 0x8b, A: 0xff}}},
+	}
+
+	svc := NewService(testLogger, 64, 2048, 0, DefaultCaptionMaxChars, DefaultCaptionMaxLines, 0, false)
+	reader := NewReader(testLogger, DefaultDecodeMaxDimension, DefaultDecodeMaxDimension, DefaultDecodeMaxPixels)
+	for name, data := range payloads {
+		for variant, base := range variants {
+			for _, level := range RecoveryLevels {
+				t.Run(name+"/"+variant+"/"+level, func(t *testing.T) {
+					opts := base
+					opts.Size, opts.Style, opts.RecoveryLevel = 512, StyleRounded, level
+					result, err := svc.Generate(context.Background(), []byte(data), opts)
+					if err != nil {
+						t.Fatalf("Generate() error = %v", err)
+					}
+					got, err := reader.Decode(context.Background(), result.Image)
+					if err != nil {
+						t.Fatalf("Decode() error = %v", err)
+					}
+					if got != data {
+						t.Errorf("Decode() = %q, want %q", got, data)
+					}
+				})
+			}
+		}
+	}
+}
@@ -18,46 +18,195 @@
 package qr
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"image"
+	"image/color"
 	"log/slog"
+	"math"
 	"unicode/utf8"
 
 	"github.com/skip2/go-qrcode"
+
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/timing"
 )
 
 type Service interface {
-	Generate(data []byte, size int) ([]byte, error)
+	Generate(ctx context.Context, data []byte, opts Options) (*Result, error)
+}
+
+// Result is a generated QR code image.
+type Result struct {
+	// Image holds the encoded image bytes.
+	Image []byte
+	// ContentType is the MIME type of Image.
+	ContentType string
+	// Width and Height are the image dimensions in pixels.
+	Width  int
+	Height int
+	// Modules is the width of the symbol in modules, quiet zone included.
+	Modules int
+	// Version is the QR version, from 1 to 40. The symbol is 17 + 4*Version
+	// modules wide, quiet zone excluded.
+	Version int
+	// Dense is set when modules are drawn smaller than the service's minimum
+	// pixels per module, so the code may not scan reliably on phones.
+	Dense bool
+	// Frames is the number of frames in an animated GIF, and zero for other
+	// formats.
+	Frames int
+}
+
+// SymbolModules returns the width of the symbol in modules, quiet zone excluded.
+func (r *Result) SymbolModules() int {
+	return moduleCount(r.Version, 0)
+}
+
+// DensityError is returned in strict density mode when a code would be drawn with
+// fewer pixels per module than the configured minimum.
+type DensityError struct {
+	PixelsPerModule float64
+	MinPixels       float64
+	// SuggestedSize is the smallest size that meets MinPixels for this payload.
+	SuggestedSize int
+}
+
+func (e *DensityError) Error() string {
+	return fmt.Sprintf("code too dense: %.2f pixels per module is below the minimum of %.2f, use size %d or larger",
+		e.PixelsPerModule, e.MinPixels, e.SuggestedSize)
+}
+
+// AbandonedError is returned when ctx is done before generation finishes. The
+// work already running carries on in the background, and Done is closed once it
+// has stopped, so callers accounting for the CPU it uses can wait for that.
+type AbandonedError struct {
+	Err  error
+	Done <-chan struct{}
+}
+
+func (e *AbandonedError) Error() string {
+	return "generation abandoned: " + e.Err.Error()
+}
+
+func (e *AbandonedError) Unwrap() error {
+	return e.Err
+}
+
+// DataTooLongError is returned when the data is longer than the service accepts
+// at the effective recovery level.
+type DataTooLongError struct {
+	Length int
+	Limit  int
+	// RecoveryLevel is set when the limit is the capacity of the code at that
+	// level rather than the configured maximum.
+	RecoveryLevel string
+}
+
+func (e *DataTooLongError) Error() string {
+	if e.RecoveryLevel != "" {
+		return fmt.Sprintf("data exceeds maximum length of %d bytes at recovery level %s", e.Limit, e.RecoveryLevel)
+	}
+	return fmt.Sprintf("data exceeds maximum length of %d bytes", e.Limit)
 }
 
 type service struct {
-	logger  *slog.Logger
-	minSize int
-	maxSize int
+	logger          *slog.Logger
+	minSize         int
+	maxSize         int
+	maxDataBytes    int
+	captionMaxChars int
+	captionMaxLines int
+	minModulePixels float64
+	strictDensity   bool
 }
 
-// NewService creates a new QR code generation service instance.
-func NewService(logger *slog.Logger, minSize, maxSize int) Service {
+// NewService creates a new QR code generation service instance. Codes drawn with
+// fewer than minModulePixels pixels per module are flagged as dense, or rejected
+// with a *DensityError when strictDensity is set. Data longer than maxDataBytes,
+// or than the capacity of a code at the effective recovery level, is rejected
+// with a *DataTooLongError; zero leaves only the capacity limit. Captions longer
+// than captionMaxChars characters, or needing more than captionMaxLines lines
+// at the image width, are rejected with a *CaptionError.
+func NewService(logger *slog.Logger, minSize, maxSize, maxDataBytes, captionMaxChars, captionMaxLines int, minModulePixels float64, strictDensity bool) Service {
 	return &service{
-		logger:  logger,
-		minSize: minSize,
-		maxSize: maxSize,
+		logger:          logger,
+		minSize:         minSize,
+		maxSize:         maxSize,
+		maxDataBytes:    maxDataBytes,
+		captionMaxChars: captionMaxChars,
+		captionMaxLines: captionMaxLines,
+		minModulePixels: minModulePixels,
+		strictDensity:   strictDensity,
+	}
+}
+
+// Generate creates a QR code image from the provided data at opts.RecoveryLevel,
+// Medium (15%) by default.
+// The work runs on its own goroutine, and Generate returns an *AbandonedError
+// wrapping ctx.Err() as soon as ctx is done: a stage already running finishes in
+// the background and its result is discarded, and no later stage starts.
+func (s *service) Generate(ctx context.Context, data []byte, opts Options) (*Result, error) {
+	type outcome struct {
+		result *Result
+		err    error
+	}
+	done := make(chan outcome, 1)
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		// A panic here would otherwise take down the process rather than
+		// just this request.
+		defer func() {
+			if p := recover(); p != nil {
+				s.logger.ErrorContext(ctx, "QR code generation panicked", "panic", p)
+				done <- outcome{err: fmt.Errorf("generation panicked: %v", p)}
+			}
+		}()
+		result, err := s.generate(ctx, data, opts)
+		done <- outcome{result: result, err: err}
+	}()
+
+	select {
+	case out := <-done:
+		return out.result, out.err
+	case <-ctx.Done():
+		s.logger.WarnContext(ctx, "QR code generation abandoned",
+			"data_length", len(data),
+			"size", opts.Size,
+			"error", ctx.Err(),
+		)
+		return nil, &AbandonedError{Err: ctx.Err(), Done: finished}
 	}
 }
 
-// Generate creates a QR code PNG image from the provided data with Medium error recovery (15%).
-func (s *service) Generate(data []byte, size int) ([]byte, error) {
-	s.logger.Debug("Starting QR code generation",
+// generate does the work of Generate. Each stage is timed against ctx, and
+// generation stops before the next stage once ctx is done.
+func (s *service) generate(ctx context.Context, data []byte, opts Options) (*Result, error) {
+	size := opts.Size
+	scale := opts.moduleScale()
+	s.logger.DebugContext(ctx, "Starting QR code generation",
 		"data_length", len(data),
 		"size", size,
+		"module_scale", scale,
+		"sharp", opts.Sharp,
+		"style", opts.style(),
+		"crop", opts.Crop,
+		"format", opts.format(),
+		"transparent", opts.Transparent,
+		"minimal", opts.Minimal,
+		"recovery_level", opts.recoveryLevel(),
+		"mode", opts.mode(),
 	)
 
 	if len(data) == 0 {
-		s.logger.Warn("QR code generation failed: empty data provided")
+		s.logger.WarnContext(ctx, "QR code generation failed: empty data provided")
 		return nil, fmt.Errorf("data cannot be empty")
 	}
 
 	if size < s.minSize || size > s.maxSize {
-		s.logger.Warn("QR code generation failed: invalid size",
+		s.logger.WarnContext(ctx, "QR code generation failed: invalid size",
 			"size", size,
 			"min", s.minSize,
 			"max", s.maxSize,
@@ -65,30 +214,430 @@ func (s *service) Generate(data []byte, size int) ([]byte, error) {
 		return nil, fmt.Errorf("invalid size: must be between %d and %d", s.minSize, s.maxSize)
 	}
 
-	s.logger.Debug("Encoding QR code",
-		"recovery_level", "Medium",
+	// NaN fails every comparison, so the range is checked in negated form.
+	if !(scale >= MinModuleScale && scale <= 1) {
+		s.logger.WarnContext(ctx, "QR code generation failed: invalid module scale",
+			"module_scale", scale,
+			"min", MinModuleScale,
+			"max", 1.0,
+		)
+		return nil, fmt.Errorf("invalid module scale: must be between %.1f and 1.0", MinModuleScale)
+	}
+	if scale < ScannableModuleScale {
+		s.logger.WarnContext(ctx, "Module scale below scannable threshold, code may not scan reliably",
+			"module_scale", scale,
+			"threshold", ScannableModuleScale,
+		)
+	}
+
+	if opts.CropPadding < 0 || opts.CropPadding > QuietZone {
+		s.logger.WarnContext(ctx, "QR code generation failed: invalid crop padding",
+			"crop_padding", opts.CropPadding,
+			"max", QuietZone,
+		)
+		return nil, fmt.Errorf("invalid crop padding: must be between 0 and %d modules", QuietZone)
+	}
+
+	if border := opts.border(); border < 0 || border > MaxBorder {
+		s.logger.WarnContext(ctx, "QR code generation failed: invalid border",
+			"border", border,
+			"max", MaxBorder,
+		)
+		return nil, fmt.Errorf("invalid border: must be between 0 and %d modules", MaxBorder)
+	}
+
+	if !IsSupportedFormat(opts.format()) {
+		s.logger.WarnContext(ctx, "QR code generation failed: unsupported format", "format", opts.Format)
+		return nil, fmt.Errorf("unsupported format %q", opts.Format)
+	}
+
+	if opts.Quality != 0 && opts.format() != FormatJPEG {
+		s.logger.WarnContext(ctx, "QR code generation failed: quality is only supported for JPEG output", "format", opts.format())
+		return nil, fmt.Errorf("quality is only supported with format %q", FormatJPEG)
+	}
+	if opts.format() == FormatJPEG {
+		if q := opts.quality(); q < MinJPEGQuality || q > MaxJPEGQuality {
+			s.logger.WarnContext(ctx, "QR code generation failed: invalid JPEG quality", "quality", q)
+			return nil, fmt.Errorf("invalid quality: must be between %d and %d", MinJPEGQuality, MaxJPEGQuality)
+		}
+		if q := opts.quality(); q < ScannableJPEGQuality {
+			s.logger.WarnContext(ctx, "JPEG quality below scannable threshold, code may not scan reliably",
+				"quality", q,
+				"threshold", ScannableJPEGQuality,
+			)
+		}
+	}
+
+	if opts.WidthMM != 0 && opts.format() != FormatPDF {
+		s.logger.WarnContext(ctx, "QR code generation failed: printed width is only supported for PDF output", "format", opts.format())
+		return nil, fmt.Errorf("printed width is only supported with format %q", FormatPDF)
+	}
+	if w := opts.widthMM(); opts.format() == FormatPDF && !(w >= MinPDFWidthMM && w <= MaxPDFWidthMM) {
+		s.logger.WarnContext(ctx, "QR code generation failed: invalid printed width", "width_mm", w)
+		return nil, fmt.Errorf("invalid printed width: must be between %gmm and %gmm", MinPDFWidthMM, MaxPDFWidthMM)
+	}
+
+	if opts.Transparent && opts.format() != FormatPNG {
+		s.logger.WarnContext(ctx, "QR code generation failed: transparent background is only supported for PNG output", "format", opts.format())
+		return nil, fmt.Errorf("transparent background is only supported with format %q", FormatPNG)
+	}
+
+	if !IsSupportedRecoveryLevel(opts.recoveryLevel()) {
+		s.logger.WarnContext(ctx, "QR code generation failed: unsupported recovery level", "recovery_level", opts.RecoveryLevel)
+		return nil, fmt.Errorf("unsupported recovery level %q", opts.RecoveryLevel)
+	}
+
+	if !IsSupportedMode(opts.mode()) {
+		s.logger.WarnContext(ctx, "QR code generation failed: unsupported mode", "mode", opts.Mode)
+		return nil, fmt.Errorf("unsupported mode %q", opts.Mode)
+	}
+	if opts.mode() == ModeByte && opts.format() == FormatGIF {
+		s.logger.WarnContext(ctx, "QR code generation failed: byte mode is not supported for animated GIF output")
+		return nil, fmt.Errorf("mode %q is not supported with format %q", ModeByte, FormatGIF)
+	}
+
+	if opts.Logo != nil && !(opts.logoScale() > 0 && opts.logoScale() <= MaxLogoScale) {
+		s.logger.WarnContext(ctx, "QR code generation failed: invalid logo scale", "logo_scale", opts.LogoScale, "max", MaxLogoScale)
+		return nil, &LogoError{Reason: fmt.Sprintf("scale must be greater than 0 and at most %.1f", MaxLogoScale)}
+	}
+
+	if opts.Minimal && opts.Logo != nil {
+		s.logger.WarnContext(ctx, "QR code generation failed: minimal is not supported with a logo")
+		return nil, fmt.Errorf("minimal is not supported with a logo, which raises the recovery level")
+	}
+
+	if opts.format() == FormatSVG && opts.Logo != nil {
+		s.logger.WarnContext(ctx, "QR code generation failed: logo is not supported for SVG output")
+		return nil, fmt.Errorf("logo is not supported with format %q", FormatSVG)
+	}
+
+	if !IsSupportedStyle(opts.style()) {
+		s.logger.WarnContext(ctx, "QR code generation failed: unsupported style", "style", opts.Style)
+		return nil, fmt.Errorf("unsupported style %q", opts.Style)
+	}
+
+	if opts.format() == FormatSVG && opts.style() != StyleSquare {
+		s.logger.WarnContext(ctx, "QR code generation failed: style is not supported for SVG output", "style", opts.style())
+		return nil, fmt.Errorf("style %q is not supported with format %q", opts.style(), FormatSVG)
+	}
+
+	opts.Caption = SanitizeCaption(opts.Caption)
+	if err := ValidateCaption(opts.Caption, s.captionMaxChars); err != nil {
+		s.logger.WarnContext(ctx, "QR code generation failed: invalid caption", "error", err)
+		return nil, err
+	}
+	if opts.format() == FormatSVG && opts.Caption != "" {
+		s.logger.WarnContext(ctx, "QR code generation failed: caption is not supported for SVG output")
+		return nil, fmt.Errorf("caption is not supported with format %q", FormatSVG)
+	}
+
+	if opts.format() == FormatSVG && opts.Card != nil {
+		s.logger.WarnContext(ctx, "QR code generation failed: card is not supported for SVG output")
+		return nil, fmt.Errorf("card is not supported with format %q", FormatSVG)
+	}
+
+	if opts.Transparent && opts.Card != nil {
+		s.logger.WarnContext(ctx, "QR code generation failed: transparent background is not supported with a card")
+		return nil, fmt.Errorf("transparent background is not supported with a card")
+	}
+
+	if err := opts.Colors.CheckContrast(); err != nil {
+		s.logger.WarnContext(ctx, "QR code generation failed: insufficient color contrast",
+			"colors", opts.Colors.String(),
+			"error", err,
+		)
+		return nil, fmt.Errorf("invalid colors: %w", err)
+	}
+
+	if c := opts.Card; c != nil {
+		if c.Radius < 0 || c.Radius > MaxCardDimension ||
+			c.Padding < 0 || c.Padding > MaxCardDimension ||
+			c.Shadow < 0 || c.Shadow > MaxCardDimension {
+			s.logger.WarnContext(ctx, "QR code generation failed: invalid card style",
+				"radius", c.Radius,
+				"padding", c.Padding,
+				"shadow", c.Shadow,
+				"max", MaxCardDimension,
+			)
+			return nil, fmt.Errorf("invalid card style: radius, padding and shadow must be between 0 and %d", MaxCardDimension)
+		}
+	}
+
+	level := opts.recoveryLevel()
+	if opts.Logo != nil {
+		// A logo hides modules, so keep enough redundancy to restore them.
+		level = atLeastRecovery(level, RecoveryHigh)
+	}
+
+	if opts.format() == FormatGIF {
+		return s.generateGIF(ctx, data, opts, level)
+	}
+
+	if err := s.checkDataLength(ctx, len(data), level); err != nil {
+		return nil, err
+	}
+
+	s.logger.DebugContext(ctx, "Encoding QR code",
+		"recovery_level", level,
+		"requested_recovery_level", opts.recoveryLevel(),
 		"data_length", len(data),
 	)
 
 	// Note: The skip2/go-qrcode library requires string input.
 	// Converting []byte to string creates a copy, but this is unavoidable with current library.
 	// Consider checking if newer versions support []byte directly to avoid allocation.
-	png, err := qrcode.Encode(string(data), qrcode.Medium, size)
+	if err := s.checkDeadline(ctx, "encode"); err != nil {
+		return nil, err
+	}
+	done := timing.Start(ctx, "encode")
+	var sym symbol
+	var err error
+	if opts.mode() == ModeByte {
+		sym.version, sym.modules, err = encodeByteMode(data, level)
+	} else {
+		sym.q, err = qrcode.New(string(data), recoveryLevels[level])
+	}
+	done()
 	if err != nil {
-		s.logger.Error("Failed to encode QR code",
+		s.logger.ErrorContext(ctx, "Failed to encode QR code",
 			"error", err,
+			"mode", opts.mode(),
 			"data_length", len(data),
 			"size", size,
 		)
 		return nil, fmt.Errorf("failed to encode QR code: %w", err)
 	}
 
-	s.logger.Debug("QR code generated successfully",
-		"output_size_bytes", len(png),
-		"image_dimensions", fmt.Sprintf("%dx%d", size, size),
+	border := opts.border()
+	if sym.q != nil {
+		sym.version = sym.q.VersionNumber
+		if border != QuietZone {
+			// Draw the symbol without go-qrcode's fixed border and add our own.
+			sym.q.DisableBorder = true
+		}
+	}
+	modules := moduleCount(sym.version, border)
+	dense, err := s.checkDensity(ctx, size, modules)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkDeadline(ctx, "render"); err != nil {
+		return nil, err
+	}
+	if opts.format() == FormatSVG {
+		done = timing.Start(ctx, "render")
+		svg, width, height := renderSVG(sym.bitmap(border), border, size, scale, opts.Crop, min(opts.CropPadding, border), opts.Colors)
+		done()
+		s.logger.DebugContext(ctx, "QR code generated successfully",
+			"output_size_bytes", len(svg),
+			"format", FormatSVG,
+			"image_dimensions", fmt.Sprintf("%dx%d", width, height),
+		)
+		return &Result{
+			Image:       svg,
+			ContentType: contentTypes[FormatSVG],
+			Width:       width,
+			Height:      height,
+			Modules:     modules,
+			Version:     sym.version,
+			Dense:       dense,
+		}, nil
+	}
+
+	img, err := s.rasterize(ctx, sym, border, modules, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkDeadline(ctx, "encode_image"); err != nil {
+		return nil, err
+	}
+	done = timing.Start(ctx, "encode_image")
+	encoded, contentType, err := encodeImage(img, opts)
+	done()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to encode image",
+			"error", err,
+			"format", opts.format(),
+			"data_length", len(data),
+			"size", size,
+		)
+		return nil, fmt.Errorf("failed to encode QR code: %w", err)
+	}
+
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	s.logger.DebugContext(ctx, "QR code generated successfully",
+		"output_size_bytes", len(encoded),
+		"format", opts.format(),
+		"image_dimensions", fmt.Sprintf("%dx%d", width, height),
+	)
+
+	return &Result{
+		Image:       encoded,
+		ContentType: contentType,
+		Width:       width,
+		Height:      height,
+		Modules:     modules,
+		Version:     sym.version,
+		Dense:       dense,
+	}, nil
+}
+
+// rasterize draws sym as an image and applies cropping, colours, the logo, the
+// caption and the card from opts. modules is the symbol width including the border.
+func (s *service) rasterize(ctx context.Context, sym symbol, border, modules int, opts Options) (image.Image, error) {
+	done := timing.Start(ctx, "render")
+	img := s.render(ctx, sym, border, opts.Size, opts.moduleScale(), opts.Sharp, opts.Colors.customEye(), opts.style() == StyleRounded)
+	done()
+
+	if opts.Crop {
+		padding := int(math.Round(float64(opts.CropPadding) * float64(img.Bounds().Dx()) / float64(modules)))
+		img = cropToContent(img, padding)
+		s.logger.DebugContext(ctx, "Cropped QR code to content bounds",
+			"crop_padding_modules", opts.CropPadding,
+			"crop_padding_pixels", padding,
+		)
+	}
+	// Find the code and recolour only after cropping, as both rely on the
+	// black-on-white rendering to locate dark pixels.
+	code := contentBounds(img)
+	img = applyColors(img, opts.Colors)
+	_, bg, _ := opts.Colors.resolve()
+	if opts.Transparent {
+		img = clearBackground(img)
+		bg = color.Transparent
+	}
+
+	if opts.Logo != nil {
+		if err := s.checkDeadline(ctx, "logo"); err != nil {
+			return nil, err
+		}
+		done = timing.Start(ctx, "logo")
+		// With a transparent background the plate clears the modules beneath
+		// the logo rather than covering them.
+		var err error
+		img, err = OverlayLogo(img, bytes.NewReader(opts.Logo), code, opts.logoScale(), bg)
+		done()
+		if err != nil {
+			s.logger.WarnContext(ctx, "QR code generation failed: logo overlay", "error", err)
+			return nil, err
+		}
+		s.logger.DebugContext(ctx, "Overlaid logo on QR code",
+			"logo_size_bytes", len(opts.Logo),
+			"logo_scale", opts.logoScale(),
+		)
+	}
+
+	if opts.Caption != "" {
+		if err := s.checkDeadline(ctx, "caption"); err != nil {
+			return nil, err
+		}
+		done = timing.Start(ctx, "caption")
+		fg, _, _ := opts.Colors.resolve()
+		var err error
+		img, err = drawCaption(img, opts.Caption, s.captionMaxLines, code, modules-2*border, fg, bg)
+		done()
+		var captionErr *CaptionError
+		if errors.As(err, &captionErr) {
+			s.logger.WarnContext(ctx, "QR code generation failed: caption does not fit", "error", err)
+			return nil, err
+		}
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to draw caption", "error", err)
+			return nil, err
+		}
+		s.logger.DebugContext(ctx, "Drew caption beneath QR code", "caption_length", len(opts.Caption))
+	}
+
+	if opts.Card != nil {
+		if err := s.checkDeadline(ctx, "card"); err != nil {
+			return nil, err
+		}
+		done = timing.Start(ctx, "card")
+		img = composeCard(img, *opts.Card)
+		done()
+		s.logger.DebugContext(ctx, "Composited QR code onto card",
+			"radius", opts.Card.Radius,
+			"padding", opts.Card.Padding,
+			"shadow", opts.Card.Shadow,
+		)
+	}
+	return img, nil
+}
+
+// render rasterizes sym with a border modules wide. The go-qrcode renderer is used
+// unless module scaling, pixel snapping, a separate eye colour, rounded modules, a
+// non-standard border or a byte-mode symbol requires drawing directly from the QR
+// matrix.
+func (s *service) render(ctx context.Context, sym symbol, border, size int, scale float64, sharp, eye, rounded bool) image.Image {
+	if sym.q != nil && scale == 1 && !sharp && !eye && !rounded && border == QuietZone {
+		return sym.q.Image(size)
+	}
+
+	s.logger.DebugContext(ctx, "Rendering QR code from matrix",
+		"version", sym.version,
+		"module_scale", scale,
+		"sharp", sharp,
+		"eye", eye,
+		"rounded", rounded,
+		"border", border,
 	)
+	return renderModules(sym.bitmap(border), border, size, scale, sharp, eye, rounded)
+}
+
+// checkDataLength returns a *DataTooLongError when length bytes exceed the
+// configured maximum or the capacity of a code at level, whichever is lower.
+func (s *service) checkDataLength(ctx context.Context, length int, level string) error {
+	err := &DataTooLongError{Length: length, Limit: DataCapacity(level), RecoveryLevel: level}
+	if s.maxDataBytes > 0 && s.maxDataBytes < err.Limit {
+		err.Limit, err.RecoveryLevel = s.maxDataBytes, ""
+	}
+	if length <= err.Limit {
+		return nil
+	}
+	s.logger.WarnContext(ctx, "QR code generation failed: data too long",
+		"data_length", length,
+		"max_length", err.Limit,
+		"recovery_level", level,
+	)
+	return err
+}
+
+// checkDensity reports whether a symbol modules wide drawn at size pixels falls
+// below the minimum pixels per module. In strict mode that is an error instead.
+func (s *service) checkDensity(ctx context.Context, size, modules int) (bool, error) {
+	pixels := float64(size) / float64(modules)
+	if pixels >= s.minModulePixels {
+		return false, nil
+	}
 
-	return png, nil
+	suggested := int(math.Ceil(s.minModulePixels * float64(modules)))
+	s.logger.WarnContext(ctx, "QR code modules below minimum pixel size, code may not scan reliably",
+		"size", size,
+		"modules", modules,
+		"pixels_per_module", pixels,
+		"min_module_pixels", s.minModulePixels,
+		"suggested_size", suggested,
+		"strict", s.strictDensity,
+	)
+	if s.strictDensity {
+		return true, &DensityError{PixelsPerModule: pixels, MinPixels: s.minModulePixels, SuggestedSize: suggested}
+	}
+	return true, nil
+}
+
+// checkDeadline returns an error wrapping ctx.Err() if the request budget ran
+// out before the named stage could start.
+func (s *service) checkDeadline(ctx context.Context, stage string) error {
+	if err := ctx.Err(); err != nil {
+		s.logger.WarnContext(ctx, "QR code generation stopped: request deadline reached",
+			"stage", stage,
+			"error", err,
+		)
+		return fmt.Errorf("deadline reached before %s: %w", stage, err)
+	}
+	return nil
 }
 
 // truncateString truncates a string to maxLen for safe logging with proper UTF-8 handling.
@@ -0,0 +1,71 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package qr
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"math"
+	"testing"
+)
+
+// testLogo returns a small PNG logo.
+func testLogo(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 16, 16))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestGenerateRejectsInvalidScales(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{name: "module scale NaN", opts: Options{ModuleScale: math.NaN()}},
+		{name: "module scale below minimum", opts: Options{ModuleScale: MinModuleScale / 2}},
+		{name: "module scale above 1", opts: Options{ModuleScale: 1.5}},
+		{name: "module scale infinite", opts: Options{ModuleScale: math.Inf(1)}},
+		{name: "PDF width NaN", opts: Options{Format: FormatPDF, WidthMM: math.NaN()}},
+		{name: "logo scale NaN", opts: Options{Logo: testLogo(t), LogoScale: math.NaN()}},
+		{name: "logo scale negative", opts: Options{Logo: testLogo(t), LogoScale: -0.1}},
+		{name: "logo scale above maximum", opts: Options{Logo: testLogo(t), LogoScale: MaxLogoScale * 2}},
+	}
+
+	svc := NewService(testLogger, 64, 2048, 0, DefaultCaptionMaxChars, DefaultCaptionMaxLines, 0, false)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.Size = 256
+			if _, err := svc.Generate(context.Background(), []byte("https://wso2.com"), opts); err == nil {
+				t.Error("Generate() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestOverlayLogoRejectsNaNScale(t *testing.T) {
+	base := image.NewGray(image.Rect(0, 0, 256, 256))
+	_, err := OverlayLogo(base, bytes.NewReader(testLogo(t)), base.Bounds(), math.NaN(), nil)
+	if _, ok := err.(*LogoError); !ok {
+		t.Errorf("OverlayLogo() error = %v, want a *LogoError", err)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// renderSVG draws a QR bitmap with a quiet zone border modules wide as an SVG
+// document whose width and height give the module grid, quiet zone included, a
+// logical size of size pixels. Only the central scale fraction of each dark module
+// is drawn. When crop is set, the quiet zone is trimmed to cropPadding modules,
+// which must not exceed border. It returns the document and its pixel dimensions.
+func renderSVG(bitmap [][]bool, border, size int, scale float64, crop bool, cropPadding int, colors Colors) ([]byte, int, int) {
+	modules := len(bitmap)
+	// first and last bound the visible modules on both axes.
+	first, last := 0, modules
+	if crop {
+		first, last = border-cropPadding, modules-border+cropPadding
+	}
+	span := last - first
+	pixels := int(float64(size) * float64(span) / float64(modules))
+
+	fg, bg, eye := colors.resolve()
+	var fgPath, eyePath bytes.Buffer
+	inset := (1 - scale) / 2
+	isEye := func(row, col int) bool {
+		return colors.customEye() && isFinderModule(row, col, modules, border)
+	}
+
+	for row := first; row < last; row++ {
+		for col := first; col < last; col++ {
+			if !bitmap[row][col] {
+				continue
+			}
+			path := &fgPath
+			if isEye(row, col) {
+				path = &eyePath
+			}
+
+			if scale < 1 {
+				fmt.Fprintf(path, "M%s %sh%sv%sh-%sz",
+					svgNum(float64(col)+inset), svgNum(float64(row)+inset),
+					svgNum(scale), svgNum(scale), svgNum(scale))
+				continue
+			}
+
+			// Merge a run of dark modules of the same colour into one rectangle.
+			run := 1
+			for col+run < last && bitmap[row][col+run] && isEye(row, col+run) == isEye(row, col) {
+				run++
+			}
+			fmt.Fprintf(path, "M%d %dh%dv1h-%dz", col, row, run, run)
+			col += run - 1
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="%d %d %d %d" shape-rendering="crispEdges">`,
+		pixels, pixels, first, first, span, span)
+	fmt.Fprintf(&buf, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`,
+		first, first, span, span, FormatHexColor(bg))
+	if fgPath.Len() > 0 {
+		fmt.Fprintf(&buf, `<path fill="%s" d="%s"/>`, FormatHexColor(fg), fgPath.String())
+	}
+	if eyePath.Len() > 0 {
+		fmt.Fprintf(&buf, `<path fill="%s" d="%s"/>`, FormatHexColor(eye), eyePath.String())
+	}
+	buf.WriteString("</svg>\n")
+	return buf.Bytes(), pixels, pixels
+}
+
+// svgNum formats v compactly for SVG path data, to four decimal places.
+func svgNum(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1e4)/1e4, 'f', -1, 64)
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package timing records how long each stage of a request takes, so the stage
+// that consumed most of a request's time budget can be reported.
+package timing
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Stage is the measured duration of one named step of a request.
+type Stage struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Recorder collects stage durations for a single request. It is safe for concurrent use.
+type Recorder struct {
+	mu     sync.Mutex
+	stages []Stage
+}
+
+type recorderKey struct{}
+
+// WithRecorder returns a copy of ctx carrying a new Recorder.
+func WithRecorder(ctx context.Context) (context.Context, *Recorder) {
+	rec := &Recorder{}
+	return context.WithValue(ctx, recorderKey{}, rec), rec
+}
+
+// Start begins timing the named stage and returns a function that ends it.
+// If ctx carries no Recorder the returned function does nothing.
+func Start(ctx context.Context, name string) func() {
+	rec, _ := ctx.Value(recorderKey{}).(*Recorder)
+	if rec == nil {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		rec.add(name, time.Since(start))
+	}
+}
+
+func (r *Recorder) add(name string, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stages = append(r.stages, Stage{Name: name, Duration: d})
+}
+
+// Stages returns the recorded stages in the order they finished.
+func (r *Recorder) Stages() []Stage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Stage(nil), r.stages...)
+}
+
+// Slowest returns the stage with the longest duration, and false if none were recorded.
+func (r *Recorder) Slowest() (Stage, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.stages) == 0 {
+		return Stage{}, false
+	}
+	slowest := r.stages[0]
+	for _, s := range r.stages[1:] {
+		if s.Duration > slowest.Duration {
+			slowest = s
+		}
+	}
+	return slowest, true
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package tracing provides OpenTelemetry tracing for the QR generation service.
+// Tracing is off unless an OTLP endpoint is configured, in which case spans are
+// exported over OTLP/HTTP and incoming W3C trace context is continued.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/qr"
+)
+
+// DefaultServiceName is the service.name reported when OTEL_SERVICE_NAME is unset.
+const DefaultServiceName = "qr-generation-service"
+
+// tracerName identifies the instrumentation in exported spans.
+const tracerName = "github.com/wso2-open-operations/common-tools/operations/qr-generation-service"
+
+var tracer = otel.Tracer(tracerName)
+
+// Setup installs a global tracer provider that batches spans to the OTLP/HTTP
+// collector at endpoint, such as "http://otel-collector:4318", and propagates W3C
+// trace context. The returned function flushes pending spans and must be called
+// on shutdown. Other OTEL_* variables, such as OTEL_SERVICE_NAME,
+// OTEL_EXPORTER_OTLP_HEADERS and OTEL_TRACES_SAMPLER, are honoured.
+func Setup(ctx context.Context, logger *slog.Logger, endpoint string) (func(context.Context) error, error) {
+	// The endpoint is the collector base URL, so traces go to its standard
+	// path as they would if OTEL_EXPORTER_OTLP_ENDPOINT were read directly.
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(strings.TrimSuffix(endpoint, "/")+"/v1/traces"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+
+	// Detectors applied later win, so OTEL_SERVICE_NAME overrides the default.
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", DefaultServiceName)),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		logger.Warn("OpenTelemetry error", "error", err)
+	}))
+	return provider.Shutdown, nil
+}
+
+// Middleware starts a server span for each request to route, continuing the trace
+// in any incoming traceparent header, and records the response status.
+func Middleware(route string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			ctx, span := tracer.Start(ctx, r.Method+" "+route,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", r.Method),
+					attribute.String("http.route", route),
+					attribute.String("url.path", r.URL.Path),
+				),
+			)
+			defer span.End()
+
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(sw, r.WithContext(ctx))
+			span.SetAttributes(attribute.Int("http.response.status_code", sw.status))
+			if sw.status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(sw.status))
+			}
+		})
+	}
+}
+
+// tracedService records a span around each Generate call of a qr.Service.
+type tracedService struct {
+	next qr.Service
+}
+
+// InstrumentService wraps svc so every Generate call is recorded as a span with
+// the requested options and the size of the output.
+func InstrumentService(svc qr.Service) qr.Service {
+	return &tracedService{next: svc}
+}
+
+func (s *tracedService) Generate(ctx context.Context, data []byte, opts qr.Options) (*qr.Result, error) {
+	format := opts.Format
+	if format == "" {
+		format = qr.FormatPNG
+	}
+	ctx, span := tracer.Start(ctx, "qr.Generate", trace.WithAttributes(
+		attribute.Int("qr.data_length", len(data)),
+		attribute.Int("qr.size", opts.Size),
+		attribute.String("qr.format", format),
+		attribute.String("qr.recovery_level", opts.RecoveryLevel),
+		attribute.Bool("qr.logo", opts.Logo != nil),
+		attribute.Bool("qr.card", opts.Card != nil),
+	))
+	defer span.End()
+
+	result, err := s.next.Generate(ctx, data, opts)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(
+		attribute.Int("qr.output_bytes", len(result.Image)),
+		attribute.Int("qr.width", result.Width),
+		attribute.Int("qr.height", result.Height),
+		attribute.Int("qr.modules", result.Modules),
+		attribute.Bool("qr.dense", result.Dense),
+	)
+	if result.Frames > 0 {
+		span.SetAttributes(attribute.Int("qr.frames", result.Frames))
+	}
+	return result, nil
+}
+
+// statusWriter records the status code written through it.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush forwards to the underlying writer so streaming handlers keep working.
+func (w *statusWriter) Flush() {
+	if fl, ok := w.ResponseWriter.(http.Flusher); ok {
+		fl.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,677 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/limiter"
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/qr"
+)
+
+// batchIDRegex restricts batch item ids to characters that are safe in ZIP entry names.
+var batchIDRegex = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// batchFormats are the image formats a batch item may be generated in. PDF is
+// left out because a batch prints to PDF as a whole with ?format=pdf.
+var batchFormats = []string{qr.FormatPNG, qr.FormatJPEG, qr.FormatTIFF, qr.FormatGIF, qr.FormatSVG}
+
+// batchManifestName is the name of the ZIP entry describing every item's outcome.
+const batchManifestName = "manifest.json"
+
+// BatchLimits bounds the work a single batch request can cause.
+type BatchLimits struct {
+	// MaxItems is the largest number of items accepted in one batch.
+	MaxItems int
+	// Concurrency is the number of items generated at the same time.
+	Concurrency int
+}
+
+// BatchOptions are the generation settings of a batch item, named like the
+// query parameters of /generate. Fields an item leaves unset take the value from
+// the request's defaults, and after that the deployment defaults used for a
+// single code.
+type BatchOptions struct {
+	Size        int     `json:"size,omitempty"`
+	Format      string  `json:"format,omitempty"`
+	ECLevel     string  `json:"ecLevel,omitempty"`
+	Mode        string  `json:"mode,omitempty"`
+	FG          string  `json:"fg,omitempty"`
+	BG          string  `json:"bg,omitempty"`
+	Eye         string  `json:"eye,omitempty"`
+	Style       string  `json:"style,omitempty"`
+	Border      *int    `json:"border,omitempty"`
+	ModuleScale float64 `json:"module_scale,omitempty"`
+	Sharp       *bool   `json:"sharp,omitempty"`
+	Quality     int     `json:"quality,omitempty"`
+	Transparent *bool   `json:"transparent,omitempty"`
+	Minimal     *bool   `json:"minimal,omitempty"`
+	Caption     string  `json:"caption,omitempty"`
+	// Logo is a PNG image, base64 encoded in JSON.
+	Logo      []byte  `json:"logo,omitempty"`
+	LogoScale float64 `json:"logo_scale,omitempty"`
+}
+
+// withDefaults returns o with every field it leaves unset taken from defaults.
+func (o BatchOptions) withDefaults(defaults BatchOptions) BatchOptions {
+	for _, f := range []struct{ dst, src *string }{
+		{&o.Format, &defaults.Format},
+		{&o.ECLevel, &defaults.ECLevel},
+		{&o.Mode, &defaults.Mode},
+		{&o.FG, &defaults.FG},
+		{&o.BG, &defaults.BG},
+		{&o.Eye, &defaults.Eye},
+		{&o.Style, &defaults.Style},
+		{&o.Caption, &defaults.Caption},
+	} {
+		if *f.dst == "" {
+			*f.dst = *f.src
+		}
+	}
+	for _, f := range []struct{ dst, src **bool }{
+		{&o.Sharp, &defaults.Sharp},
+		{&o.Transparent, &defaults.Transparent},
+		{&o.Minimal, &defaults.Minimal},
+	} {
+		if *f.dst == nil {
+			*f.dst = *f.src
+		}
+	}
+	if o.Size == 0 {
+		o.Size = defaults.Size
+	}
+	if o.Border == nil {
+		o.Border = defaults.Border
+	}
+	if o.ModuleScale == 0 {
+		o.ModuleScale = defaults.ModuleScale
+	}
+	if o.Quality == 0 {
+		o.Quality = defaults.Quality
+	}
+	if len(o.Logo) == 0 {
+		o.Logo = defaults.Logo
+	}
+	if o.LogoScale == 0 {
+		o.LogoScale = defaults.LogoScale
+	}
+	return o
+}
+
+// BatchItem is one QR code requested in a batch. Its options are given inline
+// alongside id and data.
+type BatchItem struct {
+	ID   string `json:"id"`
+	Data string `json:"data"`
+	BatchOptions
+}
+
+// batchRequest is the object form of a batch body, whose defaults apply to every
+// item. A bare JSON array of items is accepted too.
+type batchRequest struct {
+	Defaults BatchOptions `json:"defaults"`
+	Items    []BatchItem  `json:"items"`
+}
+
+// batchItemError describes why one batch item was rejected. It is listed in the
+// items of the error response.
+type batchItemError struct {
+	Index   int    `json:"index"`
+	ID      string `json:"id,omitempty"`
+	Message string `json:"message"`
+}
+
+// batchManifest is the manifest.json entry of a batch archive.
+type batchManifest struct {
+	Items     int `json:"items"`
+	Generated int `json:"generated"`
+	Failed    int `json:"failed"`
+	// Unique is the number of codes generated for the valid items, which share
+	// one whenever their data and options match.
+	Unique int `json:"unique"`
+	// DedupRatio is the fraction of valid items that reused another item's code
+	// instead of being generated, rounded to three decimal places.
+	DedupRatio float64              `json:"dedup_ratio"`
+	Entries    []batchManifestEntry `json:"entries"`
+}
+
+// batchManifestEntry reports the outcome of one batch item, in request order.
+type batchManifestEntry struct {
+	Index int    `json:"index"`
+	ID    string `json:"id,omitempty"`
+	// Status is "ok" when File holds the item's code and "failed" otherwise.
+	Status string `json:"status"`
+	File   string `json:"file,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// GenerateBatch handles POST /generate/batch requests. It accepts a JSON array of
+// items, or an object with the items and the defaults they share, and returns a
+// ZIP archive with an {id}.{ext} entry per item in the item's own format, plus a
+// manifest.json entry reporting each item's outcome. An item that is invalid or
+// fails to generate is listed in the manifest as failed without holding up the
+// rest; only a batch where every item failed is rejected. With ?format=pdf the
+// codes are instead tiled in item order across the pages of a contact sheet,
+// each printed at the width set by ?mm and labelled with its caption or id, in
+// a grid and on a paper size set by ?columns, ?rows and ?page. A printed sheet
+// with gaps would go unnoticed, so any failed item fails a PDF batch with a
+// structured JSON error.
+func (h *Handler) GenerateBatch(w http.ResponseWriter, r *http.Request) {
+	// sheet is set for PDF output, which prints every code using pdfSize
+	// pixels as laid out by layout.
+	var (
+		sheet   bool
+		layout  qr.SheetLayout
+		pdfSize int
+	)
+	switch format := r.URL.Query().Get("format"); format {
+	case "", qr.FormatPNG:
+	case qr.FormatPDF:
+		widthMM, size, ok := h.parsePrintSize(w, r)
+		if !ok {
+			return
+		}
+		if layout, ok = h.parseSheetLayout(w, r, widthMM); !ok {
+			return
+		}
+		sheet, pdfSize = true, size
+	default:
+		h.logger.WarnContext(r.Context(), "Invalid batch format parameter",
+			"format", format,
+			"remote_addr", r.RemoteAddr,
+		)
+		writeParamError(w, "format", fmt.Sprintf("Invalid format parameter: must be %s or %s", qr.FormatPNG, qr.FormatPDF))
+		return
+	}
+
+	req, ok := h.decodeBatch(w, r)
+	if !ok {
+		return
+	}
+	items := req.Items
+
+	if len(items) == 0 {
+		h.logger.WarnContext(r.Context(), "Empty batch received", "remote_addr", r.RemoteAddr)
+		writeErrorDetail(w, http.StatusBadRequest, errorDetail{Code: ErrCodeInvalidBatch, Message: "batch must contain at least one item"})
+		return
+	}
+	if len(items) > h.batch.MaxItems {
+		h.logger.WarnContext(r.Context(), "Batch too large",
+			"items", len(items),
+			"max_items", h.batch.MaxItems,
+			"remote_addr", r.RemoteAddr,
+		)
+		writeErrorDetail(w, http.StatusBadRequest, errorDetail{Code: ErrCodeInvalidBatch, Message: fmt.Sprintf("batch has %d items, the maximum is %d", len(items), h.batch.MaxItems)})
+		return
+	}
+
+	opts, problems := h.validateBatch(items, req.Defaults, pdfSize, h.sizeLimit(r))
+	invalid := countProblems(problems)
+	if invalid > 0 {
+		h.logger.WarnContext(r.Context(), "Invalid batch items",
+			"invalid_items", invalid,
+			"items", len(items),
+			"remote_addr", r.RemoteAddr,
+		)
+		if sheet || invalid == len(items) {
+			writeErrorDetail(w, http.StatusBadRequest, errorDetail{Code: ErrCodeInvalidBatch, Message: "batch contains invalid items", Items: itemErrors(items, problems)})
+			return
+		}
+	}
+
+	h.logger.DebugContext(r.Context(), "Generating batch",
+		"items", len(items),
+		"concurrency", h.batch.Concurrency,
+		"pdf", sheet,
+	)
+
+	// A sheet prints captions as labels, so that every code fills a square
+	// cell of the grid.
+	var labels []string
+	if sheet {
+		labels = make([]string, len(items))
+		for i := range items {
+			labels[i] = items[i].ID
+			if opts[i].Caption != "" {
+				labels[i], opts[i].Caption = opts[i].Caption, ""
+			}
+		}
+	}
+
+	images, unique, busy := h.generateBatch(r.Context(), items, opts, problems)
+	if r.Context().Err() != nil {
+		h.writeTimeout(w, r, "generate")
+		return
+	}
+	if busy != nil {
+		writeBusy(w, busy)
+		return
+	}
+	if failed := countProblems(problems); failed > 0 {
+		h.logger.WarnContext(r.Context(), "Batch items failed",
+			"failed_items", failed,
+			"items", len(items),
+			"remote_addr", r.RemoteAddr,
+		)
+		if sheet || failed == len(items) {
+			writeErrorDetail(w, http.StatusBadRequest, errorDetail{Code: ErrCodeInvalidBatch, Message: "batch contains items that could not be generated", Items: itemErrors(items, problems)})
+			return
+		}
+	}
+
+	if sheet {
+		h.writeSheet(w, r, images, labels, layout)
+		return
+	}
+	h.writeArchive(w, r, items, opts, images, problems, len(items)-invalid, unique)
+}
+
+// decodeBatch reads a batch body in either of its forms. On failure it writes
+// the error response and returns false.
+func (h *Handler) decodeBatch(w http.ResponseWriter, r *http.Request) (batchRequest, bool) {
+	var raw json.RawMessage
+	if !h.decodeJSON(w, r, &raw) {
+		return batchRequest{}, false
+	}
+	var req batchRequest
+	var err error
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(trimmed, &req.Items)
+	} else {
+		err = json.Unmarshal(trimmed, &req)
+	}
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Invalid batch request body",
+			"error", err,
+			"remote_addr", r.RemoteAddr,
+		)
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidJSON, "Invalid JSON request body: must be an array of items or an object with items and defaults")
+		return batchRequest{}, false
+	}
+	return req, true
+}
+
+// validateBatch checks every item, with defaults applied, and returns the
+// options to generate each with, along with the problem that rules an item out
+// or "" for a valid one. A non-zero fixedSize is the size every code of a PDF
+// sheet is printed at, so items may not set their own. maxSize is the largest
+// size the caller may request.
+func (h *Handler) validateBatch(items []BatchItem, defaults BatchOptions, fixedSize, maxSize int) ([]qr.Options, []string) {
+	opts := make([]qr.Options, len(items))
+	problems := make([]string, len(items))
+	seen := make(map[string]bool, len(items))
+	for i, item := range items {
+		switch {
+		case !batchIDRegex.MatchString(item.ID):
+			problems[i] = "id must be 1-128 letters, digits, '.', '_' or '-'"
+		case seen[item.ID]:
+			problems[i] = "id is used by an earlier item"
+		case item.Data == "":
+			problems[i] = "data cannot be empty"
+		default:
+			opts[i], problems[i] = h.itemOptions(item.BatchOptions.withDefaults(defaults), fixedSize, maxSize)
+		}
+		seen[item.ID] = true
+	}
+	return opts, problems
+}
+
+// itemOptions returns the qr.Options that o asks for, or a message naming the
+// first problem found. The checks mirror those of the /generate query
+// parameters; what only the service can tell, such as whether a caption fits,
+// surfaces when the item is generated.
+func (h *Handler) itemOptions(o BatchOptions, fixedSize, maxSize int) (qr.Options, string) {
+	format := o.Format
+	if format == "" {
+		format = qr.FormatPNG
+	}
+	if len(o.Logo) == 0 {
+		o.Logo = nil
+	}
+	caption := qr.SanitizeCaption(o.Caption)
+	switch {
+	case !slices.Contains(batchFormats, format):
+		return qr.Options{}, fmt.Sprintf("format must be one of %s", strings.Join(batchFormats, ", "))
+	case fixedSize != 0 && format != qr.FormatPNG:
+		return qr.Options{}, "format is not supported with format=pdf, which prints PNG codes"
+	case fixedSize != 0 && o.Size != 0:
+		return qr.Options{}, "size is not supported with format=pdf, which is sized with mm and dpi"
+	case o.Size != 0 && (o.Size < h.minSize || o.Size > maxSize):
+		return qr.Options{}, fmt.Sprintf("size must be between %d and %d", h.minSize, maxSize)
+	case o.ECLevel != "" && !qr.IsSupportedRecoveryLevel(o.ECLevel):
+		return qr.Options{}, fmt.Sprintf("ecLevel must be one of %s", strings.Join(qr.RecoveryLevels, ", "))
+	case o.Mode != "" && !qr.IsSupportedMode(o.Mode):
+		return qr.Options{}, fmt.Sprintf("mode must be one of %s", strings.Join(qr.Modes, ", "))
+	case o.Mode == qr.ModeByte && format == qr.FormatGIF:
+		return qr.Options{}, "mode=byte is not supported with format=gif"
+	case o.Style != "" && !qr.IsSupportedStyle(o.Style):
+		return qr.Options{}, fmt.Sprintf("style must be one of %s", strings.Join(qr.Styles, ", "))
+	case o.Style == qr.StyleRounded && format == qr.FormatSVG:
+		return qr.Options{}, "style=rounded is not supported with format=svg"
+	case caption != "" && format == qr.FormatSVG:
+		return qr.Options{}, "caption is not supported with format=svg"
+	case o.Border != nil && (*o.Border < 0 || *o.Border > qr.MaxBorder):
+		return qr.Options{}, fmt.Sprintf("border must be between 0 and %d", qr.MaxBorder)
+	// Negated so that NaN is rejected too.
+	case o.ModuleScale != 0 && !(o.ModuleScale >= qr.MinModuleScale && o.ModuleScale <= 1):
+		return qr.Options{}, fmt.Sprintf("module_scale must be between %.1f and 1.0", qr.MinModuleScale)
+	case o.Quality != 0 && (format != qr.FormatJPEG || o.Quality < qr.MinJPEGQuality || o.Quality > qr.MaxJPEGQuality):
+		return qr.Options{}, fmt.Sprintf("quality requires format=jpeg and must be between %d and %d", qr.MinJPEGQuality, qr.MaxJPEGQuality)
+	case o.Transparent != nil && *o.Transparent && format != qr.FormatPNG:
+		return qr.Options{}, "transparent requires format=png"
+	case o.LogoScale != 0 && (o.Logo == nil || !(o.LogoScale > 0 && o.LogoScale <= qr.MaxLogoScale)):
+		return qr.Options{}, fmt.Sprintf("logo_scale requires a logo and must be greater than 0 and at most %.1f", qr.MaxLogoScale)
+	case o.Minimal != nil && *o.Minimal && o.Logo != nil:
+		return qr.Options{}, "minimal is not supported with a logo, which raises the error recovery level"
+	}
+
+	var colors qr.Colors
+	for _, c := range []struct {
+		name   string
+		value  string
+		target *color.Color
+	}{
+		{"fg", o.FG, &colors.Foreground},
+		{"bg", o.BG, &colors.Background},
+		{"eye", o.Eye, &colors.Eye},
+	} {
+		if c.value == "" {
+			continue
+		}
+		parsed, err := qr.ParseHexColor(c.value)
+		if err != nil {
+			return qr.Options{}, fmt.Sprintf("%s must be a hex color such as 1a2b3c", c.name)
+		}
+		*c.target = parsed
+	}
+	colors = colors.Merge(h.colors)
+	if err := colors.CheckContrast(); err != nil {
+		return qr.Options{}, fmt.Sprintf("colors: %v", err)
+	}
+
+	size := fixedSize
+	if size == 0 {
+		size = h.resolveSize(format, o.Size)
+	}
+	return qr.Options{
+		Size:          size,
+		Format:        format,
+		RecoveryLevel: o.ECLevel,
+		Mode:          o.Mode,
+		Colors:        colors,
+		Style:         o.Style,
+		Border:        o.Border,
+		ModuleScale:   o.ModuleScale,
+		Sharp:         o.Sharp != nil && *o.Sharp,
+		Quality:       o.Quality,
+		Transparent:   o.Transparent != nil && *o.Transparent,
+		Minimal:       o.Minimal != nil && *o.Minimal,
+		Caption:       caption,
+		Logo:          o.Logo,
+		LogoScale:     o.LogoScale,
+	}, ""
+}
+
+// generateBatch generates every item without a problem, with at most
+// Concurrency items in flight, and returns the images in item order. Items
+// whose data and options share a qr.Options.CacheKey are generated once, by the
+// first of them, and the rest reuse its image or its failure; unique is the
+// number of codes generated. The problems of items that fail are recorded in
+// place. busy is set when the concurrent generation limit turned an item away,
+// since that is not a fault of the item.
+func (h *Handler) generateBatch(ctx context.Context, items []BatchItem, opts []qr.Options, problems []string) (images [][]byte, unique int, busy *limiter.BusyError) {
+	images = make([][]byte, len(items))
+	errs := make([]error, len(items))
+	// source holds, for each item, the index of the item generated for it.
+	source := make([]int, len(items))
+	firsts := make(map[string]int, len(items))
+
+	sem := make(chan struct{}, h.batch.Concurrency)
+	var wg sync.WaitGroup
+	for i, item := range items {
+		source[i] = i
+		if problems[i] != "" {
+			continue
+		}
+		key := opts[i].CacheKey([]byte(item.Data))
+		if first, ok := firsts[key]; ok {
+			source[i] = first
+			continue
+		}
+		firsts[key] = i
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, item BatchItem) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			result, err := h.svc.Generate(ctx, []byte(item.Data), opts[i])
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			images[i] = result.Image
+		}(i, item)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if errors.As(err, &busy) {
+			return nil, 0, busy
+		}
+		if err != nil {
+			problems[i] = err.Error()
+		}
+	}
+	for i, first := range source {
+		if first != i {
+			images[i] = images[first]
+			problems[i] = problems[first]
+		}
+	}
+	return images, len(firsts), nil
+}
+
+// countProblems returns the number of items with a problem recorded.
+func countProblems(problems []string) int {
+	n := 0
+	for _, p := range problems {
+		if p != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// itemErrors lists the items with a problem recorded, for an error response.
+func itemErrors(items []BatchItem, problems []string) []batchItemError {
+	var errs []batchItemError
+	for i, p := range problems {
+		if p != "" {
+			errs = append(errs, batchItemError{Index: i, ID: items[i].ID, Message: p})
+		}
+	}
+	return errs
+}
+
+// writeArchive writes the ZIP archive of a batch: the manifest first, then one
+// entry per generated item in request order. Of the valid items, which passed
+// validation, unique codes were generated.
+func (h *Handler) writeArchive(w http.ResponseWriter, r *http.Request, items []BatchItem, opts []qr.Options, images [][]byte, problems []string, valid, unique int) {
+	manifest := batchManifest{Items: len(items), Unique: unique, Entries: make([]batchManifestEntry, len(items))}
+	for i, item := range items {
+		entry := batchManifestEntry{Index: i, ID: item.ID, Status: "ok"}
+		if problems[i] != "" {
+			entry.Status = "failed"
+			entry.Error = problems[i]
+			manifest.Failed++
+		} else {
+			entry.File = item.ID + qr.FileExtension(opts[i].Format)
+			manifest.Generated++
+		}
+		manifest.Entries[i] = entry
+	}
+	if reused := valid - unique; reused > 0 {
+		manifest.DedupRatio = math.Round(float64(reused)/float64(valid)*1000) / 1000
+	}
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to encode batch manifest", "error", err, "remote_addr", r.RemoteAddr)
+		writeError(w, http.StatusInternalServerError, ErrCodeEncodingFailed, "Failed to generate QR code")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="qr-codes.zip"`)
+	w.WriteHeader(http.StatusOK)
+
+	zw := zip.NewWriter(w)
+	write := func(name string, method uint16, data []byte) error {
+		entry, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: method})
+		if err != nil {
+			return err
+		}
+		_, err = entry.Write(data)
+		return err
+	}
+	if err := write(batchManifestName, zip.Deflate, manifestJSON); err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to write batch manifest", "error", err, "remote_addr", r.RemoteAddr)
+		return
+	}
+	for i, entry := range manifest.Entries {
+		if entry.File == "" {
+			continue
+		}
+		// PNG, JPEG and GIF data is already compressed, so only SVG and TIFF
+		// entries are deflated.
+		method := zip.Store
+		if f := opts[i].Format; f == qr.FormatSVG || f == qr.FormatTIFF {
+			method = zip.Deflate
+		}
+		if err := write(entry.File, method, images[i]); err != nil {
+			h.logger.ErrorContext(r.Context(), "failed to write batch archive entry",
+				"error", err,
+				"id", entry.ID,
+				"remote_addr", r.RemoteAddr,
+			)
+			return
+		}
+	}
+	if err := zw.Close(); err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to finish batch archive", "error", err, "remote_addr", r.RemoteAddr)
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "Batch request completed",
+		"items", manifest.Items,
+		"unique", manifest.Unique,
+		"generated", manifest.Generated,
+		"failed", manifest.Failed,
+		"remote_addr", r.RemoteAddr,
+	)
+}
+
+// parseSheetLayout parses the page, columns and rows query parameters of a PDF
+// batch whose codes are printed widthMM wide. On failure it writes the error
+// response and returns false.
+func (h *Handler) parseSheetLayout(w http.ResponseWriter, r *http.Request, widthMM float64) (qr.SheetLayout, bool) {
+	query := r.URL.Query()
+	layout := qr.SheetLayout{WidthMM: widthMM, Page: qr.PageA4}
+	if page := query.Get("page"); page != "" {
+		if !qr.IsSupportedPageSize(page) {
+			h.logger.WarnContext(r.Context(), "Invalid page parameter",
+				"page", page,
+				"remote_addr", r.RemoteAddr,
+			)
+			writeParamError(w, "page", fmt.Sprintf("Invalid page parameter: must be one of %s", strings.Join(qr.PageSizes, ", ")))
+			return qr.SheetLayout{}, false
+		}
+		layout.Page = page
+	}
+
+	columns, rows := qr.SheetGrid(layout.Page, widthMM)
+	for _, p := range []struct {
+		name   string
+		max    int
+		target *int
+	}{
+		{"columns", columns, &layout.Columns},
+		{"rows", rows, &layout.Rows},
+	} {
+		str := query.Get(p.name)
+		if str == "" {
+			continue
+		}
+		n, err := strconv.Atoi(str)
+		if err != nil || n < 1 || n > p.max {
+			h.logger.WarnContext(r.Context(), "Invalid sheet grid parameter",
+				"param", p.name,
+				"value", str,
+				"max", p.max,
+				"remote_addr", r.RemoteAddr,
+			)
+			writeParamError(w, p.name, fmt.Sprintf("Invalid %s parameter: must be between 1 and %d, the most %gmm codes that fit on %s paper", p.name, p.max, widthMM, layout.Page))
+			return qr.SheetLayout{}, false
+		}
+		*p.target = n
+	}
+	return layout, true
+}
+
+// writeSheet tiles the generated PNG images, each with its label beneath, onto
+// pages as set by layout and writes the PDF document as the response.
+func (h *Handler) writeSheet(w http.ResponseWriter, r *http.Request, images [][]byte, labels []string, layout qr.SheetLayout) {
+	cells := make([]qr.SheetCell, len(images))
+	for i, img := range images {
+		cells[i] = qr.SheetCell{PNG: img, Label: labels[i]}
+	}
+	pdf, err := qr.PDFSheet(cells, layout)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to build batch PDF", "error", err, "remote_addr", r.RemoteAddr)
+		writeError(w, http.StatusInternalServerError, ErrCodeEncodingFailed, "Failed to generate QR code")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Length", strconv.Itoa(len(pdf)))
+	w.Header().Set("Content-Disposition", `attachment; filename="qr-codes.pdf"`)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(pdf); err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to write batch PDF", "error", err, "remote_addr", r.RemoteAddr)
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "Batch request completed successfully",
+		"items", len(images),
+		"pdf_bytes", len(pdf),
+		"remote_addr", r.RemoteAddr,
+	)
+}
@@ -0,0 +1,224 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/qr"
+)
+
+// countingService counts the codes generated through it.
+type countingService struct {
+	qr.Service
+	calls atomic.Int32
+}
+
+func (s *countingService) Generate(ctx context.Context, data []byte, opts qr.Options) (*qr.Result, error) {
+	s.calls.Add(1)
+	return s.Service.Generate(ctx, data, opts)
+}
+
+// postBatch issues a POST /generate/batch request with body against h.
+func postBatch(t *testing.T, h *Handler, query, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/generate/batch"+query, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.GenerateBatch(rec, req)
+	return rec
+}
+
+// readArchive returns the entries of a batch ZIP response by name.
+func readArchive(t *testing.T, rec *httptest.ResponseRecorder) map[string][]byte {
+	t.Helper()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	if err != nil {
+		t.Fatalf("response is not a ZIP archive: %v", err)
+	}
+	entries := make(map[string][]byte, len(zr.File))
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries[f.Name] = data
+	}
+	return entries
+}
+
+func TestGenerateBatchItemOptions(t *testing.T) {
+	h := newTestHandler()
+	rec := postBatch(t, h, "", `{
+		"defaults": {"format": "jpeg", "quality": 60},
+		"items": [
+			{"id": "a", "data": "first"},
+			{"id": "b", "data": "second", "format": "svg", "quality": 0},
+			{"id": "c", "data": "third", "format": "png", "quality": 50},
+			{"id": "d", "data": "fourth", "fg": "eeeeee"}
+		]
+	}`)
+	entries := readArchive(t, rec)
+
+	var manifest batchManifest
+	if err := json.Unmarshal(entries[batchManifestName], &manifest); err != nil {
+		t.Fatalf("manifest: %v", err)
+	}
+	if manifest.Items != 4 || manifest.Generated != 1 || manifest.Failed != 3 {
+		t.Fatalf("manifest counts = %d/%d/%d, want 4 items, 1 generated, 3 failed", manifest.Items, manifest.Generated, manifest.Failed)
+	}
+	if _, ok := entries["a.jpg"]; !ok || manifest.Entries[0].File != "a.jpg" {
+		t.Errorf("item a: want a.jpg from the jpeg default, got entry %+v", manifest.Entries[0])
+	}
+	// quality 0 leaves the default in place, which svg and png cannot take.
+	for i, want := range map[int]string{1: "quality requires format=jpeg", 2: "quality requires format=jpeg", 3: "contrast ratio"} {
+		entry := manifest.Entries[i]
+		if entry.Status != "failed" || entry.File != "" || !strings.Contains(entry.Error, want) {
+			t.Errorf("entry %d = %+v, want a failure mentioning %q", i, entry, want)
+		}
+	}
+	if len(entries) != 2 {
+		t.Errorf("archive has %d entries, want the manifest and a.jpg", len(entries))
+	}
+}
+
+func TestGenerateBatchPartialFailure(t *testing.T) {
+	h := newTestHandler()
+	entries := readArchive(t, postBatch(t, h, "", `[
+		{"id": "ok", "data": "hello", "format": "svg"},
+		{"id": "bad id", "data": "hello"},
+		{"id": "long", "data": "hello", "caption": "`+strings.Repeat("x", 200)+`"}
+	]`))
+
+	var manifest batchManifest
+	if err := json.Unmarshal(entries[batchManifestName], &manifest); err != nil {
+		t.Fatalf("manifest: %v", err)
+	}
+	got := []string{manifest.Entries[0].Status, manifest.Entries[1].Status, manifest.Entries[2].Status}
+	if strings.Join(got, ",") != "ok,failed,failed" {
+		t.Fatalf("statuses = %v, want ok,failed,failed; manifest: %+v", got, manifest)
+	}
+	if !strings.HasPrefix(string(entries["ok.svg"]), "<") {
+		t.Errorf("ok.svg is not an SVG document")
+	}
+	if !strings.Contains(manifest.Entries[2].Error, "caption") {
+		t.Errorf("caption failure = %q, want the service's caption error", manifest.Entries[2].Error)
+	}
+}
+
+func TestGenerateBatchRejected(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		body  string
+	}{
+		{"every item invalid", "", `[{"id": "a", "data": ""}, {"id": "b", "data": "x", "mode": "kanji"}]`},
+		{"pdf with a failed item", "?format=pdf", `[{"id": "a", "data": "x"}, {"id": "b", "data": "y", "format": "svg"}]`},
+		{"pdf with an item size", "?format=pdf", `{"defaults": {"size": 300}, "items": [{"id": "a", "data": "x"}]}`},
+		{"pdf on an unknown page", "?format=pdf&page=a3", `[{"id": "a", "data": "x"}]`},
+		{"pdf grid wider than the page", "?format=pdf&mm=100&columns=2", `[{"id": "a", "data": "x"}]`},
+		{"not a batch", "", `"hello"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := postBatch(t, newTestHandler(), tt.query, tt.body)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d; body: %s", rec.Code, http.StatusBadRequest, rec.Body)
+			}
+		})
+	}
+}
+
+func TestGenerateBatchDeduplicates(t *testing.T) {
+	h := newTestHandler()
+	svc := &countingService{Service: h.svc}
+	h.svc = svc
+
+	long := strings.Repeat("x", 200)
+	entries := readArchive(t, postBatch(t, h, "", `[
+		{"id": "a", "data": "same"},
+		{"id": "b", "data": "same", "size": 256},
+		{"id": "c", "data": "same", "size": 300},
+		{"id": "d", "data": "same", "caption": "`+long+`"},
+		{"id": "e", "data": "same", "caption": "`+long+`"},
+		{"id": "f", "data": ""}
+	]`))
+
+	if got := svc.calls.Load(); got != 3 {
+		t.Errorf("service called %d times, want 3 for the unique codes", got)
+	}
+	if !bytes.Equal(entries["a.png"], entries["b.png"]) || len(entries["a.png"]) == 0 {
+		t.Error("a.png and b.png differ, want the same code, since size 256 is the default")
+	}
+	if bytes.Equal(entries["a.png"], entries["c.png"]) {
+		t.Error("c.png matches a.png, want its own code at size 300")
+	}
+
+	var manifest batchManifest
+	if err := json.Unmarshal(entries[batchManifestName], &manifest); err != nil {
+		t.Fatalf("manifest: %v", err)
+	}
+	if manifest.Generated != 3 || manifest.Failed != 3 || manifest.Unique != 3 {
+		t.Errorf("manifest counts = %d generated, %d failed, %d unique, want 3, 3, 3", manifest.Generated, manifest.Failed, manifest.Unique)
+	}
+	// Five valid items, two of which reused a code.
+	if manifest.DedupRatio != 0.4 {
+		t.Errorf("dedup_ratio = %v, want 0.4", manifest.DedupRatio)
+	}
+	if d, e := manifest.Entries[3], manifest.Entries[4]; e.Status != "failed" || e.Error != d.Error {
+		t.Errorf("entry e = %+v, want the failure of its duplicate d %+v", e, d)
+	}
+}
+
+func TestGenerateBatchSheetLabels(t *testing.T) {
+	rec := postBatch(t, newTestHandler(), "?format=pdf&page=letter&columns=2", `[
+		{"id": "bin-A01", "data": "BIN-A01"},
+		{"id": "bin-A02", "data": "BIN-A02", "caption": "Shelf 2"}
+	]`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+	pdf := rec.Body.Bytes()
+	for _, label := range []string{"(bin-A01) Tj", "(Shelf 2) Tj"} {
+		if !bytes.Contains(pdf, []byte(label)) {
+			t.Errorf("PDF lacks the label %s", label)
+		}
+	}
+	if bytes.Contains(pdf, []byte("(bin-A02) Tj")) {
+		t.Error("PDF labels bin-A02 by its id, want its caption")
+	}
+	if !bytes.Contains(pdf, []byte("/MediaBox [0 0 612 792]")) {
+		t.Error("PDF is not on letter paper")
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
+
+// GenerateOptions lists the query parameters accepted by the generate endpoints.
+var GenerateOptions = []string{
+	"size",
+	"size_pow2",
+	"preset",
+	"module_scale",
+	"sharp",
+	"style",
+	"crop",
+	"crop_padding",
+	"border",
+	"caption",
+	"card",
+	"card_radius",
+	"card_padding",
+	"card_shadow",
+	"format",
+	"filename",
+	"quality",
+	"mm",
+	"dpi",
+	"transparent",
+	"logo_scale",
+	"ecLevel",
+	"mode",
+	"minimal",
+	"fg",
+	"bg",
+	"eye",
+	"require_https",
+	"validate",
+	"source",
+}
+
+// Capabilities describes what this deployment supports, for client feature discovery.
+type Capabilities struct {
+	Symbologies          []string       `json:"symbologies"`
+	Formats              []string       `json:"formats"`
+	MinSize              int            `json:"min_size"`
+	MaxSize              int            `json:"max_size"`
+	DefaultSize          int            `json:"default_size"`
+	DefaultSizes         map[string]int `json:"default_sizes,omitempty"`
+	RecoveryLevels       []string       `json:"ecc_levels"`
+	DefaultRecoveryLevel string         `json:"default_ecc"`
+	Options              []string       `json:"options"`
+	Features             []string       `json:"features"`
+}
+
+// CapabilitiesHandler serves GET /capabilities with the given capabilities document.
+func CapabilitiesHandler(logger *slog.Logger, caps Capabilities) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.DebugContext(r.Context(), "Capabilities request received", "remote_addr", r.RemoteAddr)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		if err := json.NewEncoder(w).Encode(caps); err != nil {
+			logger.ErrorContext(r.Context(), "failed to encode capabilities response",
+				"error", err,
+				"remote_addr", r.RemoteAddr,
+			)
+		}
+	})
+}
@@ -0,0 +1,268 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"compress/gzip"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// gzipPool reuses gzip writers, which allocate large internal buffers.
+var gzipPool = sync.Pool{
+	New: func() any { return gzip.NewWriter(nil) },
+}
+
+// compressibleType reports whether responses of the given content type are
+// worth compressing. PNG, JPEG, TIFF and ZIP bodies are already compressed.
+func compressibleType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "text/"):
+		return true
+	case mediaType == "application/json", mediaType == "image/svg+xml":
+		return true
+	}
+	return false
+}
+
+// acceptsGzip reports whether an Accept-Encoding header value allows a gzip
+// response. Codings with q=0 are refused, and "*" stands for any coding not
+// otherwise listed.
+func acceptsGzip(header string) bool {
+	gzipQ, anyQ := -1.0, -1.0
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if name, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.EqualFold(strings.TrimSpace(name), "q") {
+			v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				continue
+			}
+			q = v
+		}
+		switch strings.ToLower(strings.TrimSpace(coding)) {
+		case "gzip", "x-gzip":
+			gzipQ = q
+		case "*":
+			anyQ = q
+		}
+	}
+	if gzipQ >= 0 {
+		return gzipQ > 0
+	}
+	return anyQ > 0
+}
+
+// CompressionMiddleware gzips text-like responses (SVG, JSON, data URIs) for
+// clients that send Accept-Encoding: gzip. Bodies shorter than minBytes, images
+// that are already compressed, and responses that already carry a
+// Content-Encoding are sent unchanged. Compressible responses always get
+// Vary: Accept-Encoding so that shared caches keep the variants apart, and a
+// strong ETag on a gzipped response gets a "-gzip" suffix, which is stripped
+// again from If-None-Match before the request reaches the handler.
+func CompressionMiddleware(logger *slog.Logger, minBytes int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cw := &compressWriter{
+				ResponseWriter: w,
+				accept:         r.Method != http.MethodHead && acceptsGzip(r.Header.Get("Accept-Encoding")),
+				minBytes:       minBytes,
+			}
+			if inm := r.Header.Get("If-None-Match"); cw.accept && strings.Contains(inm, gzipETagSuffix) {
+				r = r.Clone(r.Context())
+				r.Header.Set("If-None-Match", identityETags(inm))
+			}
+			defer func() {
+				if err := cw.finish(); err != nil {
+					logger.DebugContext(r.Context(), "Failed to finish compressed response", "error", err)
+				}
+			}()
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// compressMode is how a compressWriter sends the response body.
+type compressMode int
+
+const (
+	// modePending buffers the body until it is known to reach minBytes.
+	modePending compressMode = iota
+	// modeIdentity sends the body unchanged.
+	modeIdentity
+	// modeGzip sends the body gzipped.
+	modeGzip
+)
+
+// compressWriter decides, once the handler has chosen a status and headers,
+// whether to gzip the body. When the handler does not declare a Content-Length,
+// up to minBytes of body are buffered to make the decision.
+type compressWriter struct {
+	http.ResponseWriter
+	accept   bool
+	minBytes int
+
+	status      int
+	wroteHeader bool
+	mode        compressMode
+	buf         []byte
+	gz          *gzip.Writer
+}
+
+// WriteHeader records the status and chooses how the body is sent.
+func (w *compressWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.status = code
+
+	h := w.Header()
+	if code < http.StatusOK || code == http.StatusNoContent ||
+		h.Get("Content-Encoding") != "" || !compressibleType(h.Get("Content-Type")) {
+		w.start(modeIdentity)
+		return
+	}
+	h.Add("Vary", "Accept-Encoding")
+	if code == http.StatusNotModified {
+		// A 304 has no body, but carries the headers of the representation the
+		// client holds, so its ETag names the gzipped variant when that is what
+		// a 200 would have sent.
+		if w.accept && w.sizeWorthCompressing() {
+			h.Set("ETag", gzipETag(h.Get("ETag")))
+		}
+		w.start(modeIdentity)
+		return
+	}
+	if !w.accept {
+		w.start(modeIdentity)
+		return
+	}
+	if h.Get("Content-Length") != "" {
+		if !w.sizeWorthCompressing() {
+			w.start(modeIdentity)
+			return
+		}
+		w.start(modeGzip)
+		return
+	}
+	if w.minBytes <= 0 {
+		w.start(modeGzip)
+	}
+}
+
+// sizeWorthCompressing reports whether the declared Content-Length reaches
+// minBytes. Without one, only a minBytes of zero or less qualifies.
+func (w *compressWriter) sizeWorthCompressing() bool {
+	cl := w.Header().Get("Content-Length")
+	if cl == "" {
+		return w.minBytes <= 0
+	}
+	n, err := strconv.Atoi(cl)
+	return err != nil || n >= w.minBytes
+}
+
+// start sends the headers for mode and any body buffered so far.
+func (w *compressWriter) start(mode compressMode) {
+	w.mode = mode
+	if mode == modeGzip {
+		h := w.Header()
+		h.Del("Content-Length")
+		h.Set("Content-Encoding", "gzip")
+		if etag := h.Get("ETag"); etag != "" {
+			h.Set("ETag", gzipETag(etag))
+		}
+		w.gz = gzipPool.Get().(*gzip.Writer)
+		w.gz.Reset(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	if len(w.buf) > 0 {
+		buf := w.buf
+		w.buf = nil
+		_, _ = w.writeBody(buf)
+	}
+}
+
+// Write sends, compresses or buffers p according to the chosen mode.
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.mode != modePending {
+		return w.writeBody(p)
+	}
+	w.buf = append(w.buf, p...)
+	if len(w.buf) >= w.minBytes {
+		w.start(modeGzip)
+	}
+	return len(p), nil
+}
+
+func (w *compressWriter) writeBody(p []byte) (int, error) {
+	if w.mode == modeGzip {
+		return w.gz.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush forwards to the underlying writer so streaming handlers keep working. A
+// body still being buffered is sent uncompressed, since it has not reached the
+// size worth compressing.
+func (w *compressWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	switch w.mode {
+	case modePending:
+		w.start(modeIdentity)
+	case modeGzip:
+		_ = w.gz.Flush()
+	}
+	if fl, ok := w.ResponseWriter.(http.Flusher); ok {
+		fl.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// finish sends any buffered body and completes the gzip stream.
+func (w *compressWriter) finish() error {
+	switch {
+	case !w.wroteHeader:
+		return nil
+	case w.mode == modePending:
+		w.start(modeIdentity)
+	case w.mode == modeGzip:
+		err := w.gz.Close()
+		w.gz.Reset(nil)
+		gzipPool.Put(w.gz)
+		w.gz = nil
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/requestid"
+)
+
+const (
+	// corsAllowMethods are the methods browsers may use cross-origin.
+	corsAllowMethods = "GET, POST, OPTIONS"
+	// corsAllowHeaders are the request headers browsers may send cross-origin.
+	corsAllowHeaders = "Content-Type, If-None-Match, " + APIKeyHeader + ", " + DebugHeader + ", " + requestid.Header
+	// corsExposeHeaders are the response headers scripts may read.
+	corsExposeHeaders = "Content-Disposition, ETag, Retry-After, X-QR-Content-Type, X-QR-Dimensions, X-QR-Frames, X-QR-Module-Count, X-QR-Size, X-QR-Version, X-QR-Warning, X-UPI-URI, " + requestid.Header
+	// corsMaxAge is how long, in seconds, browsers may cache a preflight result.
+	corsMaxAge = 600
+)
+
+// corsPolicy decides which origins may call the service from a browser.
+type corsPolicy struct {
+	anyOrigin bool
+	origins   map[string]bool
+}
+
+// newCORSPolicy builds a policy from exact origins such as "https://app.example.com".
+// The entry "*" allows every origin. Matching ignores case and a trailing slash.
+func newCORSPolicy(allowedOrigins []string) corsPolicy {
+	p := corsPolicy{origins: make(map[string]bool)}
+	for _, origin := range allowedOrigins {
+		if origin == "*" {
+			p.anyOrigin = true
+			continue
+		}
+		p.origins[normalizeOrigin(origin)] = true
+	}
+	return p
+}
+
+// allowed reports whether a request from origin may receive CORS headers.
+func (p corsPolicy) allowed(origin string) bool {
+	if origin == "" {
+		return false
+	}
+	return p.anyOrigin || p.origins[normalizeOrigin(origin)]
+}
+
+func normalizeOrigin(origin string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(origin), "/"))
+}
+
+// CORSMiddleware lets browser clients on allowedOrigins call the service. Requests
+// from an allowed origin get Access-Control-Allow-Origin echoing that origin, and
+// preflight OPTIONS requests from one are answered with 204 and the allowed
+// methods and headers without reaching next. Requests from other origins get no
+// CORS headers, so the browser blocks them. It is a no-op when allowedOrigins is
+// empty.
+func CORSMiddleware(logger *slog.Logger, allowedOrigins []string) func(http.Handler) http.Handler {
+	policy := newCORSPolicy(allowedOrigins)
+
+	return func(next http.Handler) http.Handler {
+		if len(allowedOrigins) == 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			// Responses differ by origin, so caches must key on it.
+			w.Header().Add("Vary", "Origin")
+			if !policy.allowed(origin) {
+				if origin != "" {
+					logger.DebugContext(r.Context(), "Origin not allowed for CORS",
+						"origin", origin,
+						"method", r.Method,
+						"path", r.URL.Path,
+					)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				w.Header().Add("Vary", "Access-Control-Request-Method")
+				w.Header().Add("Vary", "Access-Control-Request-Headers")
+				w.Header().Set("Access-Control-Allow-Methods", corsAllowMethods)
+				w.Header().Set("Access-Control-Allow-Headers", corsAllowHeaders)
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(corsMaxAge))
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			w.Header().Set("Access-Control-Expose-Headers", corsExposeHeaders)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
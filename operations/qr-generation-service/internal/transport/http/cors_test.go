@@ -0,0 +1,237 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+// testLogger discards everything logged during tests.
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// corsHeaders are the response headers CORSMiddleware may set.
+var corsHeaders = []string{
+	"Access-Control-Allow-Origin",
+	"Access-Control-Allow-Methods",
+	"Access-Control-Allow-Headers",
+	"Access-Control-Max-Age",
+	"Access-Control-Expose-Headers",
+}
+
+func TestCORSMiddleware(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		method  string
+		headers map[string]string
+		// wantNext is whether the request reaches the wrapped handler.
+		wantNext   bool
+		wantStatus int
+		// want lists the CORS headers expected; every other one must be absent.
+		want map[string]string
+		// wantVary is whether the response varies by Origin.
+		wantVary bool
+	}{
+		{
+			name:       "no origin",
+			allowed:    []string{"https://app.example.com"},
+			method:     http.MethodPost,
+			wantNext:   true,
+			wantStatus: http.StatusOK,
+			wantVary:   true,
+		},
+		{
+			name:       "allowed origin",
+			allowed:    []string{"https://app.example.com"},
+			method:     http.MethodPost,
+			headers:    map[string]string{"Origin": "https://app.example.com"},
+			wantNext:   true,
+			wantStatus: http.StatusOK,
+			want: map[string]string{
+				"Access-Control-Allow-Origin":   "https://app.example.com",
+				"Access-Control-Expose-Headers": corsExposeHeaders,
+			},
+			wantVary: true,
+		},
+		{
+			name:       "allowed origin matched ignoring case and trailing slash",
+			allowed:    []string{"https://App.Example.com/"},
+			method:     http.MethodGet,
+			headers:    map[string]string{"Origin": "https://app.example.com"},
+			wantNext:   true,
+			wantStatus: http.StatusOK,
+			want: map[string]string{
+				"Access-Control-Allow-Origin":   "https://app.example.com",
+				"Access-Control-Expose-Headers": corsExposeHeaders,
+			},
+			wantVary: true,
+		},
+		{
+			name:       "wildcard echoes the origin",
+			allowed:    []string{"*"},
+			method:     http.MethodPost,
+			headers:    map[string]string{"Origin": "https://other.example.org"},
+			wantNext:   true,
+			wantStatus: http.StatusOK,
+			want: map[string]string{
+				"Access-Control-Allow-Origin":   "https://other.example.org",
+				"Access-Control-Expose-Headers": corsExposeHeaders,
+			},
+			wantVary: true,
+		},
+		{
+			name:       "disallowed origin",
+			allowed:    []string{"https://app.example.com"},
+			method:     http.MethodPost,
+			headers:    map[string]string{"Origin": "https://evil.example.com"},
+			wantNext:   true,
+			wantStatus: http.StatusOK,
+			wantVary:   true,
+		},
+		{
+			name:       "origin differing only by scheme is disallowed",
+			allowed:    []string{"https://app.example.com"},
+			method:     http.MethodPost,
+			headers:    map[string]string{"Origin": "http://app.example.com"},
+			wantNext:   true,
+			wantStatus: http.StatusOK,
+			wantVary:   true,
+		},
+		{
+			name:    "preflight from allowed origin",
+			allowed: []string{"https://app.example.com"},
+			method:  http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                         "https://app.example.com",
+				"Access-Control-Request-Method":  http.MethodPost,
+				"Access-Control-Request-Headers": "content-type, x-api-key",
+			},
+			wantStatus: http.StatusNoContent,
+			want: map[string]string{
+				"Access-Control-Allow-Origin":  "https://app.example.com",
+				"Access-Control-Allow-Methods": corsAllowMethods,
+				"Access-Control-Allow-Headers": corsAllowHeaders,
+				"Access-Control-Max-Age":       "600",
+			},
+			wantVary: true,
+		},
+		{
+			name:    "preflight from disallowed origin",
+			allowed: []string{"https://app.example.com"},
+			method:  http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                        "https://evil.example.com",
+				"Access-Control-Request-Method": http.MethodPost,
+			},
+			wantNext:   true,
+			wantStatus: http.StatusOK,
+			wantVary:   true,
+		},
+		{
+			name:       "OPTIONS without a requested method is not a preflight",
+			allowed:    []string{"https://app.example.com"},
+			method:     http.MethodOptions,
+			headers:    map[string]string{"Origin": "https://app.example.com"},
+			wantNext:   true,
+			wantStatus: http.StatusOK,
+			want: map[string]string{
+				"Access-Control-Allow-Origin":   "https://app.example.com",
+				"Access-Control-Expose-Headers": corsExposeHeaders,
+			},
+			wantVary: true,
+		},
+		{
+			name:    "no allowed origins",
+			allowed: nil,
+			method:  http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                        "https://app.example.com",
+				"Access-Control-Request-Method": http.MethodPost,
+			},
+			wantNext:   true,
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reached := false
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				reached = true
+				w.WriteHeader(http.StatusOK)
+			})
+			req := httptest.NewRequest(tt.method, "/generate", nil)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			rec := httptest.NewRecorder()
+			CORSMiddleware(testLogger, tt.allowed)(next).ServeHTTP(rec, req)
+
+			if reached != tt.wantNext {
+				t.Errorf("reached next = %t, want %t", reached, tt.wantNext)
+			}
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			for _, name := range corsHeaders {
+				if got, want := rec.Header().Get(name), tt.want[name]; got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+			if got := slices.Contains(rec.Header().Values("Vary"), "Origin"); got != tt.wantVary {
+				t.Errorf("Vary: Origin present = %t, want %t", got, tt.wantVary)
+			}
+		})
+	}
+}
+
+func TestCORSPreflightSkipsAPIKey(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("preflight reached the endpoint")
+	})
+	// The router applies CORS outside API key authentication.
+	handler := CORSMiddleware(testLogger, []string{"https://app.example.com"})(
+		APIKeyMiddleware(testLogger, []string{"secret"}, nil)(next),
+	)
+
+	req := httptest.NewRequest(http.MethodOptions, "/generate", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/generate", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status without API key = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+		t.Errorf("Access-Control-Allow-Origin on 401 = %q, want the origin so the browser can read the error", got)
+	}
+}
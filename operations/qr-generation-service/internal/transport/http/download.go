@@ -0,0 +1,92 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"path"
+	"strings"
+	"unicode"
+)
+
+const (
+	// defaultFilename is the download name, before the extension, used when the
+	// request does not set one.
+	defaultFilename = "qr"
+	// maxFilenameLength bounds the download name in runes, extension excluded.
+	maxFilenameLength = 128
+)
+
+// sanitizeFilename reduces name to a bare file name that is safe in a
+// Content-Disposition header: any directory part, control characters and
+// characters reserved in file names are removed, and surrounding spaces and
+// dots are trimmed. It returns an empty string if nothing usable is left.
+func sanitizeFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	var b strings.Builder
+	n := 0
+	for _, r := range name {
+		if unicode.IsControl(r) || strings.ContainsRune(`"*:<>?|/`, r) || r == unicode.ReplacementChar {
+			continue
+		}
+		if n == maxFilenameLength {
+			break
+		}
+		b.WriteRune(r)
+		n++
+	}
+	return strings.Trim(b.String(), " .")
+}
+
+// contentDisposition returns the Content-Disposition header value that names
+// the response name with ext appended, unless name already ends with it. name
+// must come from sanitizeFilename, which leaves nothing that needs escaping in
+// a quoted string. A name that is not plain ASCII is also sent UTF-8 encoded
+// in filename* (RFC 6266), with an ASCII fallback in filename.
+func contentDisposition(disposition, name, ext string) string {
+	if !strings.HasSuffix(strings.ToLower(name), ext) {
+		name += ext
+	}
+	fallback := strings.Map(func(r rune) rune {
+		if r > unicode.MaxASCII {
+			return '_'
+		}
+		return r
+	}, name)
+	value := disposition + `; filename="` + fallback + `"`
+	if fallback != name {
+		value += "; filename*=UTF-8''" + encodeExtValue(name)
+	}
+	return value
+}
+
+// encodeExtValue percent-encodes s as the value of an RFC 8187 extended
+// parameter, leaving only attr-char bytes as they are.
+func encodeExtValue(s string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("!#$&+-.^_`|~", c) >= 0 {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&0xf])
+	}
+	return b.String()
+}
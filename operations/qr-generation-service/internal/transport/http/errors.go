@@ -0,0 +1,111 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Machine-readable error codes returned in the "code" field of error responses.
+// They are part of the API and must not change once published.
+const (
+	ErrCodeEmptyBody        = "empty_body"
+	ErrCodeBodyTooLarge     = "body_too_large"
+	ErrCodeURITooLong       = "uri_too_long"
+	ErrCodeReadFailed       = "read_failed"
+	ErrCodeInvalidJSON      = "invalid_json"
+	ErrCodeInvalidMultipart = "invalid_multipart"
+	ErrCodeUnsupportedMedia = "unsupported_media_type"
+	ErrCodeMissingData      = "missing_data"
+	ErrCodeDataTooLong      = "data_too_long"
+	ErrCodeInvalidSize      = "invalid_size"
+	ErrCodeInvalidParameter = "invalid_parameter"
+	ErrCodeInsecureURL      = "insecure_url"
+	ErrCodeInvalidURL       = "invalid_url"
+	ErrCodeSourceNotAllowed = "source_not_allowed"
+	ErrCodeSourceTooLarge   = "source_too_large"
+	ErrCodeFetchFailed      = "fetch_failed"
+	ErrCodeLowContrast      = "low_contrast"
+	ErrCodeInvalidLogo      = "invalid_logo"
+	ErrCodeTooDense         = "too_dense"
+	ErrCodeInvalidPayload   = "invalid_payload"
+	ErrCodeInvalidBatch     = "invalid_batch"
+	ErrCodeInvalidImage     = "invalid_image"
+	ErrCodeNoCodeFound      = "no_code_found"
+	ErrCodeEncodingFailed   = "encoding_failed"
+	ErrCodeDecodingFailed   = "decoding_failed"
+	ErrCodeTimeout          = "timeout"
+	ErrCodeMissingAPIKey    = "missing_api_key"
+	ErrCodeInvalidAPIKey    = "invalid_api_key"
+	ErrCodeRateLimited      = "rate_limited"
+	ErrCodeMethodNotAllowed = "method_not_allowed"
+	ErrCodeNotFound         = "not_found"
+	ErrCodeShuttingDown     = "shutting_down"
+	ErrCodeOverloaded       = "overloaded"
+)
+
+// statusClientClosedRequest is the non-standard status, borrowed from nginx,
+// recorded for requests abandoned because the client disconnected.
+const statusClientClosedRequest = 499
+
+// errorResponse is the JSON envelope of every error response.
+type errorResponse struct {
+	Error errorDetail `json:"error"`
+}
+
+// errorDetail describes a failed request. Parameter names the offending query
+// parameter for invalid_parameter and invalid_size errors, and Items lists the
+// rejected entries of a batch.
+type errorDetail struct {
+	Code      string           `json:"code"`
+	Message   string           `json:"message"`
+	Parameter string           `json:"parameter,omitempty"`
+	Items     []batchItemError `json:"items,omitempty"`
+}
+
+// writeError responds with status and a JSON error envelope carrying code and
+// message. Like http.Error, it leaves other headers already set in place.
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeErrorDetail(w, status, errorDetail{Code: code, Message: message})
+}
+
+// writeParamError responds with 400 Bad Request for an invalid query parameter.
+func writeParamError(w http.ResponseWriter, param, message string) {
+	code := ErrCodeInvalidParameter
+	if param == "size" || param == "size_pow2" || param == "preset" {
+		code = ErrCodeInvalidSize
+	}
+	writeErrorDetail(w, http.StatusBadRequest, errorDetail{Code: code, Message: message, Parameter: param})
+}
+
+// writeErrorDetail responds with status and detail wrapped in the error envelope.
+func writeErrorDetail(w http.ResponseWriter, status int, detail errorDetail) {
+	h := w.Header()
+	// A partial success response may have set these; they no longer apply.
+	h.Del("Content-Length")
+	h.Del("Content-Disposition")
+	h.Set("Content-Type", "application/json")
+	h.Set("X-Content-Type-Options", "nosniff")
+	// Errors depend on limits and state that can change, so caches must never
+	// serve one in place of a fresh attempt.
+	h.Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	// Encoding a struct of strings cannot fail, and a write error means the
+	// client has gone away, so there is nothing useful to do with the result.
+	_ = json.NewEncoder(w).Encode(errorResponse{Error: detail})
+}
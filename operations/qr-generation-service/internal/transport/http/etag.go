@@ -0,0 +1,77 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// gzipETagSuffix marks the ETag of a gzipped representation, which differs byte
+// for byte from the identity one and so needs a tag of its own.
+const gzipETagSuffix = "-gzip"
+
+// strongETag returns a strong entity tag for body: the first 128 bits of its
+// SHA-256 digest in hex, quoted. The generate handler passes the encoded image
+// bytes rather than the request options, so anything that changes the output
+// (colours, logo, style, border, size or format) changes the tag, and two
+// requests that render identically share one.
+func strongETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches reports whether an If-None-Match header value lists etag or is
+// "*". As RFC 9110 requires for If-None-Match, tags are compared weakly, so a
+// W/ prefix on either side is ignored.
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// gzipETag returns the tag of the gzipped representation whose identity tag is
+// etag. Weak tags are returned unchanged.
+func gzipETag(etag string) string {
+	if !strings.HasPrefix(etag, `"`) || !strings.HasSuffix(etag, `"`) || len(etag) < 2 {
+		return etag
+	}
+	return etag[:len(etag)-1] + gzipETagSuffix + `"`
+}
+
+// identityETags rewrites the tags in an If-None-Match header value that name a
+// gzipped representation back to the identity tag the handler computes.
+func identityETags(header string) string {
+	tags := strings.Split(header, ",")
+	for i, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if suffix := gzipETagSuffix + `"`; strings.HasSuffix(tag, suffix) {
+			tag = strings.TrimSuffix(tag, suffix) + `"`
+		}
+		tags[i] = tag
+	}
+	return strings.Join(tags, ", ")
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/qr"
+)
+
+// newTestHandler returns a Handler over the real QR service with the default
+// configuration's size limits and no optional features.
+func newTestHandler() *Handler {
+	svc := qr.NewService(testLogger, 64, 2048, 0, qr.DefaultCaptionMaxChars, qr.DefaultCaptionMaxLines, 3, false)
+	return NewHandler(svc, qr.NewReader(testLogger, qr.DefaultDecodeMaxDimension, qr.DefaultDecodeMaxDimension, qr.DefaultDecodeMaxPixels), testLogger, 524288, 524288, 64, 2048, 0, false, qr.Colors{}, 300, nil, nil, "", nil, BatchLimits{MaxItems: 10, Concurrency: 1})
+}
+
+// generateETag issues req against the generate handler and returns the ETag of
+// its 200 response.
+func generateETag(t *testing.T, h *Handler, req *http.Request) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.Generate(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+	etag := rec.Header().Get("ETag")
+	if etag != strongETag(rec.Body.Bytes()) {
+		t.Fatalf("ETag = %s, want the tag of the response body %s", etag, strongETag(rec.Body.Bytes()))
+	}
+	return etag
+}
+
+// multipartRequest returns a POST /generate request carrying data and, when
+// logo is non-nil, a logo file.
+func multipartRequest(t *testing.T, query, data string, logo []byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	if err := mw.WriteField("data", data); err != nil {
+		t.Fatal(err)
+	}
+	if logo != nil {
+		fw, err := mw.CreateFormFile("logo", "logo.png")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write(logo); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/generate"+query, &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestGenerateETagTracksRenderedOptions(t *testing.T) {
+	h := newTestHandler()
+	base := generateETag(t, h, httptest.NewRequest(http.MethodGet, "/generate?data=hello&size=256", nil))
+
+	// The same request must yield the same tag, or revalidation never matches.
+	if again := generateETag(t, h, httptest.NewRequest(http.MethodGet, "/generate?data=hello&size=256", nil)); again != base {
+		t.Fatalf("repeated request ETag = %s, want %s", again, base)
+	}
+
+	for _, query := range []string{
+		"fg=1a2b3c",
+		"bg=fffff0",
+		"style=rounded",
+		"border=8",
+	} {
+		t.Run(query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/generate?data=hello&size=256&"+query, nil)
+			if etag := generateETag(t, h, req); etag == base {
+				t.Errorf("ETag with %s = %s, want one different from the default rendering", query, etag)
+			}
+		})
+	}
+
+	t.Run("logo", func(t *testing.T) {
+		var logo bytes.Buffer
+		if err := png.Encode(&logo, image.NewGray(image.Rect(0, 0, 16, 16))); err != nil {
+			t.Fatal(err)
+		}
+		plain := generateETag(t, h, multipartRequest(t, "?size=256", "hello", nil))
+		if plain != base {
+			t.Fatalf("multipart ETag without a logo = %s, want %s", plain, base)
+		}
+		if etag := generateETag(t, h, multipartRequest(t, "?size=256", "hello", logo.Bytes())); etag == base {
+			t.Errorf("ETag with a logo = %s, want one different from the default rendering", etag)
+		}
+	})
+}
+
+func TestGenerateNotModified(t *testing.T) {
+	h := newTestHandler()
+	etag := generateETag(t, h, httptest.NewRequest(http.MethodGet, "/generate?data=hello", nil))
+
+	tests := []struct {
+		name        string
+		method      string
+		ifNoneMatch string
+		want        int
+	}{
+		{name: "matching tag", method: http.MethodGet, ifNoneMatch: etag, want: http.StatusNotModified},
+		{name: "weak form of the tag", method: http.MethodGet, ifNoneMatch: "W/" + etag, want: http.StatusNotModified},
+		{name: "wildcard", method: http.MethodGet, ifNoneMatch: "*", want: http.StatusNotModified},
+		{name: "other tag", method: http.MethodGet, ifNoneMatch: `"0123"`, want: http.StatusOK},
+		{name: "POST ignores the header", method: http.MethodPost, ifNoneMatch: etag, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req *http.Request
+			if tt.method == http.MethodGet {
+				req = httptest.NewRequest(http.MethodGet, "/generate?data=hello", nil)
+			} else {
+				req = httptest.NewRequest(http.MethodPost, "/generate", bytes.NewBufferString("hello"))
+				req.Header.Set("Content-Type", "text/plain")
+			}
+			req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			rec := httptest.NewRecorder()
+			h.Generate(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if got := rec.Header().Get("ETag"); got != etag {
+				t.Errorf("ETag = %s, want %s", got, etag)
+			}
+		})
+	}
+}
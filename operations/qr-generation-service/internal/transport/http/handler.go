@@ -19,19 +19,49 @@ package http
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"image/color"
 	"io"
 	"log/slog"
+	"math/bits"
+	"mime"
 	"net/http"
+	"net/url"
+	"os"
+	"slices"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/config"
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/fetch"
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/limiter"
 	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/qr"
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/timing"
 )
 
+// MaxQueryDataLength is the longest data query parameter accepted by GET
+// /generate, in bytes after decoding. Browsers, proxies and servers commonly cap
+// URLs at a few kilobytes, so longer payloads must be POSTed.
+const MaxQueryDataLength = 2048
+
+// FormatDataURI is the response format that returns the PNG as a base64 data URI
+// in a text/plain body instead of raw image bytes.
+const FormatDataURI = "datauri"
+
+// ResponseFormats lists the values accepted by the format query parameter: the
+// image formats produced by the QR service plus the handler-level data URI mode.
+var ResponseFormats = append(append([]string(nil), qr.Formats...), FormatDataURI)
+
 // GenerateHandler defines the interface for QR code generation handler.
 type GenerateHandler interface {
 	http.Handler
@@ -39,22 +69,56 @@ type GenerateHandler interface {
 }
 
 type Handler struct {
-	svc         qr.Service
-	logger      *slog.Logger
-	maxBodySize int64
-	minSize     int
-	maxSize     int
-	encoderPool sync.Pool
+	svc          qr.Service
+	reader       qr.Reader
+	logger       *slog.Logger
+	maxBodySize  int64
+	decodeMax    int64
+	minSize      int
+	maxSize      int
+	trustedMax   int
+	requireHTTPS bool
+	colors       qr.Colors
+	pdfDPI       int
+	sizePresets  map[string]int
+	defaultSizes map[string]int
+	cacheControl string
+	fetcher      *fetch.Fetcher
+	batch        BatchLimits
+	encoderPool  sync.Pool
+	selfTest     selfTest
+	ready        atomic.Bool
 }
 
-// NewHandler creates a new HTTP handler for QR code generation.
-func NewHandler(svc qr.Service, logger *slog.Logger, maxBodySize int64, minSize, maxSize int) *Handler {
+// NewHandler creates a new HTTP handler for QR code generation and decoding.
+// /decode uploads are limited to decodeMax bytes instead of maxBodySize. When
+// requireHTTPS is set, payloads that are http:// URLs are rejected for every
+// request. colors are the deployment defaults that per-request colour parameters
+// override. Callers authenticated with a trusted API key may request sizes up to
+// trustedMax instead of maxSize; zero gives them no extra room. pdfDPI is the
+// resolution PDF output is rasterized at unless a request sets its own. sizePresets maps the names accepted by the preset parameter to
+// pixel sizes. defaultSizes holds the size used per format when a request sets
+// neither size nor preset. cacheControl, when not empty, is sent as the Cache-Control header
+// of generated codes. fetcher downloads the documents /generate encodes with
+// source=url; nil disables remote sources.
+func NewHandler(svc qr.Service, reader qr.Reader, logger *slog.Logger, maxBodySize, decodeMax int64, minSize, maxSize, trustedMax int, requireHTTPS bool, colors qr.Colors, pdfDPI int, sizePresets, defaultSizes map[string]int, cacheControl string, fetcher *fetch.Fetcher, batch BatchLimits) *Handler {
 	return &Handler{
-		svc:         svc,
-		logger:      logger,
-		maxBodySize: maxBodySize,
-		minSize:     minSize,
-		maxSize:     maxSize,
+		svc:          svc,
+		reader:       reader,
+		logger:       logger,
+		maxBodySize:  maxBodySize,
+		decodeMax:    decodeMax,
+		minSize:      minSize,
+		maxSize:      maxSize,
+		trustedMax:   trustedMax,
+		requireHTTPS: requireHTTPS,
+		colors:       colors,
+		pdfDPI:       pdfDPI,
+		sizePresets:  sizePresets,
+		defaultSizes: defaultSizes,
+		cacheControl: cacheControl,
+		fetcher:      fetcher,
+		batch:        batch,
 		encoderPool: sync.Pool{
 			New: func() interface{} {
 				return json.NewEncoder(io.Discard)
@@ -63,150 +127,1395 @@ func NewHandler(svc qr.Service, logger *slog.Logger, maxBodySize int64, minSize,
 	}
 }
 
-// Generate handles POST /generate?size={pixels} requests to create QR codes.
-// Accepts raw text/URL in body, returns PNG image.
+// sizeLimit returns the largest size r may request: trustedMax for callers
+// authenticated with a trusted API key, maxSize for everyone else.
+func (h *Handler) sizeLimit(r *http.Request) int {
+	if h.trustedMax > h.maxSize && isTrusted(r.Context()) {
+		return h.trustedMax
+	}
+	return h.maxSize
+}
+
+// rawBodyTypes are the request content types whose body Generate encodes as-is.
+// Form-encoded bodies are included because curl -d sends that type by default.
+var rawBodyTypes = map[string]bool{
+	"":                                  true,
+	"text/plain":                        true,
+	"application/x-www-form-urlencoded": true,
+	"application/octet-stream":          true,
+}
+
+// generateRequest is the JSON body accepted by POST /generate. Set fields take
+// the place of the query parameters of the same name.
+type generateRequest struct {
+	Data    string `json:"data"`
+	Size    *int   `json:"size,omitempty"`
+	ECLevel string `json:"ecLevel,omitempty"`
+	Format  string `json:"format,omitempty"`
+}
+
+// Generate handles POST /generate?size={pixels}&module_scale={fraction} requests to create QR codes.
+// Accepts raw text/URL in body, returns a PNG (or ?format=tiff, jpeg, svg, gif or pdf) image. A
+// multipart/form-data body carries the text in a "data" field and an optional
+// PNG "logo" file to draw over the centre of the code, and an application/json
+// body carries it in a generateRequest. Other content types are rejected with
+// 415. GET requests read the text from the "data" query parameter instead. With
+// source=url the text is a URL whose document is fetched and encoded instead.
 // Note: Method checking should be handled by middleware for cleaner separation.
 func (h *Handler) Generate(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		data := r.URL.Query().Get("data")
+		if len(data) > MaxQueryDataLength {
+			h.logger.WarnContext(r.Context(), "Data query parameter too long",
+				"data_length", len(data),
+				"max_length", MaxQueryDataLength,
+				"remote_addr", r.RemoteAddr,
+			)
+			writeError(w, http.StatusRequestURITooLong, ErrCodeDataTooLong, fmt.Sprintf("Data query parameter is too long: at most %d bytes; POST the data in the request body instead", MaxQueryDataLength))
+			return
+		}
+		if data == "" {
+			h.logger.WarnContext(r.Context(), "Empty data query parameter received", "remote_addr", r.RemoteAddr)
+			writeError(w, http.StatusBadRequest, ErrCodeMissingData, "Data query parameter is required")
+			return
+		}
+		payload, ok := h.resolveSource(w, r, []byte(data))
+		if !ok {
+			return
+		}
+		h.serveQR(w, r, payload, nil)
+		return
+	}
+
+	var body, logo []byte
+	var ok bool
+	contentType := r.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	switch {
+	case err != nil && contentType != "":
+		h.unsupportedMediaType(w, r, contentType)
+		return
+	case mediaType == "multipart/form-data":
+		body, logo, ok = h.readMultipart(w, r)
+	case mediaType == "application/json":
+		h.generateJSON(w, r)
+		return
+	case rawBodyTypes[mediaType]:
+		body, ok = h.readBody(w, r)
+	default:
+		h.unsupportedMediaType(w, r, contentType)
+		return
+	}
+	if !ok {
+		return
+	}
+
+	if len(body) == 0 {
+		h.logger.WarnContext(r.Context(), "Empty request body received", "remote_addr", r.RemoteAddr)
+		writeError(w, http.StatusBadRequest, ErrCodeEmptyBody, "Request body is empty")
+		return
+	}
+
+	body, ok = h.resolveSource(w, r, body)
+	if !ok {
+		return
+	}
+	h.serveQR(w, r, body, logo)
+}
+
+// generateJSON serves a POST /generate request with a JSON generateRequest body.
+// Size, ecLevel and format from the body replace the query parameters of the
+// same name, so they are validated exactly like them.
+func (h *Handler) generateJSON(w http.ResponseWriter, r *http.Request) {
+	var req generateRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Data == "" {
+		h.logger.WarnContext(r.Context(), "JSON request without data", "remote_addr", r.RemoteAddr)
+		writeError(w, http.StatusBadRequest, ErrCodeMissingData, "Data field is required")
+		return
+	}
+
+	query := r.URL.Query()
+	if req.Size != nil {
+		query.Set("size", strconv.Itoa(*req.Size))
+	}
+	if req.ECLevel != "" {
+		query.Set("ecLevel", req.ECLevel)
+	}
+	if req.Format != "" {
+		query.Set("format", req.Format)
+	}
+	r = r.Clone(r.Context())
+	r.URL.RawQuery = query.Encode()
+
+	h.logger.DebugContext(r.Context(), "JSON generate request decoded",
+		"data_length", len(req.Data),
+		"size_set", req.Size != nil,
+		"ec_level", req.ECLevel,
+		"format", req.Format,
+	)
+	payload, ok := h.resolveSource(w, r, []byte(req.Data))
+	if !ok {
+		return
+	}
+	h.serveQR(w, r, payload, nil)
+}
+
+// unsupportedMediaType responds with 415 for a request body of a content type
+// that Generate does not accept.
+func (h *Handler) unsupportedMediaType(w http.ResponseWriter, r *http.Request, contentType string) {
+	h.logger.WarnContext(r.Context(), "Unsupported request content type",
+		"content_type", contentType,
+		"remote_addr", r.RemoteAddr,
+	)
+	writeError(w, http.StatusUnsupportedMediaType, ErrCodeUnsupportedMedia,
+		"Unsupported Content-Type: send text/plain, application/json or multipart/form-data")
+}
+
+// GenerateUPI handles POST /generate/upi requests. It accepts a JSON UPI payment
+// request, builds the upi://pay URI, and returns it as a QR code. The constructed
+// URI is echoed in the X-UPI-URI response header.
+func (h *Handler) GenerateUPI(w http.ResponseWriter, r *http.Request) {
+	var req qr.UPIPayment
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	payload, err := qr.UPIPayload(req)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Invalid UPI payment request",
+			"error", err,
+			"remote_addr", r.RemoteAddr,
+		)
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidPayload, fmt.Sprintf("Invalid UPI payment request: %v", err))
+		return
+	}
+
+	h.logger.DebugContext(r.Context(), "UPI payload built", "payload_length", len(payload))
+	w.Header().Set("X-UPI-URI", payload)
+	h.serveQR(w, r, []byte(payload), nil)
+}
+
+// GenerateWiFi handles POST /generate/wifi requests. It accepts a JSON WiFi
+// network description, builds the WIFI: join string, and returns it as a QR code.
+// The payload is not echoed or logged because it contains the network password.
+func (h *Handler) GenerateWiFi(w http.ResponseWriter, r *http.Request) {
+	var req qr.WiFiNetwork
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	payload, err := qr.WiFiPayload(req)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Invalid WiFi network request",
+			"error", err,
+			"remote_addr", r.RemoteAddr,
+		)
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidPayload, fmt.Sprintf("Invalid WiFi network request: %v", err))
+		return
+	}
+
+	h.logger.DebugContext(r.Context(), "WiFi payload built", "payload_length", len(payload))
+	h.serveQR(w, r, []byte(payload), nil)
+}
+
+// GenerateVCard handles POST /generate/vcard requests. It accepts JSON contact
+// fields, builds a vCard 3.0 card, and returns it as a QR code that adds the
+// contact when scanned.
+func (h *Handler) GenerateVCard(w http.ResponseWriter, r *http.Request) {
+	var req qr.VCardContact
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	payload, err := qr.VCardPayload(req)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Invalid vCard contact request",
+			"error", err,
+			"remote_addr", r.RemoteAddr,
+		)
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidPayload, fmt.Sprintf("Invalid vCard contact request: %v", err))
+		return
+	}
+
+	h.logger.DebugContext(r.Context(), "vCard payload built", "payload_length", len(payload))
+	h.serveQR(w, r, []byte(payload), nil)
+}
+
+// GenerateSMS handles POST /generate/sms requests. It accepts a JSON phone number
+// and optional message, builds the SMSTO: string, and returns it as a QR code that
+// opens a pre-filled text message when scanned.
+func (h *Handler) GenerateSMS(w http.ResponseWriter, r *http.Request) {
+	var req qr.SMSMessage
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	payload, err := qr.SMSPayload(req)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Invalid SMS request",
+			"error", err,
+			"remote_addr", r.RemoteAddr,
+		)
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidPayload, fmt.Sprintf("Invalid SMS request: %v", err))
+		return
+	}
+
+	h.logger.DebugContext(r.Context(), "SMS payload built", "payload_length", len(payload))
+	h.serveQR(w, r, []byte(payload), nil)
+}
+
+// GenerateEmail handles POST /generate/email requests. It accepts JSON recipient,
+// subject and body fields, builds a mailto: URI, and returns it as a QR code that
+// opens a pre-filled email when scanned.
+func (h *Handler) GenerateEmail(w http.ResponseWriter, r *http.Request) {
+	var req qr.EmailMessage
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	payload, err := qr.EmailPayload(req)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Invalid email request",
+			"error", err,
+			"remote_addr", r.RemoteAddr,
+		)
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidPayload, fmt.Sprintf("Invalid email request: %v", err))
+		return
+	}
+
+	h.logger.DebugContext(r.Context(), "Email payload built", "payload_length", len(payload))
+	h.serveQR(w, r, []byte(payload), nil)
+}
+
+// GenerateEvent handles POST /generate/event requests. It accepts JSON summary,
+// start, end, location and description fields, builds an iCalendar VEVENT, and
+// returns it as a QR code that offers to add the event when scanned.
+func (h *Handler) GenerateEvent(w http.ResponseWriter, r *http.Request) {
+	var req qr.CalendarEvent
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	payload, err := qr.EventPayload(req)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Invalid calendar event request",
+			"error", err,
+			"remote_addr", r.RemoteAddr,
+		)
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidPayload, fmt.Sprintf("Invalid calendar event request: %v", err))
+		return
+	}
+
+	h.logger.DebugContext(r.Context(), "Calendar event payload built", "payload_length", len(payload))
+	h.serveQR(w, r, []byte(payload), nil)
+}
+
+// GenerateGeo handles POST /generate/geo requests. It accepts JSON latitude,
+// longitude and optional altitude fields, builds a geo: URI, and returns it as
+// a QR code that opens a map pin when scanned.
+func (h *Handler) GenerateGeo(w http.ResponseWriter, r *http.Request) {
+	var req qr.GeoLocation
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	payload, err := qr.GeoPayload(req)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Invalid geo location request",
+			"error", err,
+			"remote_addr", r.RemoteAddr,
+		)
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidPayload, fmt.Sprintf("Invalid geo location request: %v", err))
+		return
+	}
+
+	h.logger.DebugContext(r.Context(), "Geo payload built", "payload_length", len(payload))
+	h.serveQR(w, r, []byte(payload), nil)
+}
+
+// GenerateAuto handles POST /generate/auto requests. It accepts raw text like
+// /generate, classifies it with qr.DetectPayload, and encodes it unchanged. The
+// detected type is returned in the X-QR-Content-Type response header. The
+// payload is not logged, as WiFi join strings carry the network password.
+func (h *Handler) GenerateAuto(w http.ResponseWriter, r *http.Request) {
+	body, ok := h.readBody(w, r)
+	if !ok {
+		return
+	}
+
+	if len(body) == 0 {
+		h.logger.WarnContext(r.Context(), "Empty request body received", "remote_addr", r.RemoteAddr)
+		writeError(w, http.StatusBadRequest, ErrCodeEmptyBody, "Request body is empty")
+		return
+	}
+
+	payloadType := qr.DetectPayload(body)
+	h.logger.DebugContext(r.Context(), "Payload type detected",
+		"payload_type", payloadType,
+		"payload_length", len(body),
+	)
+	w.Header().Set("X-QR-Content-Type", string(payloadType))
+	h.serveQR(w, r, body, nil)
+}
+
+// decodeResponse is the body of a successful POST /decode response.
+type decodeResponse struct {
+	Text string `json:"text"`
+}
+
+// Decode handles POST /decode requests. It accepts a PNG or JPEG image of at
+// most decodeMax bytes as the raw request body and returns the text of the QR
+// code it contains as JSON. Larger uploads are rejected with 413, images over
+// the reader's dimension limits with 400 and images without a readable code
+// with 422.
+func (h *Handler) Decode(w http.ResponseWriter, r *http.Request) {
+	body, ok := h.readBodyLimit(w, r, h.decodeMax)
+	if !ok {
+		return
+	}
+	if len(body) == 0 {
+		h.logger.WarnContext(r.Context(), "Empty request body received", "remote_addr", r.RemoteAddr)
+		writeError(w, http.StatusBadRequest, ErrCodeEmptyBody, "Request body is empty")
+		return
+	}
+
+	text, err := h.reader.Decode(r.Context(), body)
+	if err != nil {
+		var imageErr *qr.ImageError
+		switch {
+		case errors.As(err, &imageErr):
+			writeError(w, http.StatusBadRequest, ErrCodeInvalidImage, fmt.Sprintf("Invalid image: %s", imageErr.Reason))
+		case errors.Is(err, qr.ErrNoCode):
+			writeError(w, http.StatusUnprocessableEntity, ErrCodeNoCodeFound, "No QR code could be found or read in the image")
+		case r.Context().Err() != nil:
+			h.writeTimeout(w, r, "decode")
+		default:
+			h.logger.ErrorContext(r.Context(), "QR decode failed", "error", err, "remote_addr", r.RemoteAddr)
+			writeError(w, http.StatusInternalServerError, ErrCodeDecodingFailed, "Failed to decode image")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(decodeResponse{Text: text}); err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to encode decode response",
+			"error", err,
+			"remote_addr", r.RemoteAddr,
+		)
+	}
+}
+
+// decodeJSON reads the request body and decodes it as JSON into v. On failure it
+// writes the error response and returns false.
+func (h *Handler) decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	body, ok := h.readBody(w, r)
+	if !ok {
+		return false
+	}
+
+	if len(body) == 0 {
+		h.logger.WarnContext(r.Context(), "Empty request body received", "remote_addr", r.RemoteAddr)
+		writeError(w, http.StatusBadRequest, ErrCodeEmptyBody, "Request body is empty")
+		return false
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		h.logger.WarnContext(r.Context(), "Invalid JSON request body",
+			"error", err,
+			"remote_addr", r.RemoteAddr,
+		)
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidJSON, "Invalid JSON request body")
+		return false
+	}
+	return true
+}
+
+// readBody reads the request body up to maxBodySize. On failure it writes the
+// error response and returns false.
+func (h *Handler) readBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	return h.readBodyLimit(w, r, h.maxBodySize)
+}
+
+// readBodyLimit reads the request body up to limit bytes. On failure it writes
+// the error response and returns false.
+func (h *Handler) readBodyLimit(w http.ResponseWriter, r *http.Request, limit int64) ([]byte, bool) {
 	// Fast fail for obvious oversized requests
-	if r.ContentLength > h.maxBodySize {
-		h.logger.Warn("Request body too large (ContentLength check)",
+	if r.ContentLength > limit {
+		h.logger.WarnContext(r.Context(), "Request body too large (ContentLength check)",
 			"content_length", r.ContentLength,
-			"max_allowed", h.maxBodySize,
+			"max_allowed", limit,
 			"remote_addr", r.RemoteAddr,
 		)
-		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
-		return
+		writeError(w, http.StatusRequestEntityTooLarge, ErrCodeBodyTooLarge, fmt.Sprintf("Request body too large: at most %d bytes", limit))
+		return nil, false
 	}
 
 	// Enforce maximum request body size to prevent DoS attacks
-	r.Body = http.MaxBytesReader(w, r.Body, h.maxBodySize)
-	h.logger.Debug("Reading request body", "max_size", h.maxBodySize)
+	r.Body = http.MaxBytesReader(w, r.Body, limit)
+	h.logger.DebugContext(r.Context(), "Reading request body", "max_size", limit)
 
 	var buf bytes.Buffer
-	if _, err := io.Copy(&buf, io.LimitReader(r.Body, h.maxBodySize)); err != nil {
+	done := timing.Start(r.Context(), "read_body")
+	_, err := io.Copy(&buf, io.LimitReader(r.Body, limit))
+	done()
+	if err != nil {
 		body := buf.Bytes()
-		if len(body) > int(h.maxBodySize) {
-			h.logger.Warn("Request body hit size limit",
-				"max_allowed", h.maxBodySize,
+		if len(body) > int(limit) {
+			h.logger.WarnContext(r.Context(), "Request body hit size limit",
+				"max_allowed", limit,
 				"remote_addr", r.RemoteAddr,
 			)
-			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
-			return
+			writeError(w, http.StatusRequestEntityTooLarge, ErrCodeBodyTooLarge, fmt.Sprintf("Request body too large: at most %d bytes", limit))
+			return nil, false
 		}
-		h.logger.Error("failed to read request body", "error", err, "remote_addr", r.RemoteAddr)
+		h.logger.ErrorContext(r.Context(), "failed to read request body", "error", err, "remote_addr", r.RemoteAddr)
 		var maxErr *http.MaxBytesError
 		if errors.As(err, &maxErr) {
-			h.logger.Warn("Request body too large",
-				"max_allowed", h.maxBodySize,
+			h.logger.WarnContext(r.Context(), "Request body too large",
+				"max_allowed", limit,
 				"remote_addr", r.RemoteAddr,
 			)
-			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
-			return
+			writeError(w, http.StatusRequestEntityTooLarge, ErrCodeBodyTooLarge, fmt.Sprintf("Request body too large: at most %d bytes", limit))
+			return nil, false
 		}
-		http.Error(w, "Failed to read request body", http.StatusInternalServerError)
-		return
+		if errors.Is(err, os.ErrDeadlineExceeded) || r.Context().Err() != nil {
+			h.writeTimeout(w, r, "read_body")
+			return nil, false
+		}
+		writeError(w, http.StatusInternalServerError, ErrCodeReadFailed, "Failed to read request body")
+		return nil, false
 	}
 
 	body := buf.Bytes()
-	h.logger.Debug("Request body read successfully", "body_size", len(body))
+	h.logger.DebugContext(r.Context(), "Request body read successfully", "body_size", len(body))
+	return body, true
+}
 
-	if len(body) == 0 {
-		h.logger.Warn("Empty request body received", "remote_addr", r.RemoteAddr)
-		http.Error(w, "Request body is empty", http.StatusBadRequest)
-		return
+// readMultipart reads a multipart/form-data body up to maxBodySize and returns its
+// "data" field and optional "logo" file. On failure it writes the error response
+// and returns false.
+func (h *Handler) readMultipart(w http.ResponseWriter, r *http.Request) ([]byte, []byte, bool) {
+	if r.ContentLength > h.maxBodySize {
+		h.logger.WarnContext(r.Context(), "Request body too large (ContentLength check)",
+			"content_length", r.ContentLength,
+			"max_allowed", h.maxBodySize,
+			"remote_addr", r.RemoteAddr,
+		)
+		writeError(w, http.StatusRequestEntityTooLarge, ErrCodeBodyTooLarge, fmt.Sprintf("Request body too large: at most %d bytes", h.maxBodySize))
+		return nil, nil, false
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxBodySize)
+	h.logger.DebugContext(r.Context(), "Reading multipart request body", "max_size", h.maxBodySize)
+
+	done := timing.Start(r.Context(), "read_body")
+	err := r.ParseMultipartForm(h.maxBodySize)
+	done()
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxErr):
+			h.logger.WarnContext(r.Context(), "Request body too large",
+				"max_allowed", h.maxBodySize,
+				"remote_addr", r.RemoteAddr,
+			)
+			writeError(w, http.StatusRequestEntityTooLarge, ErrCodeBodyTooLarge, fmt.Sprintf("Request body too large: at most %d bytes", h.maxBodySize))
+		case errors.Is(err, os.ErrDeadlineExceeded) || r.Context().Err() != nil:
+			h.writeTimeout(w, r, "read_body")
+		default:
+			h.logger.WarnContext(r.Context(), "Invalid multipart request body",
+				"error", err,
+				"remote_addr", r.RemoteAddr,
+			)
+			writeError(w, http.StatusBadRequest, ErrCodeInvalidMultipart, "Invalid multipart request body")
+		}
+		return nil, nil, false
 	}
+	defer r.MultipartForm.RemoveAll()
 
-	const defaultSize = 256
-	size := config.DefaultSize
-	if config.DefaultSize == 0 {
-		size = defaultSize
+	var data []byte
+	if values := r.MultipartForm.Value["data"]; len(values) > 0 {
+		data = []byte(values[0])
 	}
+
+	var logo []byte
+	if files := r.MultipartForm.File["logo"]; len(files) > 0 {
+		f, err := files[0].Open()
+		if err == nil {
+			logo, err = io.ReadAll(f)
+			f.Close()
+		}
+		if err != nil {
+			h.logger.ErrorContext(r.Context(), "failed to read logo file", "error", err, "remote_addr", r.RemoteAddr)
+			writeError(w, http.StatusInternalServerError, ErrCodeReadFailed, "Failed to read request body")
+			return nil, nil, false
+		}
+		if len(logo) == 0 {
+			h.logger.WarnContext(r.Context(), "Empty logo file received", "remote_addr", r.RemoteAddr)
+			writeError(w, http.StatusBadRequest, ErrCodeInvalidLogo, "Logo file is empty")
+			return nil, nil, false
+		}
+	}
+
+	h.logger.DebugContext(r.Context(), "Multipart request body read successfully",
+		"data_size", len(data),
+		"logo_size", len(logo),
+	)
+	return data, logo, true
+}
+
+// serveQR parses rendering options from the query string, generates a QR code
+// for data, optionally overlaid with logo, and writes it as the response.
+func (h *Handler) serveQR(w http.ResponseWriter, r *http.Request, data, logo []byte) {
+	maxSize := h.sizeLimit(r)
+
+	// The default size depends on the output format, so a size that is not
+	// requested stays zero until the format is known.
+	var requestedSize int
 	sizeStr := r.URL.Query().Get("size")
 
+	// An unknown preset is rejected even when size is also set, but the
+	// preset only decides the size when size is absent.
+	preset := strings.ToLower(r.URL.Query().Get("preset"))
+	presetSize, ok := h.sizePresets[preset]
+	if preset != "" && !ok {
+		h.logger.WarnContext(r.Context(), "Unknown size preset",
+			"preset", preset,
+			"remote_addr", r.RemoteAddr,
+		)
+		writeParamError(w, "preset", fmt.Sprintf("Invalid preset parameter: must be one of %s", strings.Join(h.presetNames(), ", ")))
+		return
+	}
+
 	if sizeStr != "" {
-		h.logger.Debug("Parsing size parameter", "size_str", sizeStr)
+		h.logger.DebugContext(r.Context(), "Parsing size parameter", "size_str", sizeStr)
 		parsedSize, err := strconv.Atoi(sizeStr)
-		if err != nil || parsedSize < h.minSize || parsedSize > h.maxSize {
-			h.logger.Warn("Invalid size parameter",
+		if err != nil || parsedSize < h.minSize || parsedSize > maxSize {
+			h.logger.WarnContext(r.Context(), "Invalid size parameter",
 				"size_str", sizeStr,
 				"error", err,
 				"min", h.minSize,
-				"max", h.maxSize,
+				"max", maxSize,
+				"remote_addr", r.RemoteAddr,
+			)
+			writeParamError(w, "size", fmt.Sprintf("Invalid size parameter: must be between %d and %d", h.minSize, maxSize))
+			return
+		}
+		requestedSize = parsedSize
+		h.logger.DebugContext(r.Context(), "Size parameter parsed", "size", requestedSize)
+	} else if preset != "" {
+		requestedSize = presetSize
+		h.logger.DebugContext(r.Context(), "Using size preset", "preset", preset, "size", requestedSize)
+	}
+
+	opts := qr.Options{}
+
+	requireHTTPS := h.requireHTTPS
+	if requireStr := r.URL.Query().Get("require_https"); requireStr != "" {
+		require, err := strconv.ParseBool(requireStr)
+		if err != nil {
+			h.logger.WarnContext(r.Context(), "Invalid require_https parameter",
+				"require_https_str", requireStr,
+				"remote_addr", r.RemoteAddr,
+			)
+			writeParamError(w, "require_https", "Invalid require_https parameter: must be true or false")
+			return
+		}
+		// The query can tighten the deployment policy but never relax it.
+		requireHTTPS = requireHTTPS || require
+	}
+	if validate := r.URL.Query().Get("validate"); validate != "" {
+		if validate != "url" {
+			h.logger.WarnContext(r.Context(), "Invalid validate parameter",
+				"validate", validate,
+				"remote_addr", r.RemoteAddr,
+			)
+			writeParamError(w, "validate", "Invalid validate parameter: must be url")
+			return
+		}
+		if err := qr.ValidateURL(data); err != nil {
+			h.logger.WarnContext(r.Context(), "Rejected malformed URL payload",
+				"error", err,
+				"data_length", len(data),
+				"remote_addr", r.RemoteAddr,
+			)
+			writeError(w, http.StatusBadRequest, ErrCodeInvalidURL, fmt.Sprintf("Invalid URL: %v", err))
+			return
+		}
+	}
+	if requireHTTPS {
+		if u, ok := insecureURL(data); ok {
+			h.logger.WarnContext(r.Context(), "Rejected insecure http:// URL payload",
+				"host", u.Host,
+				"remote_addr", r.RemoteAddr,
+			)
+			u.Scheme = "https"
+			writeError(w, http.StatusBadRequest, ErrCodeInsecureURL, fmt.Sprintf("Insecure URL rejected: http:// links are not allowed, use %s instead", u.String()))
+			return
+		}
+	}
+
+	if scaleStr := r.URL.Query().Get("module_scale"); scaleStr != "" {
+		h.logger.DebugContext(r.Context(), "Parsing module_scale parameter", "module_scale_str", scaleStr)
+		scale, err := strconv.ParseFloat(scaleStr, 64)
+		// Written as a negated range check so that NaN, which ParseFloat
+		// accepts, is rejected too.
+		if err != nil || !(scale >= qr.MinModuleScale && scale <= 1) {
+			h.logger.WarnContext(r.Context(), "Invalid module_scale parameter",
+				"module_scale_str", scaleStr,
+				"error", err,
+				"remote_addr", r.RemoteAddr,
+			)
+			writeParamError(w, "module_scale", fmt.Sprintf("Invalid module_scale parameter: must be between %.1f and 1.0", qr.MinModuleScale))
+			return
+		}
+		opts.ModuleScale = scale
+	}
+
+	if sharpStr := r.URL.Query().Get("sharp"); sharpStr != "" {
+		sharp, err := strconv.ParseBool(sharpStr)
+		if err != nil {
+			h.logger.WarnContext(r.Context(), "Invalid sharp parameter",
+				"sharp_str", sharpStr,
+				"remote_addr", r.RemoteAddr,
+			)
+			writeParamError(w, "sharp", "Invalid sharp parameter: must be true or false")
+			return
+		}
+		opts.Sharp = sharp
+	}
+
+	if style := r.URL.Query().Get("style"); style != "" {
+		if !qr.IsSupportedStyle(style) {
+			h.logger.WarnContext(r.Context(), "Invalid style parameter",
+				"style", style,
+				"remote_addr", r.RemoteAddr,
+			)
+			writeParamError(w, "style", fmt.Sprintf("Invalid style parameter: must be one of %s", strings.Join(qr.Styles, ", ")))
+			return
+		}
+		opts.Style = style
+	}
+
+	if cropStr := r.URL.Query().Get("crop"); cropStr != "" {
+		if cropStr != "tight" {
+			h.logger.WarnContext(r.Context(), "Invalid crop parameter",
+				"crop_str", cropStr,
+				"remote_addr", r.RemoteAddr,
+			)
+			writeParamError(w, "crop", "Invalid crop parameter: must be tight")
+			return
+		}
+		opts.Crop = true
+	}
+
+	if paddingStr := r.URL.Query().Get("crop_padding"); paddingStr != "" {
+		padding, err := strconv.Atoi(paddingStr)
+		if err != nil || padding < 0 || padding > qr.QuietZone || !opts.Crop {
+			h.logger.WarnContext(r.Context(), "Invalid crop_padding parameter",
+				"crop_padding_str", paddingStr,
+				"crop", opts.Crop,
+				"remote_addr", r.RemoteAddr,
+			)
+			writeParamError(w, "crop_padding", fmt.Sprintf("Invalid crop_padding parameter: requires crop=tight and must be between 0 and %d", qr.QuietZone))
+			return
+		}
+		opts.CropPadding = padding
+	}
+
+	border := qr.QuietZone
+	if borderStr := r.URL.Query().Get("border"); borderStr != "" {
+		var err error
+		border, err = strconv.Atoi(borderStr)
+		if err != nil || border < 0 || border > qr.MaxBorder {
+			h.logger.WarnContext(r.Context(), "Invalid border parameter",
+				"border_str", borderStr,
+				"remote_addr", r.RemoteAddr,
+			)
+			writeParamError(w, "border", fmt.Sprintf("Invalid border parameter: must be between 0 and %d", qr.MaxBorder))
+			return
+		}
+		opts.Border = &border
+	}
+
+	query := r.URL.Query()
+	if level := query.Get("ecLevel"); level != "" {
+		if !qr.IsSupportedRecoveryLevel(level) {
+			h.logger.WarnContext(r.Context(), "Invalid ecLevel parameter",
+				"ec_level", level,
+				"remote_addr", r.RemoteAddr,
+			)
+			writeParamError(w, "ecLevel", fmt.Sprintf("Invalid ecLevel parameter: must be one of %s", strings.Join(qr.RecoveryLevels, ", ")))
+			return
+		}
+		opts.RecoveryLevel = level
+	}
+
+	if mode := query.Get("mode"); mode != "" {
+		if !qr.IsSupportedMode(mode) {
+			h.logger.WarnContext(r.Context(), "Invalid mode parameter",
+				"mode", mode,
+				"remote_addr", r.RemoteAddr,
+			)
+			writeParamError(w, "mode", fmt.Sprintf("Invalid mode parameter: must be one of %s", strings.Join(qr.Modes, ", ")))
+			return
+		}
+		opts.Mode = mode
+	}
+
+	dataURI := false
+	if format := query.Get("format"); format != "" {
+		switch {
+		case format == FormatDataURI:
+			dataURI = true
+		case qr.IsSupportedFormat(format):
+			opts.Format = format
+		default:
+			h.logger.WarnContext(r.Context(), "Invalid format parameter",
+				"format", format,
+				"remote_addr", r.RemoteAddr,
+			)
+			writeParamError(w, "format", fmt.Sprintf("Invalid format parameter: must be one of %s", strings.Join(ResponseFormats, ", ")))
+			return
+		}
+	} else if accepts(r, "image/svg+xml") {
+		// An explicit format query parameter takes precedence over the Accept
+		// header, which is only consulted to opt in to SVG or a data URI.
+		opts.Format = qr.FormatSVG
+	} else if accepts(r, "text/plain") {
+		dataURI = true
+	}
+	w.Header().Add("Vary", "Accept")
+
+	// Images are served inline under a default name so a browser's Save As
+	// picks a sensible one; an explicit filename asks for a download instead.
+	disposition, filename := "inline", defaultFilename
+	if filenameStr := query.Get("filename"); filenameStr != "" {
+		if dataURI {
+			h.logger.WarnContext(r.Context(), "Filename requested with data URI output", "remote_addr", r.RemoteAddr)
+			writeParamError(w, "filename", "Invalid filename parameter: filename is not supported with format=datauri")
+			return
+		}
+		filename = sanitizeFilename(filenameStr)
+		if filename == "" {
+			h.logger.WarnContext(r.Context(), "Invalid filename parameter",
+				"filename_length", len(filenameStr),
 				"remote_addr", r.RemoteAddr,
 			)
-			http.Error(w, fmt.Sprintf("Invalid size parameter: must be between %d and %d", h.minSize, h.maxSize), http.StatusBadRequest)
+			writeParamError(w, "filename", "Invalid filename parameter: must contain at least one character other than spaces, dots and reserved characters")
+			return
+		}
+		disposition = "attachment"
+	}
+
+	if qualityStr := query.Get("quality"); qualityStr != "" {
+		quality, err := strconv.Atoi(qualityStr)
+		if err != nil || quality < qr.MinJPEGQuality || quality > qr.MaxJPEGQuality || opts.Format != qr.FormatJPEG {
+			h.logger.WarnContext(r.Context(), "Invalid quality parameter",
+				"quality_str", qualityStr,
+				"format", opts.Format,
+				"remote_addr", r.RemoteAddr,
+			)
+			writeParamError(w, "quality", fmt.Sprintf("Invalid quality parameter: requires format=jpeg and must be between %d and %d", qr.MinJPEGQuality, qr.MaxJPEGQuality))
+			return
+		}
+		opts.Quality = quality
+	}
+
+	if opts.Mode == qr.ModeByte && opts.Format == qr.FormatGIF {
+		h.logger.WarnContext(r.Context(), "Byte mode requested with animated GIF output", "remote_addr", r.RemoteAddr)
+		writeParamError(w, "mode", "Invalid mode parameter: mode=byte is not supported with format=gif")
+		return
+	}
+
+	var size int
+	if opts.Format == qr.FormatPDF {
+		if sizeStr != "" || query.Get("size_pow2") != "" {
+			h.logger.WarnContext(r.Context(), "Pixel size requested with PDF output", "remote_addr", r.RemoteAddr)
+			writeParamError(w, "size", "Invalid size parameter: format=pdf is sized with mm and dpi instead")
+			return
+		}
+		if preset != "" {
+			h.logger.WarnContext(r.Context(), "Size preset requested with PDF output", "preset", preset, "remote_addr", r.RemoteAddr)
+			writeParamError(w, "preset", "Invalid preset parameter: format=pdf is sized with mm and dpi instead")
 			return
 		}
-		size = parsedSize
-		h.logger.Debug("Size parameter parsed", "size", size)
+		widthMM, pixels, ok := h.parsePrintSize(w, r)
+		if !ok {
+			return
+		}
+		size = pixels
+		opts.Size = pixels
+		opts.WidthMM = widthMM
 	} else {
-		h.logger.Debug("Using default size", "size", defaultSize)
+		size = h.resolveSize(opts.Format, requestedSize)
+		if requestedSize == 0 {
+			h.logger.DebugContext(r.Context(), "Using default size", "format", opts.Format, "size", size)
+		}
+		if mode := query.Get("size_pow2"); mode != "" {
+			rounded, ok := roundPow2(size, mode)
+			if !ok {
+				h.logger.WarnContext(r.Context(), "Invalid size_pow2 parameter",
+					"size_pow2", mode,
+					"remote_addr", r.RemoteAddr,
+				)
+				writeParamError(w, "size_pow2", "Invalid size_pow2 parameter: must be up, down or nearest")
+				return
+			}
+			if rounded < h.minSize || rounded > maxSize {
+				h.logger.WarnContext(r.Context(), "Power-of-two size out of bounds",
+					"size", size,
+					"size_pow2", mode,
+					"rounded_size", rounded,
+					"min", h.minSize,
+					"max", maxSize,
+					"remote_addr", r.RemoteAddr,
+				)
+				writeParamError(w, "size_pow2", fmt.Sprintf("Invalid size_pow2 parameter: rounding %d %s gives %d, which is outside %d-%d", size, mode, rounded, h.minSize, maxSize))
+				return
+			}
+			h.logger.DebugContext(r.Context(), "Size rounded to power of two", "size", size, "size_pow2", mode, "rounded_size", rounded)
+			size = rounded
+		}
+		opts.Size = size
+
+		for _, param := range []string{"mm", "dpi"} {
+			if query.Get(param) != "" {
+				h.logger.WarnContext(r.Context(), "Print size requested without PDF output",
+					"param", param,
+					"format", opts.Format,
+					"remote_addr", r.RemoteAddr,
+				)
+				writeParamError(w, param, fmt.Sprintf("Invalid %s parameter: requires format=pdf", param))
+				return
+			}
+		}
+	}
+
+	if transparentStr := query.Get("transparent"); transparentStr != "" {
+		transparent, err := strconv.ParseBool(transparentStr)
+		if err != nil || (transparent && opts.Format != "" && opts.Format != qr.FormatPNG) {
+			h.logger.WarnContext(r.Context(), "Invalid transparent parameter",
+				"transparent_str", transparentStr,
+				"format", opts.Format,
+				"remote_addr", r.RemoteAddr,
+			)
+			writeParamError(w, "transparent", "Invalid transparent parameter: must be true or false, and requires format=png or format=datauri")
+			return
+		}
+		opts.Transparent = transparent
+	}
+
+	var colors qr.Colors
+	for _, param := range []struct {
+		name   string
+		target *color.Color
+	}{
+		{"fg", &colors.Foreground},
+		{"bg", &colors.Background},
+		{"eye", &colors.Eye},
+	} {
+		valueStr := query.Get(param.name)
+		if valueStr == "" {
+			continue
+		}
+		c, err := qr.ParseHexColor(valueStr)
+		if err != nil {
+			h.logger.WarnContext(r.Context(), "Invalid color parameter",
+				"param", param.name,
+				"value", valueStr,
+				"remote_addr", r.RemoteAddr,
+			)
+			writeParamError(w, param.name, fmt.Sprintf("Invalid %s parameter: must be a hex color such as 1a2b3c", param.name))
+			return
+		}
+		*param.target = c
+	}
+	opts.Colors = colors.Merge(h.colors)
+	if err := opts.Colors.CheckContrast(); err != nil {
+		h.logger.WarnContext(r.Context(), "Insufficient color contrast",
+			"colors", opts.Colors.String(),
+			"error", err,
+			"remote_addr", r.RemoteAddr,
+		)
+		// The colours are well-formed but would not scan, so this is a 422 rather
+		// than the 400 used for malformed parameters.
+		writeError(w, http.StatusUnprocessableEntity, ErrCodeLowContrast, fmt.Sprintf("Invalid colors: %v", err))
+		return
+	}
+
+	if cardStr := query.Get("card"); cardStr != "" {
+		card, err := strconv.ParseBool(cardStr)
+		if err != nil {
+			h.logger.WarnContext(r.Context(), "Invalid card parameter",
+				"card_str", cardStr,
+				"remote_addr", r.RemoteAddr,
+			)
+			writeParamError(w, "card", "Invalid card parameter: must be true or false")
+			return
+		}
+		if card {
+			style := qr.DefaultCardStyle()
+			opts.Card = &style
+		}
+	}
+
+	opts.Logo = logo
+	if scaleStr := query.Get("logo_scale"); scaleStr != "" {
+		scale, err := strconv.ParseFloat(scaleStr, 64)
+		// Negated so that NaN, which ParseFloat accepts, is rejected too.
+		if err != nil || !(scale > 0 && scale <= qr.MaxLogoScale) || logo == nil {
+			h.logger.WarnContext(r.Context(), "Invalid logo_scale parameter",
+				"logo_scale_str", scaleStr,
+				"logo", logo != nil,
+				"remote_addr", r.RemoteAddr,
+			)
+			writeParamError(w, "logo_scale", fmt.Sprintf("Invalid logo_scale parameter: requires a logo and must be greater than 0 and at most %.1f", qr.MaxLogoScale))
+			return
+		}
+		opts.LogoScale = scale
+	}
+	if logo != nil && opts.Format == qr.FormatSVG {
+		h.logger.WarnContext(r.Context(), "Logo requested with SVG output", "remote_addr", r.RemoteAddr)
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidLogo, "Invalid logo: a logo is not supported with format=svg")
+		return
+	}
+
+	if minimalStr := query.Get("minimal"); minimalStr != "" {
+		minimal, err := strconv.ParseBool(minimalStr)
+		if err != nil || (minimal && logo != nil) {
+			h.logger.WarnContext(r.Context(), "Invalid minimal parameter",
+				"minimal_str", minimalStr,
+				"logo", logo != nil,
+				"remote_addr", r.RemoteAddr,
+			)
+			writeParamError(w, "minimal", "Invalid minimal parameter: must be true or false, and is not supported with a logo, which raises the error recovery level")
+			return
+		}
+		opts.Minimal = minimal
+	}
+
+	if opts.Style == qr.StyleRounded && opts.Format == qr.FormatSVG {
+		h.logger.WarnContext(r.Context(), "Rounded style requested with SVG output", "remote_addr", r.RemoteAddr)
+		writeParamError(w, "style", "Invalid style parameter: style=rounded is not supported with format=svg")
+		return
+	}
+
+	// The service enforces the configured length and line limits, which depend
+	// on the rendered width, and its *CaptionError is reported below.
+	if caption := qr.SanitizeCaption(query.Get("caption")); caption != "" {
+		if opts.Format == qr.FormatSVG {
+			h.logger.WarnContext(r.Context(), "Caption requested with SVG output", "remote_addr", r.RemoteAddr)
+			writeParamError(w, "caption", "Invalid caption parameter: caption is not supported with format=svg")
+			return
+		}
+		opts.Caption = caption
+	}
+
+	if opts.Card != nil && opts.Format == qr.FormatSVG {
+		h.logger.WarnContext(r.Context(), "Card requested with SVG output", "remote_addr", r.RemoteAddr)
+		writeParamError(w, "card", "Invalid card parameter: card is not supported with format=svg")
+		return
+	}
+
+	if opts.Card != nil && opts.Transparent {
+		h.logger.WarnContext(r.Context(), "Card requested with a transparent background", "remote_addr", r.RemoteAddr)
+		writeParamError(w, "transparent", "Invalid transparent parameter: a transparent background is not supported with card=true")
+		return
+	}
+
+	for _, param := range []struct {
+		name   string
+		target func(*qr.CardStyle) *int
+	}{
+		{"card_radius", func(c *qr.CardStyle) *int { return &c.Radius }},
+		{"card_padding", func(c *qr.CardStyle) *int { return &c.Padding }},
+		{"card_shadow", func(c *qr.CardStyle) *int { return &c.Shadow }},
+	} {
+		valueStr := query.Get(param.name)
+		if valueStr == "" {
+			continue
+		}
+		value, err := strconv.Atoi(valueStr)
+		if err != nil || value < 0 || value > qr.MaxCardDimension || opts.Card == nil {
+			h.logger.WarnContext(r.Context(), "Invalid card style parameter",
+				"param", param.name,
+				"value", valueStr,
+				"card", opts.Card != nil,
+				"remote_addr", r.RemoteAddr,
+			)
+			writeParamError(w, param.name, fmt.Sprintf("Invalid %s parameter: requires card=true and must be between 0 and %d", param.name, qr.MaxCardDimension))
+			return
+		}
+		*param.target(opts.Card) = value
 	}
 
-	h.logger.Debug("Calling QR generation service",
-		"data_length", len(body),
+	h.logger.DebugContext(r.Context(), "Calling QR generation service",
+		"data_length", len(data),
 		"size", size,
+		"module_scale", opts.ModuleScale,
+		"sharp", opts.Sharp,
+		"style", opts.Style,
+		"crop", opts.Crop,
+		"border", border,
+		"card", opts.Card != nil,
+		"caption", opts.Caption != "",
+		"format", opts.Format,
+		"transparent", opts.Transparent,
+		"minimal", opts.Minimal,
+		"recovery_level", opts.RecoveryLevel,
+		"mode", opts.Mode,
+		"logo", logo != nil,
 	)
 
-	png, err := h.svc.Generate(body, size)
+	result, err := h.svc.Generate(r.Context(), data, opts)
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		h.writeTimeout(w, r, "generate")
+		return
+	}
+	var busyErr *limiter.BusyError
+	if errors.As(err, &busyErr) {
+		writeBusy(w, busyErr)
+		return
+	}
+	var logoErr *qr.LogoError
+	if errors.As(err, &logoErr) {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidLogo, fmt.Sprintf("Invalid logo: %s", logoErr.Reason))
+		return
+	}
+	var captionErr *qr.CaptionError
+	if errors.As(err, &captionErr) {
+		writeParamError(w, "caption", fmt.Sprintf("Invalid caption parameter: %s", captionErr.Reason))
+		return
+	}
+	var lengthErr *qr.DataTooLongError
+	if errors.As(err, &lengthErr) {
+		msg := fmt.Sprintf("Data exceeds maximum length of %d bytes", lengthErr.Limit)
+		if lengthErr.RecoveryLevel != "" {
+			msg = fmt.Sprintf("Data exceeds maximum length of %d bytes at error recovery level %s", lengthErr.Limit, lengthErr.RecoveryLevel)
+		}
+		writeError(w, http.StatusBadRequest, ErrCodeDataTooLong, msg)
+		return
+	}
+	var densityErr *qr.DensityError
+	if errors.As(err, &densityErr) {
+		msg := fmt.Sprintf("QR code too dense: use size %d or larger", densityErr.SuggestedSize)
+		if densityErr.SuggestedSize > maxSize {
+			msg = fmt.Sprintf("QR code too dense: payload is too long to scan reliably at the maximum size of %d, shorten it", maxSize)
+		}
+		writeError(w, http.StatusBadRequest, ErrCodeTooDense, msg)
+		return
+	}
 	if err != nil {
-		h.logger.Error("failed to generate QR code",
+		h.logger.ErrorContext(r.Context(), "failed to generate QR code",
 			"error", err,
-			"data_length", len(body),
+			"data_length", len(data),
 			"size", size,
 			"remote_addr", r.RemoteAddr,
 		)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, ErrCodeEncodingFailed, "Failed to generate QR code")
 		return
 	}
 
-	h.logger.Debug("QR code generated successfully",
-		"png_size", len(png),
+	img := result.Image
+	h.logger.DebugContext(r.Context(), "QR code generated successfully",
+		"image_size", len(img),
+		"content_type", result.ContentType,
+		"width", result.Width,
+		"height", result.Height,
+		"data_uri", dataURI,
 		"remote_addr", r.RemoteAddr,
 	)
 
-	w.Header().Set("Content-Type", "image/png")
-	w.Header().Set("Content-Length", strconv.Itoa(len(png)))
+	contentType := result.ContentType
+	if dataURI {
+		img = dataURIEncode(result.ContentType, img)
+		contentType = "text/plain; charset=utf-8"
+	}
+	// Annotate the request span, if tracing is enabled, with what was served.
+	trace.SpanFromContext(r.Context()).SetAttributes(
+		attribute.Int("qr.size", size),
+		attribute.String("qr.content_type", contentType),
+		attribute.Int("qr.output_bytes", len(img)),
+	)
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(img)))
+	w.Header().Set("X-QR-Size", strconv.Itoa(size))
+	w.Header().Set("X-QR-Dimensions", fmt.Sprintf("%dx%d", result.Width, result.Height))
+	w.Header().Set("X-QR-Version", strconv.Itoa(result.Version))
+	w.Header().Set("X-QR-Module-Count", strconv.Itoa(result.SymbolModules()))
+	if !dataURI {
+		w.Header().Set("Content-Disposition", contentDisposition(disposition, filename, qr.FileExtension(opts.Format)))
+	}
+	if result.Frames > 0 {
+		w.Header().Set("X-QR-Frames", strconv.Itoa(result.Frames))
+	}
+	if result.Dense {
+		w.Header().Add("X-QR-Warning", "density")
+	}
+	if opts.Format == qr.FormatJPEG && opts.Quality != 0 && opts.Quality < qr.ScannableJPEGQuality {
+		w.Header().Add("X-QR-Warning", "jpeg_quality")
+	}
+
+	// Output is deterministic for a given payload and options, so a client that
+	// already holds these bytes can revalidate a GET without downloading them.
+	// The same determinism lets shared caches such as CDNs keep the response
+	// when the deployment allows it. The tag is computed from the encoded bytes,
+	// not the options, so it can never go stale against what is sent.
+	etag := strongETag(img)
+	w.Header().Set("ETag", etag)
+	if h.cacheControl != "" {
+		w.Header().Set("Cache-Control", h.cacheControl)
+	}
+	if r.Method == http.MethodGet && etagMatches(r.Header.Get("If-None-Match"), etag) {
+		h.logger.DebugContext(r.Context(), "QR code not modified", "etag", etag, "remote_addr", r.RemoteAddr)
+		// The server drops Content-Type and Content-Length from a 304 and sends
+		// no body.
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
 	w.WriteHeader(http.StatusOK)
 
 	if fl, ok := w.(http.Flusher); ok {
 		fl.Flush()
 	}
 
-	if _, err := w.Write(png); err != nil {
-		h.logger.Error("failed to write response",
+	done := timing.Start(r.Context(), "write_response")
+	_, err = w.Write(img)
+	done()
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to write response",
 			"error", err,
-			"png_size", len(png),
+			"image_size", len(img),
 			"remote_addr", r.RemoteAddr,
 		)
 		return
 	}
 
-	h.logger.Info("QR code request completed successfully",
-		"data_length", len(body),
+	h.logger.InfoContext(r.Context(), "QR code request completed successfully",
+		"data_length", len(data),
 		"size", size,
-		"output_size", len(png),
+		"output_size", len(img),
+		"remote_addr", r.RemoteAddr,
+	)
+}
+
+// dataURIEncode wraps img in a base64 data URI of the given content type.
+func dataURIEncode(contentType string, img []byte) []byte {
+	prefix := "data:" + contentType + ";base64,"
+	out := make([]byte, len(prefix)+base64.StdEncoding.EncodedLen(len(img)))
+	copy(out, prefix)
+	base64.StdEncoding.Encode(out[len(prefix):], img)
+	return out
+}
+
+// accepts reports whether the Accept header lists mediaType explicitly with a
+// non-zero quality. Wildcards such as */* are not treated as a match.
+func accepts(r *http.Request, mediaType string) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		for _, part := range strings.Split(accept, ",") {
+			mt, params, err := mime.ParseMediaType(part)
+			if err != nil || mt != mediaType {
+				continue
+			}
+			if q, ok := params["q"]; ok {
+				if weight, err := strconv.ParseFloat(q, 64); err != nil || weight == 0 {
+					continue
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// parsePrintSize reads the mm and dpi query parameters of a PDF request and
+// returns the printed width, DefaultPDFWidthMM if mm is unset, and the pixel
+// size that prints it at the requested resolution, the deployment's PDF_DPI if
+// dpi is unset. It writes the error response and returns ok false if either is
+// invalid or the pixel size is outside the allowed range.
+func (h *Handler) parsePrintSize(w http.ResponseWriter, r *http.Request) (widthMM float64, size int, ok bool) {
+	query := r.URL.Query()
+	widthMM = qr.DefaultPDFWidthMM
+	if mmStr := query.Get("mm"); mmStr != "" {
+		var err error
+		widthMM, err = strconv.ParseFloat(mmStr, 64)
+		if err != nil || !(widthMM >= qr.MinPDFWidthMM && widthMM <= qr.MaxPDFWidthMM) {
+			h.logger.WarnContext(r.Context(), "Invalid mm parameter",
+				"mm_str", mmStr,
+				"remote_addr", r.RemoteAddr,
+			)
+			writeParamError(w, "mm", fmt.Sprintf("Invalid mm parameter: must be between %g and %g", qr.MinPDFWidthMM, qr.MaxPDFWidthMM))
+			return 0, 0, false
+		}
+	}
+
+	dpi := h.pdfDPI
+	if dpiStr := query.Get("dpi"); dpiStr != "" {
+		var err error
+		dpi, err = strconv.Atoi(dpiStr)
+		if err != nil || dpi < qr.MinPDFDPI || dpi > qr.MaxPDFDPI {
+			h.logger.WarnContext(r.Context(), "Invalid dpi parameter",
+				"dpi_str", dpiStr,
+				"remote_addr", r.RemoteAddr,
+			)
+			writeParamError(w, "dpi", fmt.Sprintf("Invalid dpi parameter: must be between %d and %d", qr.MinPDFDPI, qr.MaxPDFDPI))
+			return 0, 0, false
+		}
+	}
+
+	size = qr.PDFPixels(widthMM, dpi)
+	if maxSize := h.sizeLimit(r); size < h.minSize || size > maxSize {
+		h.logger.WarnContext(r.Context(), "Print size out of bounds",
+			"mm", widthMM,
+			"dpi", dpi,
+			"size", size,
+			"min", h.minSize,
+			"max", maxSize,
+			"remote_addr", r.RemoteAddr,
+		)
+		writeParamError(w, "mm", fmt.Sprintf("Invalid mm parameter: %gmm at %d dpi is %d pixels, which is outside %d-%d", widthMM, dpi, size, h.minSize, maxSize))
+		return 0, 0, false
+	}
+	h.logger.DebugContext(r.Context(), "Print size parsed", "mm", widthMM, "dpi", dpi, "size", size)
+	return widthMM, size, true
+}
+
+// resolveSize returns the pixel size to generate at: requested when it is set,
+// and otherwise the configured default for format, where an empty format means
+// PNG. Formats without a default of their own use config.DefaultSize.
+func (h *Handler) resolveSize(format string, requested int) int {
+	if requested != 0 {
+		return requested
+	}
+	if format == "" {
+		format = qr.FormatPNG
+	}
+	if size, ok := h.defaultSizes[format]; ok {
+		return size
+	}
+	return config.DefaultSize
+}
+
+// presetNames returns the configured size preset names, sorted.
+func (h *Handler) presetNames() []string {
+	names := make([]string, 0, len(h.sizePresets))
+	for name := range h.sizePresets {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// roundPow2 rounds size to a power of two. mode is "up", "down" or "nearest";
+// nearest rounds halfway values up. ok is false for an unknown mode.
+func roundPow2(size int, mode string) (rounded int, ok bool) {
+	if size < 1 {
+		size = 1
+	}
+	down := 1 << (bits.Len(uint(size)) - 1)
+	up := down
+	if down != size {
+		up = down << 1
+	}
+	switch mode {
+	case "up":
+		return up, true
+	case "down":
+		return down, true
+	case "nearest":
+		if size-down < up-size {
+			return down, true
+		}
+		return up, true
+	}
+	return 0, false
+}
+
+// insecureURL reports whether data parses as an absolute http:// URL and returns
+// the parsed URL. Payloads that are not URLs are never reported.
+func insecureURL(data []byte) (*url.URL, bool) {
+	u, err := url.Parse(strings.TrimSpace(string(data)))
+	if err != nil || !strings.EqualFold(u.Scheme, "http") || u.Host == "" {
+		return nil, false
+	}
+	return u, true
+}
+
+// writeTimeout responds with 503 Service Unavailable when the request deadline
+// expired during stage. If the client disconnected instead, there is no one to
+// read a response, so only the status is recorded, as 499 Client Closed Request
+// in the style of nginx.
+func (h *Handler) writeTimeout(w http.ResponseWriter, r *http.Request, stage string) {
+	if errors.Is(r.Context().Err(), context.Canceled) {
+		h.logger.InfoContext(r.Context(), "Client disconnected, request abandoned",
+			"stage", stage,
+			"remote_addr", r.RemoteAddr,
+		)
+		w.WriteHeader(statusClientClosedRequest)
+		return
+	}
+	h.logger.WarnContext(r.Context(), "Request timed out",
+		"stage", stage,
 		"remote_addr", r.RemoteAddr,
 	)
+	writeError(w, http.StatusServiceUnavailable, ErrCodeTimeout, "Request timed out")
+}
+
+// writeBusy responds with 503 Service Unavailable when the concurrent
+// generation limit turned the request away, telling the client when to retry.
+func writeBusy(w http.ResponseWriter, err *limiter.BusyError) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(err.RetryAfter/time.Second)))
+	writeError(w, http.StatusServiceUnavailable, ErrCodeOverloaded, "Too many QR codes are being generated, please retry")
 }
 
-// HealthCheck handles GET /health requests for liveness/readiness probes.
+// HealthCheck handles GET /health and GET /healthz requests for liveness probes.
+// It reports ok whenever the process is running and able to serve HTTP.
 func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
-	h.logger.Debug("Health check request received",
+	h.logger.DebugContext(r.Context(), "Health check request received",
 		"method", r.Method,
 		"remote_addr", r.RemoteAddr,
 	)
+	h.writeStatus(w, r, http.StatusOK, "ok")
+}
+
+// SetReady marks whether the service should receive traffic. It starts false;
+// main sets it once the routes and listeners are up and clears it when graceful
+// shutdown begins.
+func (h *Handler) SetReady(ready bool) {
+	h.ready.Store(ready)
+}
 
+// ReadinessCheck handles GET /readyz requests for readiness probes. It returns
+// 503 until the service is marked ready and again once shutdown has begun.
+func (h *Handler) ReadinessCheck(w http.ResponseWriter, r *http.Request) {
+	if !h.ready.Load() {
+		h.logger.DebugContext(r.Context(), "Readiness check failed: service not ready",
+			"remote_addr", r.RemoteAddr,
+		)
+		h.writeStatus(w, r, http.StatusServiceUnavailable, "not_ready")
+		return
+	}
+	h.writeStatus(w, r, http.StatusOK, "ready")
+}
+
+// writeStatus writes a probe response of the form {"status": status}.
+func (h *Handler) writeStatus(w http.ResponseWriter, r *http.Request, code int, status string) {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
+	w.WriteHeader(code)
 
 	if fl, ok := w.(http.Flusher); ok {
 		fl.Flush()
 	}
 
 	encoder := json.NewEncoder(w)
-	if err := encoder.Encode(map[string]string{"status": "ok"}); err != nil {
-		h.logger.Error("failed to encode health check response",
+	if err := encoder.Encode(map[string]string{"status": status}); err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to encode health check response",
 			"error", err,
 			"remote_addr", r.RemoteAddr,
 		)
@@ -0,0 +1,73 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/qr"
+)
+
+func TestDecodeLimits(t *testing.T) {
+	var img bytes.Buffer
+	if err := png.Encode(&img, image.NewGray(image.Rect(0, 0, 64, 64))); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		decodeMax  int64
+		maxPixels  int64
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "within limits", decodeMax: 524288, maxPixels: 4096, wantStatus: http.StatusUnprocessableEntity, wantCode: ErrCodeNoCodeFound},
+		{name: "upload over DECODE_MAX_BYTES", decodeMax: int64(img.Len()) - 1, maxPixels: 4096, wantStatus: http.StatusRequestEntityTooLarge, wantCode: ErrCodeBodyTooLarge},
+		{name: "image over DECODE_MAX_PIXELS", decodeMax: 524288, maxPixels: 4095, wantStatus: http.StatusBadRequest, wantCode: ErrCodeInvalidImage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := qr.NewService(testLogger, 64, 2048, 0, qr.DefaultCaptionMaxChars, qr.DefaultCaptionMaxLines, 3, false)
+			reader := qr.NewReader(testLogger, 64, 64, tt.maxPixels)
+			// MAX_BODY_SIZE is left far above DECODE_MAX_BYTES to show the
+			// decode limit applies on its own.
+			h := NewHandler(svc, reader, testLogger, 1<<20, tt.decodeMax, 64, 2048, 0, false, qr.Colors{}, 300, nil, nil, "", nil, BatchLimits{MaxItems: 10, Concurrency: 1})
+
+			req := httptest.NewRequest(http.MethodPost, "/decode", bytes.NewReader(img.Bytes()))
+			req.Header.Set("Content-Type", "image/png")
+			rec := httptest.NewRecorder()
+			h.Decode(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			var resp errorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("invalid error body %q: %v", rec.Body, err)
+			}
+			if resp.Error.Code != tt.wantCode {
+				t.Errorf("error code = %q, want %q (message %q)", resp.Error.Code, tt.wantCode, resp.Error.Message)
+			}
+		})
+	}
+}
@@ -18,15 +18,44 @@
 package http
 
 import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/logger"
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/requestid"
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/timing"
 )
 
+// RequestIDMiddleware gives every request a correlation ID. A valid incoming
+// X-Request-ID is kept, otherwise a new UUID is generated. The ID is stored in the
+// request context, where loggers pick it up, and echoed in the response header.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestid.Header)
+		if !requestid.IsValid(id) {
+			id = requestid.New()
+		}
+		w.Header().Set(requestid.Header, id)
+		next.ServeHTTP(w, r.WithContext(requestid.WithID(r.Context(), id)))
+	})
+}
+
 // RequestLoggingMiddleware logs incoming requests with metadata.
 func RequestLoggingMiddleware(logger *slog.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			logger.Debug("Received request",
+			logger.DebugContext(r.Context(), "Received request",
 				"method", r.Method,
 				"path", r.URL.Path,
 				"remote_addr", r.RemoteAddr,
@@ -38,6 +67,248 @@ func RequestLoggingMiddleware(logger *slog.Logger) func(http.Handler) http.Handl
 	}
 }
 
+// AccessLogMiddleware writes one structured log line per completed request with
+// its method, path, status, response size, duration and remote address. It
+// belongs inside RequestIDMiddleware so the line carries the request ID, and
+// outside compression so the size is the number of bytes actually sent.
+//
+// Successful requests whose path starts with one of the exclude prefixes, such
+// as frequent health probes, are logged only one in every sampleEvery times,
+// or not at all when sampleEvery is 0. Excluded requests that fail with a 4xx
+// or 5xx status are always logged.
+func AccessLogMiddleware(logger *slog.Logger, exclude []string, sampleEvery int) func(http.Handler) http.Handler {
+	var skipped atomic.Uint64
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			aw := &accessWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(aw, r)
+			if aw.status < http.StatusBadRequest && hasAnyPrefix(r.URL.Path, exclude) {
+				if sampleEvery == 0 || skipped.Add(1)%uint64(sampleEvery) != 0 {
+					return
+				}
+			}
+			logger.InfoContext(r.Context(), "Request completed",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", aw.status,
+				"bytes", aw.bytes,
+				"duration", time.Since(start),
+				"remote_ip", remoteHost(r),
+			)
+		})
+	}
+}
+
+// hasAnyPrefix reports whether path starts with any of prefixes.
+func hasAnyPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// accessWriter records the status code and the number of body bytes written
+// through it.
+type accessWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (w *accessWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *accessWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush forwards to the underlying writer so streaming handlers keep working.
+func (w *accessWriter) Flush() {
+	if fl, ok := w.ResponseWriter.(http.Flusher); ok {
+		fl.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *accessWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// remoteHost returns the host part of the request's remote address, or the
+// address unchanged when it has no port, as for Unix socket peers.
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// APIKeyHeader is the request header that carries the API key.
+const APIKeyHeader = "X-API-Key"
+
+// authenticatedKey is the context key marking a request authenticated with any
+// API key.
+type authenticatedKey struct{}
+
+// trustedKey is the context key marking a request authenticated with a trusted
+// API key.
+type trustedKey struct{}
+
+// isAuthenticated reports whether the request behind ctx was authenticated with
+// one of the API keys, trusted or not.
+func isAuthenticated(ctx context.Context) bool {
+	authenticated, _ := ctx.Value(authenticatedKey{}).(bool)
+	return authenticated
+}
+
+// isTrusted reports whether the request behind ctx was authenticated with one of
+// the trusted API keys.
+func isTrusted(ctx context.Context) bool {
+	trusted, _ := ctx.Value(trustedKey{}).(bool)
+	return trusted
+}
+
+// APIKeyMiddleware responds with 401 Unauthorized unless the request carries one
+// of keys or trusted in the X-API-Key header. Requests made with a trusted key are
+// marked so handlers can relax their limits for them. Requests for the exempt
+// paths, and every request when both lists are empty, pass through. Keys are
+// compared in constant time and are never logged.
+func APIKeyMiddleware(logger *slog.Logger, keys, trusted []string, exempt ...string) func(http.Handler) http.Handler {
+	digests := make([][sha256.Size]byte, 0, len(keys)+len(trusted))
+	for _, key := range append(append([]string(nil), keys...), trusted...) {
+		digests = append(digests, sha256.Sum256([]byte(key)))
+	}
+	exemptPaths := make(map[string]bool)
+	for _, path := range exempt {
+		exemptPaths[path] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		if len(digests) == 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if exemptPaths[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := r.Header.Get(APIKeyHeader)
+			if key == "" {
+				logger.WarnContext(r.Context(), "Request without API key",
+					"method", r.Method,
+					"path", r.URL.Path,
+					"remote_addr", r.RemoteAddr,
+				)
+				writeError(w, http.StatusUnauthorized, ErrCodeMissingAPIKey, "Missing API key")
+				return
+			}
+
+			// Hashing first gives equal-length inputs, and every key is checked
+			// so the time taken does not reveal which one matched.
+			digest := sha256.Sum256([]byte(key))
+			match, trustedMatch := 0, 0
+			for i := range digests {
+				eq := subtle.ConstantTimeCompare(digest[:], digests[i][:])
+				match |= eq
+				if i >= len(keys) {
+					trustedMatch |= eq
+				}
+			}
+			if match == 0 {
+				logger.WarnContext(r.Context(), "Request with invalid API key",
+					"method", r.Method,
+					"path", r.URL.Path,
+					"remote_addr", r.RemoteAddr,
+				)
+				writeError(w, http.StatusUnauthorized, ErrCodeInvalidAPIKey, "Invalid API key")
+				return
+			}
+			ctx := context.WithValue(r.Context(), authenticatedKey{}, true)
+			if trustedMatch == 1 {
+				ctx = context.WithValue(ctx, trustedKey{}, true)
+			}
+			r = r.WithContext(ctx)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// DebugHeader is the request header that asks for one request to be logged at
+// debug level.
+const DebugHeader = "X-Debug"
+
+// DebugHeaderMiddleware logs every *Context call made while serving a request
+// that sends "X-Debug: true" at debug level, whatever LOG_LEVEL is. The header
+// is honored only when enabled is set and the request was authenticated by
+// APIKeyMiddleware, which must run first; anonymous callers, including every
+// caller when authentication is off, cannot raise the service's log volume.
+func DebugHeaderMiddleware(log *slog.Logger, enabled bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !enabled {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if value := r.Header.Get(DebugHeader); value != "" {
+				if debug, err := strconv.ParseBool(value); err == nil && debug && isAuthenticated(r.Context()) {
+					r = r.WithContext(logger.WithDebug(r.Context()))
+					log.InfoContext(r.Context(), "Debug logging enabled for request",
+						"method", r.Method,
+						"path", r.URL.Path,
+						"remote_addr", r.RemoteAddr,
+					)
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// URILengthMiddleware rejects requests whose request target, path and query
+// string together, is longer than maxLength bytes with 414 URI Too Long. It
+// checks the raw target before anything parses the query, so oversized GET
+// requests cost no more than reading the request line. Zero disables the check.
+func URILengthMiddleware(logger *slog.Logger, maxLength int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if maxLength <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			length := len(r.RequestURI)
+			if length == 0 {
+				// Requests built in-process have no raw target.
+				length = len(r.URL.RequestURI())
+			}
+			if length > maxLength {
+				logger.WarnContext(r.Context(), "Request URI too long",
+					"method", r.Method,
+					"path", r.URL.Path,
+					"uri_length", length,
+					"max_length", maxLength,
+					"remote_addr", r.RemoteAddr,
+				)
+				writeError(w, http.StatusRequestURITooLong, ErrCodeURITooLong,
+					fmt.Sprintf("Request URI is too long: at most %d bytes; POST the data in the request body instead", maxLength))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // MethodMiddleware restricts requests to specific HTTP methods.
 func MethodMiddleware(allowedMethods ...string) func(http.Handler) http.Handler {
 	methodMap := make(map[string]bool)
@@ -48,10 +319,155 @@ func MethodMiddleware(allowedMethods ...string) func(http.Handler) http.Handler
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			if !methodMap[r.Method] {
-				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				writeError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "Method not allowed")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// FeatureMiddleware responds with 404 Not Found when the named feature is disabled.
+func FeatureMiddleware(logger *slog.Logger, feature string, enabled bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if enabled {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logger.DebugContext(r.Context(), "Request for disabled feature",
+				"feature", feature,
+				"path", r.URL.Path,
+				"remote_addr", r.RemoteAddr,
+			)
+			writeError(w, http.StatusNotFound, ErrCodeNotFound, "Not found")
+		})
+	}
+}
+
+// DrainState records whether the server has begun graceful shutdown and tracks
+// the requests still being handled, so shutdown can wait for them to finish.
+type DrainState struct {
+	mu       sync.Mutex
+	draining atomic.Bool
+	active   sync.WaitGroup
+	inFlight atomic.Int64
+}
+
+// StartDraining marks the server as shutting down. Requests that have not yet
+// been admitted are rejected from then on. It is safe to call more than once.
+func (d *DrainState) StartDraining() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.draining.Store(true)
+}
+
+// Draining reports whether graceful shutdown has begun.
+func (d *DrainState) Draining() bool {
+	return d.draining.Load()
+}
+
+// InFlight returns the number of admitted requests that have not finished.
+func (d *DrainState) InFlight() int64 {
+	return d.inFlight.Load()
+}
+
+// admit registers a new request unless draining has begun. Admission and
+// StartDraining share a lock so that no request is added once Wait may be
+// running.
+func (d *DrainState) admit() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.draining.Load() {
+		return false
+	}
+	d.active.Add(1)
+	d.inFlight.Add(1)
+	return true
+}
+
+// done marks an admitted request as finished.
+func (d *DrainState) done() {
+	d.inFlight.Add(-1)
+	d.active.Done()
+}
+
+// Wait blocks until every admitted request has finished or ctx is done. It
+// should be called after StartDraining. When ctx ends first it returns the
+// number of requests still in flight along with the context error.
+func (d *DrainState) Wait(ctx context.Context) (int64, error) {
+	finished := make(chan struct{})
+	go func() {
+		d.active.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		return 0, nil
+	case <-ctx.Done():
+		return d.inFlight.Load(), ctx.Err()
+	}
+}
+
+// ShutdownMiddleware rejects new requests with 503 Service Unavailable once draining
+// has begun, while requests already inside the handler are tracked so that
+// shutdown can wait for them to finish.
+func ShutdownMiddleware(logger *slog.Logger, state *DrainState, retryAfter time.Duration) func(http.Handler) http.Handler {
+	retryAfterSecs := strconv.Itoa(int(retryAfter.Round(time.Second) / time.Second))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !state.admit() {
+				logger.InfoContext(r.Context(), "Rejecting request during shutdown",
+					"method", r.Method,
+					"path", r.URL.Path,
+					"remote_addr", r.RemoteAddr,
+				)
+				w.Header().Set("Connection", "close")
+				w.Header().Set("Retry-After", retryAfterSecs)
+				writeError(w, http.StatusServiceUnavailable, ErrCodeShuttingDown, "Service is shutting down, please retry")
 				return
 			}
+			defer state.done()
 			next.ServeHTTP(w, r)
 		})
 	}
 }
+
+// TimeoutMiddleware gives each request a single deadline shared by every stage that
+// handles it, from reading the body to writing the response. Body reads are bounded
+// by the same deadline, and the slowest recorded stage is logged when the request ends.
+func TimeoutMiddleware(logger *slog.Logger, timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			ctx, rec := timing.WithRecorder(ctx)
+
+			deadline, _ := ctx.Deadline()
+			if err := http.NewResponseController(w).SetReadDeadline(deadline); err != nil {
+				logger.DebugContext(r.Context(), "Request deadline not applied to body reads", "error", err)
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+
+			attrs := []any{
+				"method", r.Method,
+				"path", r.URL.Path,
+				"elapsed", time.Since(start),
+				"timeout", timeout,
+			}
+			if slowest, ok := rec.Slowest(); ok {
+				attrs = append(attrs,
+					"slowest_stage", slowest.Name,
+					"slowest_stage_duration", slowest.Duration,
+				)
+			}
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				logger.WarnContext(r.Context(), "Request exceeded timeout", attrs...)
+				return
+			}
+			logger.DebugContext(r.Context(), "Request stage timings", attrs...)
+		})
+	}
+}
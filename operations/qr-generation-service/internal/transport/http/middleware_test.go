@@ -0,0 +1,124 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/logger"
+)
+
+func TestDebugHeaderMiddleware(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+		// keys turns authentication on when non-empty.
+		keys    []string
+		headers map[string]string
+		want    bool
+	}{
+		{
+			name:    "authenticated request",
+			enabled: true,
+			keys:    []string{"key"},
+			headers: map[string]string{APIKeyHeader: "key", DebugHeader: "true"},
+			want:    true,
+		},
+		{
+			name:    "trusted key",
+			enabled: true,
+			keys:    []string{"key"},
+			headers: map[string]string{APIKeyHeader: "trusted", DebugHeader: "true"},
+			want:    true,
+		},
+		{
+			name:    "header false",
+			enabled: true,
+			keys:    []string{"key"},
+			headers: map[string]string{APIKeyHeader: "key", DebugHeader: "false"},
+		},
+		{
+			name:    "header not a boolean",
+			enabled: true,
+			keys:    []string{"key"},
+			headers: map[string]string{APIKeyHeader: "key", DebugHeader: "yes please"},
+		},
+		{
+			name:    "flag off",
+			keys:    []string{"key"},
+			headers: map[string]string{APIKeyHeader: "key", DebugHeader: "true"},
+		},
+		{
+			name:    "authentication off",
+			enabled: true,
+			headers: map[string]string{DebugHeader: "true"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got, reached bool
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				reached = true
+				got = logger.IsDebug(r.Context())
+			})
+			h := Chain(
+				APIKeyMiddleware(testLogger, tt.keys, trustedKeys(tt.keys)),
+				DebugHeaderMiddleware(testLogger, tt.enabled),
+			)(next)
+
+			req := httptest.NewRequest(http.MethodGet, "/generate", nil)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req)
+			if !reached {
+				t.Fatal("request did not reach the wrapped handler")
+			}
+			if got != tt.want {
+				t.Errorf("debug logging = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("exempt path", func(t *testing.T) {
+		var got bool
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = logger.IsDebug(r.Context())
+		})
+		h := Chain(
+			APIKeyMiddleware(testLogger, []string{"key"}, nil, "/health"),
+			DebugHeaderMiddleware(testLogger, true),
+		)(next)
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		req.Header.Set(DebugHeader, "true")
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		if got {
+			t.Error("debug logging enabled for an unauthenticated request to an exempt path")
+		}
+	})
+}
+
+// trustedKeys returns the trusted key list for a test that turns
+// authentication on with keys.
+func trustedKeys(keys []string) []string {
+	if len(keys) == 0 {
+		return nil
+	}
+	return []string{"trusted"}
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// rateLimitSweepInterval is how often idle client entries are evicted.
+const rateLimitSweepInterval = time.Minute
+
+// RateLimiter applies a token bucket per client IP. Tokens refill at a steady
+// rate up to a burst size, so short spikes are allowed while sustained traffic
+// is held to the configured rate. Clients idle long enough for their bucket to
+// refill completely are evicted periodically.
+type RateLimiter struct {
+	logger         *slog.Logger
+	limit          rate.Limit
+	burst          int
+	trustedProxies int
+	idleTTL        time.Duration
+
+	mu      sync.Mutex
+	clients map[string]*rateClient
+
+	stop chan struct{}
+	once sync.Once
+}
+
+type rateClient struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// NewRateLimiter creates a limiter allowing rps requests per second per client
+// with bursts of up to burst requests. trustedProxies is the number of reverse
+// proxies in front of the service; when non-zero the client IP is taken from
+// X-Forwarded-For that many entries from the right, otherwise the connection's
+// remote address is used. Call Close to stop the eviction goroutine.
+func NewRateLimiter(logger *slog.Logger, rps float64, burst, trustedProxies int) *RateLimiter {
+	// An idle client's bucket is full again after burst/rps seconds, at which
+	// point forgetting it makes no difference.
+	idleTTL := max(time.Duration(float64(burst)/rps*float64(time.Second)), rateLimitSweepInterval)
+	rl := &RateLimiter{
+		logger:         logger,
+		limit:          rate.Limit(rps),
+		burst:          burst,
+		trustedProxies: trustedProxies,
+		idleTTL:        idleTTL,
+		clients:        make(map[string]*rateClient),
+		stop:           make(chan struct{}),
+	}
+	go rl.sweep()
+	return rl
+}
+
+// Middleware responds with 429 Too Many Requests and a Retry-After header once
+// the client has used up its tokens.
+func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := rl.clientIP(r)
+		reservation := rl.limiter(ip).Reserve()
+		if delay := reservation.Delay(); delay > 0 {
+			// Give the token back: a rejected request should not push the
+			// client's next allowed request further out.
+			reservation.Cancel()
+			retryAfter := int(math.Ceil(delay.Seconds()))
+			rl.logger.WarnContext(r.Context(), "Rate limit exceeded",
+				"client_ip", ip,
+				"path", r.URL.Path,
+				"retry_after_seconds", retryAfter,
+			)
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			writeError(w, http.StatusTooManyRequests, ErrCodeRateLimited, "Rate limit exceeded, please retry later")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Close stops the background eviction of idle clients.
+func (rl *RateLimiter) Close() {
+	rl.once.Do(func() { close(rl.stop) })
+}
+
+// limiter returns the token bucket for ip, creating it on first use.
+func (rl *RateLimiter) limiter(ip string) *rate.Limiter {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	c, ok := rl.clients[ip]
+	if !ok {
+		c = &rateClient{limiter: rate.NewLimiter(rl.limit, rl.burst)}
+		rl.clients[ip] = c
+	}
+	c.lastSeen = time.Now()
+	return c.limiter
+}
+
+// sweep evicts clients that have been idle for longer than idleTTL until Close is called.
+func (rl *RateLimiter) sweep() {
+	ticker := time.NewTicker(rateLimitSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-rl.stop:
+			return
+		case now := <-ticker.C:
+			rl.mu.Lock()
+			for ip, c := range rl.clients {
+				if now.Sub(c.lastSeen) > rl.idleTTL {
+					delete(rl.clients, ip)
+				}
+			}
+			remaining := len(rl.clients)
+			rl.mu.Unlock()
+			rl.logger.Debug("Evicted idle rate limit clients", "clients", remaining)
+		}
+	}
+}
+
+// clientIP returns the address requests are counted against. X-Forwarded-For is
+// only consulted when proxies are trusted, and then from the right, since entries
+// further left are supplied by the client and can be forged.
+func (rl *RateLimiter) clientIP(r *http.Request) string {
+	if rl.trustedProxies > 0 {
+		var hops []string
+		for _, header := range r.Header.Values("X-Forwarded-For") {
+			for _, hop := range strings.Split(header, ",") {
+				if hop = strings.TrimSpace(hop); hop != "" {
+					hops = append(hops, hop)
+				}
+			}
+		}
+		if len(hops) > 0 {
+			return hops[max(len(hops)-rl.trustedProxies, 0)]
+		}
+	}
+
+	return remoteHost(r)
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/config"
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/qr"
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/version"
+)
+
+// probePaths are the health endpoints that must keep working without credentials.
+var probePaths = []string{"/health", "/healthz", "/readyz", "/selftest"}
+
+// Chain composes middlewares into one. The first middleware listed is the
+// outermost, so a request passes through them in the order given before it
+// reaches the wrapped handler.
+func Chain(middlewares ...func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			next = middlewares[i](next)
+		}
+		return next
+	}
+}
+
+// RouteDeps holds the shared state that BuildHandler wires into the routes. The
+// caller keeps its own references to manage readiness, draining and shutdown.
+type RouteDeps struct {
+	// Drain tracks in-flight requests and rejects new work once shutdown begins.
+	Drain *DrainState
+	// RateLimit is shared by the generate and decode routes, so a client's budget
+	// covers all of them together. Nil disables rate limiting.
+	RateLimit func(http.Handler) http.Handler
+	// Instrument returns the metrics middleware for a route. Nil disables it.
+	Instrument func(route string) func(http.Handler) http.Handler
+	// Trace returns the tracing middleware for a route. Nil disables it.
+	Trace func(route string) func(http.Handler) http.Handler
+	// Metrics serves GET /metrics. Nil leaves the route unregistered.
+	Metrics http.Handler
+}
+
+// BuildHandler registers every route served by h and returns the fully wrapped
+// http.Handler. Each generate and decode route is wrapped, outermost first, in
+// tracing, request logging, metrics, rate limiting, drain tracking, the feature
+// switch, the method check and the request timeout; /selftest gets the same
+// protection without tracing, metrics or a feature switch. The mux as a whole is
+// wrapped in request IDs, access logging, the URI length limit, CORS,
+// compression, API key authentication and the X-Debug override, in that order.
+func BuildHandler(cfg *config.Config, h *Handler, logger *slog.Logger, deps RouteDeps) http.Handler {
+	passThrough := func(next http.Handler) http.Handler { return next }
+	perRoute := func(mw func(string) func(http.Handler) http.Handler, route string) func(http.Handler) http.Handler {
+		if mw == nil {
+			return passThrough
+		}
+		return mw(route)
+	}
+	rateLimit := deps.RateLimit
+	if rateLimit == nil {
+		rateLimit = passThrough
+	}
+
+	mux := http.NewServeMux()
+	var endpoints []string
+	handle := func(route string, handler http.Handler) {
+		mux.Handle(route, handler)
+		endpoints = append(endpoints, route)
+	}
+	work := func(route, feature string, handler http.HandlerFunc, methods ...string) {
+		handle(route, Chain(
+			perRoute(deps.Trace, route),
+			RequestLoggingMiddleware(logger),
+			perRoute(deps.Instrument, route),
+			rateLimit,
+			ShutdownMiddleware(logger, deps.Drain, cfg.RetryAfter),
+			FeatureMiddleware(logger, feature, cfg.FeatureEnabled(feature)),
+			MethodMiddleware(methods...),
+			TimeoutMiddleware(logger, cfg.RequestTimeout),
+		)(handler))
+	}
+
+	work("/generate", config.FeatureGenerate, h.Generate, http.MethodGet, http.MethodPost)
+	work("/generate/upi", config.FeatureUPI, h.GenerateUPI, http.MethodPost)
+	work("/generate/wifi", config.FeatureWiFi, h.GenerateWiFi, http.MethodPost)
+	work("/generate/vcard", config.FeatureVCard, h.GenerateVCard, http.MethodPost)
+	work("/generate/sms", config.FeatureSMS, h.GenerateSMS, http.MethodPost)
+	work("/generate/email", config.FeatureEmail, h.GenerateEmail, http.MethodPost)
+	work("/generate/event", config.FeatureEvent, h.GenerateEvent, http.MethodPost)
+	work("/generate/geo", config.FeatureGeo, h.GenerateGeo, http.MethodPost)
+	work("/generate/auto", config.FeatureGenerate, h.GenerateAuto, http.MethodPost)
+	work("/generate/batch", config.FeatureBatch, h.GenerateBatch, http.MethodPost)
+	work("/decode", config.FeatureDecode, h.Decode, http.MethodPost)
+
+	healthHandler := RequestLoggingMiddleware(logger)(http.HandlerFunc(h.HealthCheck))
+	handle("/health", healthHandler)
+	handle("/healthz", healthHandler)
+	handle("/readyz", RequestLoggingMiddleware(logger)(http.HandlerFunc(h.ReadinessCheck)))
+	// The self-test needs no API key but runs a real encode and decode, so it
+	// shares the generate routes' rate limit, drain tracking and timeout.
+	handle("/selftest", Chain(
+		RequestLoggingMiddleware(logger),
+		rateLimit,
+		ShutdownMiddleware(logger, deps.Drain, cfg.RetryAfter),
+		MethodMiddleware(http.MethodGet),
+		TimeoutMiddleware(logger, cfg.RequestTimeout),
+	)(http.HandlerFunc(h.SelfTest)))
+
+	capabilities := CapabilitiesHandler(logger, Capabilities{
+		Symbologies:          qr.Symbologies,
+		Formats:              ResponseFormats,
+		MinSize:              cfg.MinSize,
+		MaxSize:              cfg.MaxSize,
+		DefaultSize:          cfg.DefaultSize,
+		DefaultSizes:         cfg.DefaultSizes,
+		RecoveryLevels:       qr.RecoveryLevels,
+		DefaultRecoveryLevel: qr.DefaultRecoveryLevel,
+		Options:              GenerateOptions,
+		Features:             cfg.EnabledFeatures(),
+	})
+	handle("/capabilities", Chain(
+		RequestLoggingMiddleware(logger),
+		MethodMiddleware(http.MethodGet),
+	)(capabilities))
+
+	handle("/version", Chain(
+		RequestLoggingMiddleware(logger),
+		MethodMiddleware(http.MethodGet),
+	)(VersionHandler(logger, version.Get())))
+
+	if deps.Metrics != nil {
+		handle("/metrics", MethodMiddleware(http.MethodGet)(deps.Metrics))
+	}
+	logger.Debug("HTTP routes registered", "endpoints", endpoints)
+
+	if len(cfg.APIKeys) > 0 || len(cfg.TrustedKeys) > 0 {
+		logger.Info("API key authentication enabled", "keys", len(cfg.APIKeys), "trusted_keys", len(cfg.TrustedKeys), "exempt", probePaths)
+	} else {
+		logger.Warn("API key authentication disabled: API_KEYS is not set")
+	}
+	if cfg.DebugHeader {
+		logger.Info("X-Debug header honored for authenticated requests")
+	}
+	if len(cfg.AccessLogSkip) > 0 {
+		logger.Info("Access log exclusions", "paths", cfg.AccessLogSkip, "sample_every", cfg.AccessLogSample)
+	}
+	if len(cfg.CORSOrigins) > 0 {
+		logger.Info("CORS enabled", "allowed_origins", cfg.CORSOrigins)
+	}
+
+	// CORS sits outside authentication because browsers send preflight
+	// requests without credentials.
+	return Chain(
+		RequestIDMiddleware,
+		AccessLogMiddleware(logger, cfg.AccessLogSkip, cfg.AccessLogSample),
+		URILengthMiddleware(logger, cfg.MaxURILength),
+		CORSMiddleware(logger, cfg.CORSOrigins),
+		CompressionMiddleware(logger, cfg.CompressMin),
+		APIKeyMiddleware(logger, cfg.APIKeys, cfg.TrustedKeys, probePaths...),
+		DebugHeaderMiddleware(logger, cfg.DebugHeader),
+	)(mux)
+}
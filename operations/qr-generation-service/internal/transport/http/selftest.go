@@ -0,0 +1,109 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/qr"
+)
+
+const (
+	// selfTestPayload is the text every self-test encodes, followed by a run
+	// number so that a generation cache cannot answer in place of the encoder.
+	selfTestPayload = "qr-generation-service self-test"
+	// selfTestCaption is drawn beneath the code so the caption font is loaded too.
+	selfTestCaption = "self-test"
+	// selfTestTimeout bounds one encode and decode round trip.
+	selfTestTimeout = 5 * time.Second
+	// selfTestInterval is how long a result is reused. The endpoint needs no
+	// API key, so callers cannot make it generate more often than this.
+	selfTestInterval = 5 * time.Second
+)
+
+// selfTest holds the outcome of the latest self-test run.
+type selfTest struct {
+	mu   sync.Mutex
+	runs int
+	at   time.Time
+	err  error
+}
+
+// SelfTest handles GET /selftest requests for deep readiness checks. It
+// generates a QR code for a known payload, decodes it back and returns 200 only
+// if the decoded text matches, so a broken encoder, decoder or asset is caught
+// where /readyz would still pass.
+func (h *Handler) SelfTest(w http.ResponseWriter, r *http.Request) {
+	if err := h.runSelfTest(r.Context()); err != nil {
+		h.logger.ErrorContext(r.Context(), "Self-test failed", "error", err, "remote_addr", r.RemoteAddr)
+		h.writeStatus(w, r, http.StatusServiceUnavailable, "failed")
+		return
+	}
+	h.writeStatus(w, r, http.StatusOK, "ok")
+}
+
+// runSelfTest returns the result of the last round trip if it is recent enough,
+// and otherwise runs a new one. Concurrent callers wait for the same run.
+func (h *Handler) runSelfTest(ctx context.Context) error {
+	h.selfTest.mu.Lock()
+	defer h.selfTest.mu.Unlock()
+	if !h.selfTest.at.IsZero() && time.Since(h.selfTest.at) < selfTestInterval {
+		return h.selfTest.err
+	}
+
+	h.selfTest.runs++
+	payload := fmt.Sprintf("%s #%d", selfTestPayload, h.selfTest.runs)
+	testCtx, cancel := context.WithTimeout(ctx, selfTestTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := h.roundTrip(testCtx, payload)
+	if err != nil && ctx.Err() != nil {
+		// A caller that gave up says nothing about the pipeline, so the next
+		// request tries again instead of reusing the failure.
+		return err
+	}
+	h.selfTest.at = time.Now()
+	h.selfTest.err = err
+	h.logger.DebugContext(ctx, "Self-test completed", "run", h.selfTest.runs, "duration", time.Since(start), "error", err)
+	return err
+}
+
+// roundTrip encodes payload with the deployment's default colors and decodes the
+// image back, returning an error naming the stage that failed.
+func (h *Handler) roundTrip(ctx context.Context, payload string) error {
+	result, err := h.svc.Generate(ctx, []byte(payload), qr.Options{
+		Size:    h.resolveSize(qr.FormatPNG, 0),
+		Caption: selfTestCaption,
+		Colors:  h.colors,
+	})
+	if err != nil {
+		return fmt.Errorf("generate: %w", err)
+	}
+	text, err := h.reader.Decode(ctx, result.Image)
+	if err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+	if text != payload {
+		return fmt.Errorf("decoded %q, want %q", text, payload)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/fetch"
+	"github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/timing"
+)
+
+// Values of the source query parameter of /generate.
+const (
+	// SourceData encodes the data as given, even if it starts with "@".
+	SourceData = "data"
+	// SourceURL treats the data as a URL and encodes the document it points to.
+	SourceURL = "url"
+)
+
+// resolveSource returns the data /generate should encode. By default that is
+// data itself, but with source=url, or when remote fetching is enabled and
+// data is an http(s) URL prefixed with "@", it is the body fetched from that
+// URL. On failure it writes the error response and returns false.
+func (h *Handler) resolveSource(w http.ResponseWriter, r *http.Request, data []byte) ([]byte, bool) {
+	var rawURL string
+	switch source := r.URL.Query().Get("source"); source {
+	case SourceURL:
+		rawURL = strings.TrimSpace(string(data))
+	case SourceData:
+		return data, true
+	case "":
+		rest, ok := strings.CutPrefix(string(data), "@")
+		if !ok || h.fetcher == nil || !(hasPrefixFold(rest, "https://") || hasPrefixFold(rest, "http://")) {
+			return data, true
+		}
+		rawURL = strings.TrimSpace(rest)
+	default:
+		writeParamError(w, "source", `Invalid source: must be "data" or "url"`)
+		return nil, false
+	}
+
+	if h.fetcher == nil {
+		h.logger.WarnContext(r.Context(), "Remote fetch requested but disabled", "remote_addr", r.RemoteAddr)
+		writeError(w, http.StatusForbidden, ErrCodeSourceNotAllowed, "Fetching remote content is disabled on this server")
+		return nil, false
+	}
+
+	done := timing.Start(r.Context(), "fetch")
+	body, err := h.fetcher.Fetch(r.Context(), rawURL)
+	done()
+	if err == nil {
+		if len(body) == 0 {
+			writeError(w, http.StatusBadGateway, ErrCodeFetchFailed, "Remote document is empty")
+			return nil, false
+		}
+		return body, true
+	}
+
+	// The URL is logged without its query, which may carry tokens.
+	logURL := rawURL
+	if i := strings.IndexAny(logURL, "?#"); i >= 0 {
+		logURL = logURL[:i]
+	}
+	h.logger.WarnContext(r.Context(), "Remote fetch failed",
+		"url", logURL,
+		"error", err,
+		"remote_addr", r.RemoteAddr,
+	)
+	switch {
+	case r.Context().Err() != nil:
+		h.writeTimeout(w, r, "fetch")
+	case errors.Is(err, fetch.ErrInvalidURL):
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidURL, "Invalid source URL: must be an absolute https URL")
+	case errors.Is(err, fetch.ErrNotAllowed):
+		writeError(w, http.StatusForbidden, ErrCodeSourceNotAllowed, "Source URL rejected: "+err.Error())
+	case errors.Is(err, fetch.ErrTooLarge):
+		writeError(w, http.StatusBadGateway, ErrCodeSourceTooLarge, "Remote document is "+err.Error())
+	default:
+		writeError(w, http.StatusBadGateway, ErrCodeFetchFailed, "Failed to fetch the source URL")
+	}
+	return nil, false
+}
+
+// hasPrefixFold reports whether s begins with prefix, ignoring ASCII case.
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
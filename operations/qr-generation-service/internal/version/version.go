@@ -0,0 +1,90 @@
+// Copyright (c) 2026 WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package version reports the build metadata of the running binary.
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Build metadata, set at build time with -ldflags, for example:
+//
+//	go build -ldflags "-X github.com/wso2-open-operations/common-tools/operations/qr-generation-service/internal/version.Version=v1.4.0" ./cmd/api
+//
+// They are left empty by a plain go build.
+var (
+	Version   string
+	Commit    string
+	BuildDate string
+)
+
+// Info is the build metadata reported by /version.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build metadata. A version that was not set at build time is
+// reported as "dev", and a commit falls back to the VCS revision that go build
+// embeds when building inside a checkout, with "-dirty" appended when the tree
+// had local changes. Anything still unknown is reported as "unknown".
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+	}
+	if info.Version == "" {
+		info.Version = "dev"
+	}
+	if info.Commit == "" {
+		info.Commit = vcsRevision()
+	}
+	if info.BuildDate == "" {
+		info.BuildDate = "unknown"
+	}
+	return info
+}
+
+// vcsRevision returns the VCS revision embedded in the binary, or "unknown".
+func vcsRevision() string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	var revision string
+	var modified bool
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			modified = s.Value == "true"
+		}
+	}
+	if revision == "" {
+		return "unknown"
+	}
+	if modified {
+		revision += "-dirty"
+	}
+	return revision
+}